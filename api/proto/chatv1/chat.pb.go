@@ -21,13 +21,266 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Role ranks the users of a room; higher roles hold every permission of the lower ones.
+// The user who creates a room owns it. When the owner leaves a room that was not created
+// with CreateRoom, the highest ranked user still present (the longest present among equals)
+// becomes the owner; persistent rooms keep their owner.
+type Role int32
+
+const (
+	Role_ROLE_UNSPECIFIED Role = 0
+	// ROLE_READ_ONLY users follow the room but may not send messages to it.
+	Role_ROLE_READ_ONLY Role = 1
+	Role_ROLE_MEMBER    Role = 2
+	// ROLE_MODERATOR users may also sanction lower ranked users, change the room's topic,
+	// change or delete the messages of others and assign lower roles.
+	Role_ROLE_MODERATOR Role = 3
+	// ROLE_OWNER may also change the room's settings and archive it.
+	Role_ROLE_OWNER Role = 4
+)
+
+// Enum value maps for Role.
+var (
+	Role_name = map[int32]string{
+		0: "ROLE_UNSPECIFIED",
+		1: "ROLE_READ_ONLY",
+		2: "ROLE_MEMBER",
+		3: "ROLE_MODERATOR",
+		4: "ROLE_OWNER",
+	}
+	Role_value = map[string]int32{
+		"ROLE_UNSPECIFIED": 0,
+		"ROLE_READ_ONLY":   1,
+		"ROLE_MEMBER":      2,
+		"ROLE_MODERATOR":   3,
+		"ROLE_OWNER":       4,
+	}
+)
+
+func (x Role) Enum() *Role {
+	p := new(Role)
+	*p = x
+	return p
+}
+
+func (x Role) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Role) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[0].Descriptor()
+}
+
+func (Role) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[0]
+}
+
+func (x Role) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Role.Descriptor instead.
+func (Role) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{0}
+}
+
+// ModerationAction is a sanction a moderator applies to another user of a room.
+type ModerationAction int32
+
+const (
+	ModerationAction_MODERATION_ACTION_UNSPECIFIED ModerationAction = 0
+	// MODERATION_ACTION_KICK removes the user from the room; they may join again.
+	ModerationAction_MODERATION_ACTION_KICK ModerationAction = 1
+	// MODERATION_ACTION_BAN removes the user and rejects their joins until the ban expires.
+	ModerationAction_MODERATION_ACTION_BAN   ModerationAction = 2
+	ModerationAction_MODERATION_ACTION_UNBAN ModerationAction = 3
+	// MODERATION_ACTION_MUTE rejects the user's messages until the mute expires.
+	ModerationAction_MODERATION_ACTION_MUTE   ModerationAction = 4
+	ModerationAction_MODERATION_ACTION_UNMUTE ModerationAction = 5
+)
+
+// Enum value maps for ModerationAction.
+var (
+	ModerationAction_name = map[int32]string{
+		0: "MODERATION_ACTION_UNSPECIFIED",
+		1: "MODERATION_ACTION_KICK",
+		2: "MODERATION_ACTION_BAN",
+		3: "MODERATION_ACTION_UNBAN",
+		4: "MODERATION_ACTION_MUTE",
+		5: "MODERATION_ACTION_UNMUTE",
+	}
+	ModerationAction_value = map[string]int32{
+		"MODERATION_ACTION_UNSPECIFIED": 0,
+		"MODERATION_ACTION_KICK":        1,
+		"MODERATION_ACTION_BAN":         2,
+		"MODERATION_ACTION_UNBAN":       3,
+		"MODERATION_ACTION_MUTE":        4,
+		"MODERATION_ACTION_UNMUTE":      5,
+	}
+)
+
+func (x ModerationAction) Enum() *ModerationAction {
+	p := new(ModerationAction)
+	*p = x
+	return p
+}
+
+func (x ModerationAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ModerationAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[1].Descriptor()
+}
+
+func (ModerationAction) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[1]
+}
+
+func (x ModerationAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ModerationAction.Descriptor instead.
+func (ModerationAction) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{1}
+}
+
+// Visibility controls whether a room appears in ListRooms.
+type Visibility int32
+
+const (
+	Visibility_VISIBILITY_UNSPECIFIED Visibility = 0
+	Visibility_VISIBILITY_PUBLIC      Visibility = 1
+	// VISIBILITY_UNLISTED rooms are left out of ListRooms but may still be joined by name.
+	Visibility_VISIBILITY_UNLISTED Visibility = 2
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "VISIBILITY_UNSPECIFIED",
+		1: "VISIBILITY_PUBLIC",
+		2: "VISIBILITY_UNLISTED",
+	}
+	Visibility_value = map[string]int32{
+		"VISIBILITY_UNSPECIFIED": 0,
+		"VISIBILITY_PUBLIC":      1,
+		"VISIBILITY_UNLISTED":    2,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[2].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[2]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{2}
+}
+
+// AccessPolicy controls who may join a room.
+type AccessPolicy int32
+
+const (
+	AccessPolicy_ACCESS_POLICY_UNSPECIFIED AccessPolicy = 0
+	AccessPolicy_ACCESS_POLICY_PUBLIC      AccessPolicy = 1
+	// ACCESS_POLICY_PASSWORD rooms may be joined with their password or an invite.
+	AccessPolicy_ACCESS_POLICY_PASSWORD AccessPolicy = 2
+	// ACCESS_POLICY_INVITE_ONLY rooms may only be joined with an invite.
+	AccessPolicy_ACCESS_POLICY_INVITE_ONLY AccessPolicy = 3
+)
+
+// Enum value maps for AccessPolicy.
+var (
+	AccessPolicy_name = map[int32]string{
+		0: "ACCESS_POLICY_UNSPECIFIED",
+		1: "ACCESS_POLICY_PUBLIC",
+		2: "ACCESS_POLICY_PASSWORD",
+		3: "ACCESS_POLICY_INVITE_ONLY",
+	}
+	AccessPolicy_value = map[string]int32{
+		"ACCESS_POLICY_UNSPECIFIED": 0,
+		"ACCESS_POLICY_PUBLIC":      1,
+		"ACCESS_POLICY_PASSWORD":    2,
+		"ACCESS_POLICY_INVITE_ONLY": 3,
+	}
+)
+
+func (x AccessPolicy) Enum() *AccessPolicy {
+	p := new(AccessPolicy)
+	*p = x
+	return p
+}
+
+func (x AccessPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccessPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_proto_enumTypes[3].Descriptor()
+}
+
+func (AccessPolicy) Type() protoreflect.EnumType {
+	return &file_chat_proto_enumTypes[3]
+}
+
+func (x AccessPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccessPolicy.Descriptor instead.
+func (AccessPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{3}
+}
+
 type ServerNotice_Type int32
 
 const (
 	ServerNotice_TYPE_GENERIC     ServerNotice_Type = 0
 	ServerNotice_TYPE_USER_JOINED ServerNotice_Type = 1
 	ServerNotice_TYPE_USER_LEFT   ServerNotice_Type = 2
-	ServerNotice_TYPE_ERROR       ServerNotice_Type = 3
+	// TYPE_ERROR reports an envelope the server rejected without ending the stream;
+	// error_code, envelope and envelope_index identify the failure and the envelope.
+	// Protocol violations, such as a missing payload or another user's identity, end the
+	// stream with a status instead.
+	ServerNotice_TYPE_ERROR ServerNotice_Type = 3
+	// TYPE_EVENTS_MISSED reports that the client fell behind and the server dropped
+	// missed_events events; it should resync, e.g. by resuming from its last sequence.
+	ServerNotice_TYPE_EVENTS_MISSED ServerNotice_Type = 4
+	// TYPE_SESSION_CLOSED is the last event of a session the server removed; message holds
+	// the reason. The stream ends right after it.
+	ServerNotice_TYPE_SESSION_CLOSED ServerNotice_Type = 5
+	// TYPE_MODERATION announces that actor_user_id applied action to user_id; reason holds
+	// the moderator's reason and until_utc when a ban or mute expires. A kicked or banned
+	// user receives it right before the TYPE_SESSION_CLOSED notice of each of its sessions.
+	ServerNotice_TYPE_MODERATION ServerNotice_Type = 6
+	// TYPE_ROLE_CHANGED announces that user_id now holds role. actor_user_id names the user
+	// who assigned it; it is empty when ownership passed on because the owner left.
+	ServerNotice_TYPE_ROLE_CHANGED ServerNotice_Type = 7
+	// TYPE_TOPIC_CHANGED announces that user_id set the room's topic to topic.
+	ServerNotice_TYPE_TOPIC_CHANGED ServerNotice_Type = 8
+	// TYPE_QUEUE_POSITION tells a join waiting for a seat its place in line, held by
+	// queue_position. Like TYPE_EVENTS_MISSED it carries no message_id or sequence.
+	ServerNotice_TYPE_QUEUE_POSITION ServerNotice_Type = 9
 )
 
 // Enum value maps for ServerNotice_Type.
@@ -37,12 +290,24 @@ var (
 		1: "TYPE_USER_JOINED",
 		2: "TYPE_USER_LEFT",
 		3: "TYPE_ERROR",
+		4: "TYPE_EVENTS_MISSED",
+		5: "TYPE_SESSION_CLOSED",
+		6: "TYPE_MODERATION",
+		7: "TYPE_ROLE_CHANGED",
+		8: "TYPE_TOPIC_CHANGED",
+		9: "TYPE_QUEUE_POSITION",
 	}
 	ServerNotice_Type_value = map[string]int32{
-		"TYPE_GENERIC":     0,
-		"TYPE_USER_JOINED": 1,
-		"TYPE_USER_LEFT":   2,
-		"TYPE_ERROR":       3,
+		"TYPE_GENERIC":        0,
+		"TYPE_USER_JOINED":    1,
+		"TYPE_USER_LEFT":      2,
+		"TYPE_ERROR":          3,
+		"TYPE_EVENTS_MISSED":  4,
+		"TYPE_SESSION_CLOSED": 5,
+		"TYPE_MODERATION":     6,
+		"TYPE_ROLE_CHANGED":   7,
+		"TYPE_TOPIC_CHANGED":  8,
+		"TYPE_QUEUE_POSITION": 9,
 	}
 )
 
@@ -57,11 +322,11 @@ func (x ServerNotice_Type) String() string {
 }
 
 func (ServerNotice_Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_chat_proto_enumTypes[0].Descriptor()
+	return file_chat_proto_enumTypes[4].Descriptor()
 }
 
 func (ServerNotice_Type) Type() protoreflect.EnumType {
-	return &file_chat_proto_enumTypes[0]
+	return &file_chat_proto_enumTypes[4]
 }
 
 func (x ServerNotice_Type) Number() protoreflect.EnumNumber {
@@ -70,15 +335,33 @@ func (x ServerNotice_Type) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ServerNotice_Type.Descriptor instead.
 func (ServerNotice_Type) EnumDescriptor() ([]byte, []int) {
-	return file_chat_proto_rawDescGZIP(), []int{6, 0}
+	return file_chat_proto_rawDescGZIP(), []int{24, 0}
 }
 
 // JoinRequest describes the information a client must send to join a room.
 type JoinRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Room          string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
-	DisplayName   string                 `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id may be omitted on authenticated streams, which always join as the user their
+	// credentials belong to; a different value is rejected with PERMISSION_DENIED.
+	UserId      string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Room        string `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+	DisplayName string `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// history_limit asks the server to replay up to this many of the room's latest
+	// messages right after the JoinAck. The server caps it to its configured maximum.
+	HistoryLimit uint32 `protobuf:"varint,4,opt,name=history_limit,json=historyLimit,proto3" json:"history_limit,omitempty"`
+	// history_since_utc (Unix milliseconds) asks the server to replay messages sent at or
+	// after this instant. When combined with history_limit, the latest matches are kept.
+	HistorySinceUtc int64 `protobuf:"varint,5,opt,name=history_since_utc,json=historySinceUtc,proto3" json:"history_since_utc,omitempty"`
+	// password and invite_token grant access to rooms that are not public; joins without
+	// either are rejected with PERMISSION_DENIED. Users already admitted since the room's
+	// access settings last changed, and moderators, need neither.
+	Password    string `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	InviteToken string `protobuf:"bytes,7,opt,name=invite_token,json=inviteToken,proto3" json:"invite_token,omitempty"`
+	// wait_for_seat asks to queue when the room reached its participant limit instead of
+	// being rejected with RESOURCE_EXHAUSTED. A queued join receives TYPE_QUEUE_POSITION
+	// notices as it moves up and its JoinAck once it is admitted; it is still rejected when
+	// the server disabled queueing or the room's queue is full.
+	WaitForSeat   bool `protobuf:"varint,8,opt,name=wait_for_seat,json=waitForSeat,proto3" json:"wait_for_seat,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -134,13 +417,74 @@ func (x *JoinRequest) GetDisplayName() string {
 	return ""
 }
 
+func (x *JoinRequest) GetHistoryLimit() uint32 {
+	if x != nil {
+		return x.HistoryLimit
+	}
+	return 0
+}
+
+func (x *JoinRequest) GetHistorySinceUtc() int64 {
+	if x != nil {
+		return x.HistorySinceUtc
+	}
+	return 0
+}
+
+func (x *JoinRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetInviteToken() string {
+	if x != nil {
+		return x.InviteToken
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetWaitForSeat() bool {
+	if x != nil {
+		return x.WaitForSeat
+	}
+	return false
+}
+
 // ChatPayload represents an arbitrary message sent by a client.
 type ChatPayload struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Room          string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
-	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	TimestampUtc  int64                  `protobuf:"varint,4,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// user_id and room are set by the server on broadcasts. On a stream, messages always
+	// belong to the stream's user, so user_id may be omitted and a different value is rejected
+	// with PERMISSION_DENIED. room selects which of the stream's rooms receives the message;
+	// it may be omitted while the stream follows a single room, and naming a room the stream
+	// does not follow is rejected with a FAILED_PRECONDITION error notice.
+	UserId       *string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	Room         *string `protobuf:"bytes,2,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	Content      string  `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	TimestampUtc int64   `protobuf:"varint,4,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	// replayed is set by the server on messages delivered from history on join.
+	Replayed bool `protobuf:"varint,5,opt,name=replayed,proto3" json:"replayed,omitempty"`
+	// message_id is the server-assigned unique identifier of the message.
+	MessageId string `protobuf:"bytes,6,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// sequence is the server-assigned position of the event within its room. Every event of a
+	// room (messages and notices alike) takes the next value, so within a room sequences are
+	// strictly increasing, events are delivered to each participant in sequence order, and
+	// gaps mean the participant missed events. Sequences of different rooms are unrelated.
+	Sequence uint64 `protobuf:"varint,7,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// edited_at_utc (Unix milliseconds) is set on messages whose content was edited.
+	EditedAtUtc int64 `protobuf:"varint,8,opt,name=edited_at_utc,json=editedAtUtc,proto3" json:"edited_at_utc,omitempty"`
+	// deleted marks a tombstone of a retracted message; its content is empty.
+	Deleted bool `protobuf:"varint,9,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// reactions are the message's current reactions, in the order they were first added.
+	Reactions []*Reaction `protobuf:"bytes,10,rep,name=reactions,proto3" json:"reactions,omitempty"`
+	// reply_to posts the message as a reply in the thread of an earlier message of the room;
+	// replying to a reply joins the thread of its root. On broadcasts it holds the thread
+	// root's ID. Replies are delivered to the whole room like any other message.
+	ReplyTo string `protobuf:"bytes,11,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`
+	// reply_count is the number of replies a thread root received, tombstones included.
+	ReplyCount    uint32 `protobuf:"varint,12,opt,name=reply_count,json=replyCount,proto3" json:"reply_count,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -176,15 +520,15 @@ func (*ChatPayload) Descriptor() ([]byte, []int) {
 }
 
 func (x *ChatPayload) GetUserId() string {
-	if x != nil {
-		return x.UserId
+	if x != nil && x.UserId != nil {
+		return *x.UserId
 	}
 	return ""
 }
 
 func (x *ChatPayload) GetRoom() string {
-	if x != nil {
-		return x.Room
+	if x != nil && x.Room != nil {
+		return *x.Room
 	}
 	return ""
 }
@@ -203,29 +547,87 @@ func (x *ChatPayload) GetTimestampUtc() int64 {
 	return 0
 }
 
-// LeaveRequest notifies the server that a client wants to disconnect from a room.
-type LeaveRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Room          string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+func (x *ChatPayload) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+func (x *ChatPayload) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ChatPayload) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ChatPayload) GetEditedAtUtc() int64 {
+	if x != nil {
+		return x.EditedAtUtc
+	}
+	return 0
+}
+
+func (x *ChatPayload) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *ChatPayload) GetReactions() []*Reaction {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+func (x *ChatPayload) GetReplyTo() string {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return ""
+}
+
+func (x *ChatPayload) GetReplyCount() uint32 {
+	if x != nil {
+		return x.ReplyCount
+	}
+	return 0
+}
+
+// Reaction summarizes the users reacting to a message with one emoji.
+type Reaction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Emoji string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count uint32                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// user_ids lists the reacting users, in the order they reacted.
+	UserIds       []string `protobuf:"bytes,3,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LeaveRequest) Reset() {
-	*x = LeaveRequest{}
+func (x *Reaction) Reset() {
+	*x = Reaction{}
 	mi := &file_chat_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LeaveRequest) String() string {
+func (x *Reaction) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LeaveRequest) ProtoMessage() {}
+func (*Reaction) ProtoMessage() {}
 
-func (x *LeaveRequest) ProtoReflect() protoreflect.Message {
+func (x *Reaction) ProtoReflect() protoreflect.Message {
 	mi := &file_chat_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -237,52 +639,58 @@ func (x *LeaveRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LeaveRequest.ProtoReflect.Descriptor instead.
-func (*LeaveRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Reaction.ProtoReflect.Descriptor instead.
+func (*Reaction) Descriptor() ([]byte, []int) {
 	return file_chat_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *LeaveRequest) GetUserId() string {
+func (x *Reaction) GetEmoji() string {
 	if x != nil {
-		return x.UserId
+		return x.Emoji
 	}
 	return ""
 }
 
-func (x *LeaveRequest) GetRoom() string {
+func (x *Reaction) GetCount() uint32 {
 	if x != nil {
-		return x.Room
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-// ClientEnvelope is the input stream wrapper clients use to talk to the server.
-type ClientEnvelope struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Message:
-	//
-	//	*ClientEnvelope_Join
-	//	*ClientEnvelope_Chat
-	//	*ClientEnvelope_Leave
-	Message       isClientEnvelope_Message `protobuf_oneof:"message"`
+func (x *Reaction) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+// EditMessageRequest replaces the content of an earlier message. Only the message's author
+// or a moderator may edit it, and deleted messages cannot be edited. Like in ChatPayload,
+// room may be omitted while the stream follows a single room.
+type EditMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ClientEnvelope) Reset() {
-	*x = ClientEnvelope{}
+func (x *EditMessageRequest) Reset() {
+	*x = EditMessageRequest{}
 	mi := &file_chat_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ClientEnvelope) String() string {
+func (x *EditMessageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientEnvelope) ProtoMessage() {}
+func (*EditMessageRequest) ProtoMessage() {}
 
-func (x *ClientEnvelope) ProtoReflect() protoreflect.Message {
+func (x *EditMessageRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_chat_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -294,92 +702,2962 @@ func (x *ClientEnvelope) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientEnvelope.ProtoReflect.Descriptor instead.
-func (*ClientEnvelope) Descriptor() ([]byte, []int) {
+// Deprecated: Use EditMessageRequest.ProtoReflect.Descriptor instead.
+func (*EditMessageRequest) Descriptor() ([]byte, []int) {
 	return file_chat_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ClientEnvelope) GetMessage() isClientEnvelope_Message {
-	if x != nil {
-		return x.Message
+func (x *EditMessageRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
 	}
-	return nil
+	return ""
 }
 
-func (x *ClientEnvelope) GetJoin() *JoinRequest {
+func (x *EditMessageRequest) GetMessageId() string {
 	if x != nil {
-		if x, ok := x.Message.(*ClientEnvelope_Join); ok {
-			return x.Join
-		}
+		return x.MessageId
 	}
-	return nil
+	return ""
 }
 
-func (x *ClientEnvelope) GetChat() *ChatPayload {
+func (x *EditMessageRequest) GetContent() string {
 	if x != nil {
-		if x, ok := x.Message.(*ClientEnvelope_Chat); ok {
-			return x.Chat
-		}
+		return x.Content
 	}
-	return nil
+	return ""
 }
 
-func (x *ClientEnvelope) GetLeave() *LeaveRequest {
-	if x != nil {
-		if x, ok := x.Message.(*ClientEnvelope_Leave); ok {
-			return x.Leave
-		}
-	}
-	return nil
+// DeleteMessageRequest replaces an earlier message with a tombstone. The same rules as for
+// edits apply.
+type DeleteMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type isClientEnvelope_Message interface {
-	isClientEnvelope_Message()
+func (x *DeleteMessageRequest) Reset() {
+	*x = DeleteMessageRequest{}
+	mi := &file_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type ClientEnvelope_Join struct {
-	Join *JoinRequest `protobuf:"bytes,1,opt,name=join,proto3,oneof"`
+func (x *DeleteMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageRequest) ProtoMessage() {}
+
+func (x *DeleteMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMessageRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteMessageRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+func (x *DeleteMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// ReactionRequest adds or removes the stream user's reaction to an earlier message. Any
+// member may react; adding a reaction twice or removing a missing one is a no-op. Like in
+// ChatPayload, room may be omitted while the stream follows a single room.
+type ReactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Emoji         string                 `protobuf:"bytes,3,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionRequest) Reset() {
+	*x = ReactionRequest{}
+	mi := &file_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionRequest) ProtoMessage() {}
+
+func (x *ReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionRequest.ProtoReflect.Descriptor instead.
+func (*ReactionRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReactionRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+func (x *ReactionRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ReactionRequest) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+// SetRoleRequest assigns a role to another user of a room. Users may only assign roles
+// below their own to users they outrank; the owner hands ownership over by assigning
+// ROLE_OWNER to a user present in the room, and becomes a moderator. Like in ChatPayload,
+// room may be omitted while the stream follows a single room.
+type SetRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          Role                   `protobuf:"varint,3,opt,name=role,proto3,enum=chat.v1.Role" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetRoleRequest) Reset() {
+	*x = SetRoleRequest{}
+	mi := &file_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetRoleRequest) ProtoMessage() {}
+
+func (x *SetRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetRoleRequest.ProtoReflect.Descriptor instead.
+func (*SetRoleRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetRoleRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+func (x *SetRoleRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetRoleRequest) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_ROLE_UNSPECIFIED
+}
+
+// ModerationRequest sanctions another user of a room. Only moderators may send it, and
+// failures are reported as TYPE_ERROR notices. Like in ChatPayload, room may be omitted
+// while the stream follows a single room.
+type ModerationRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Room   *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Action ModerationAction       `protobuf:"varint,3,opt,name=action,proto3,enum=chat.v1.ModerationAction" json:"action,omitempty"`
+	// reason is shown to the room and to the sanctioned user.
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// duration_seconds is how long a ban or mute lasts; it is required for both.
+	DurationSeconds uint32 `protobuf:"varint,5,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ModerationRequest) Reset() {
+	*x = ModerationRequest{}
+	mi := &file_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModerationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModerationRequest) ProtoMessage() {}
+
+func (x *ModerationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModerationRequest.ProtoReflect.Descriptor instead.
+func (*ModerationRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ModerationRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+func (x *ModerationRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ModerationRequest) GetAction() ModerationAction {
+	if x != nil {
+		return x.Action
+	}
+	return ModerationAction_MODERATION_ACTION_UNSPECIFIED
+}
+
+func (x *ModerationRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ModerationRequest) GetDurationSeconds() uint32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+// LeaveRequest notifies the server that a client wants to disconnect from a room.
+// Like in ChatPayload, user_id and room are optional: room selects the room to leave and may
+// be omitted while the stream follows a single room. The stream ends after its last room is left.
+type LeaveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        *string                `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	Room          *string                `protobuf:"bytes,2,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveRequest) Reset() {
+	*x = LeaveRequest{}
+	mi := &file_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveRequest) ProtoMessage() {}
+
+func (x *LeaveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveRequest.ProtoReflect.Descriptor instead.
+func (*LeaveRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LeaveRequest) GetUserId() string {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return ""
+}
+
+func (x *LeaveRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+// ResumeRequest reattaches a new stream to a session whose connection dropped, as long as it
+// arrives within the server's grace period. No leave/join notices are emitted for the room.
+type ResumeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// resume_token is the token received in the latest JoinAck of the session.
+	ResumeToken string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// last_sequence is the sequence of the last event the client processed; stored messages
+	// after it are replayed (marked as replayed) before live events resume.
+	LastSequence  uint64 `protobuf:"varint,2,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeRequest) Reset() {
+	*x = ResumeRequest{}
+	mi := &file_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeRequest) ProtoMessage() {}
+
+func (x *ResumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeRequest.ProtoReflect.Descriptor instead.
+func (*ResumeRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResumeRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *ResumeRequest) GetLastSequence() uint64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+// DirectMessageRequest sends a message to a single user, wherever they are connected. The
+// stream must have joined or resumed a room first; the message is sent as the stream's user.
+// When the recipient is offline the server queues the message if it keeps a message store,
+// and rejects it with NOT_FOUND otherwise.
+type DirectMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ToUserId      string                 `protobuf:"bytes,1,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectMessageRequest) Reset() {
+	*x = DirectMessageRequest{}
+	mi := &file_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectMessageRequest) ProtoMessage() {}
+
+func (x *DirectMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectMessageRequest.ProtoReflect.Descriptor instead.
+func (*DirectMessageRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DirectMessageRequest) GetToUserId() string {
+	if x != nil {
+		return x.ToUserId
+	}
+	return ""
+}
+
+func (x *DirectMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// TypingRequest signals that the stream's user started or stopped typing in a room. Clients
+// may send typing=true on every keystroke: the server debounces repeated starts and announces
+// the stop by itself once the user stays silent for its typing timeout. Like in ChatPayload,
+// room may be omitted while the stream follows a single room.
+type TypingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *string                `protobuf:"bytes,1,opt,name=room,proto3,oneof" json:"room,omitempty"`
+	Typing        bool                   `protobuf:"varint,2,opt,name=typing,proto3" json:"typing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypingRequest) Reset() {
+	*x = TypingRequest{}
+	mi := &file_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypingRequest) ProtoMessage() {}
+
+func (x *TypingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypingRequest.ProtoReflect.Descriptor instead.
+func (*TypingRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TypingRequest) GetRoom() string {
+	if x != nil && x.Room != nil {
+		return *x.Room
+	}
+	return ""
+}
+
+func (x *TypingRequest) GetTyping() bool {
+	if x != nil {
+		return x.Typing
+	}
+	return false
+}
+
+// PingRequest is an application heartbeat. Any envelope counts as activity, so clients only
+// need to ping while they have nothing else to send; the server evicts sessions that stay
+// silent for its idle timeout. The server answers with a Pong echoing the nonce.
+type PingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nonce         uint64                 `protobuf:"varint,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PingRequest) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+// ClientEnvelope is the input stream wrapper clients use to talk to the server.
+type ClientEnvelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*ClientEnvelope_Join
+	//	*ClientEnvelope_Chat
+	//	*ClientEnvelope_Leave
+	//	*ClientEnvelope_Resume
+	//	*ClientEnvelope_Direct
+	//	*ClientEnvelope_Typing
+	//	*ClientEnvelope_Ping
+	//	*ClientEnvelope_Edit
+	//	*ClientEnvelope_Delete
+	//	*ClientEnvelope_React
+	//	*ClientEnvelope_Unreact
+	//	*ClientEnvelope_Moderate
+	//	*ClientEnvelope_SetRole
+	Message isClientEnvelope_Message `protobuf_oneof:"message"`
+	// request_id is an optional client-generated identifier of the envelope. A ChatPayload
+	// carrying one is answered with a SendAck, and retrying it within the server's
+	// de-duplication window acknowledges the original message instead of sending a copy.
+	// Error notices caused by the envelope echo it.
+	RequestId     string `protobuf:"bytes,8,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientEnvelope) Reset() {
+	*x = ClientEnvelope{}
+	mi := &file_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientEnvelope) ProtoMessage() {}
+
+func (x *ClientEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientEnvelope.ProtoReflect.Descriptor instead.
+func (*ClientEnvelope) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ClientEnvelope) GetMessage() isClientEnvelope_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetJoin() *JoinRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Join); ok {
+			return x.Join
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetChat() *ChatPayload {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Chat); ok {
+			return x.Chat
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetLeave() *LeaveRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Leave); ok {
+			return x.Leave
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetResume() *ResumeRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Resume); ok {
+			return x.Resume
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetDirect() *DirectMessageRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Direct); ok {
+			return x.Direct
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetTyping() *TypingRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Typing); ok {
+			return x.Typing
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetPing() *PingRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Ping); ok {
+			return x.Ping
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetEdit() *EditMessageRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Edit); ok {
+			return x.Edit
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetDelete() *DeleteMessageRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Delete); ok {
+			return x.Delete
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetReact() *ReactionRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_React); ok {
+			return x.React
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetUnreact() *ReactionRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Unreact); ok {
+			return x.Unreact
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetModerate() *ModerationRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_Moderate); ok {
+			return x.Moderate
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetSetRole() *SetRoleRequest {
+	if x != nil {
+		if x, ok := x.Message.(*ClientEnvelope_SetRole); ok {
+			return x.SetRole
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type isClientEnvelope_Message interface {
+	isClientEnvelope_Message()
+}
+
+type ClientEnvelope_Join struct {
+	Join *JoinRequest `protobuf:"bytes,1,opt,name=join,proto3,oneof"`
+}
+
+type ClientEnvelope_Chat struct {
+	Chat *ChatPayload `protobuf:"bytes,2,opt,name=chat,proto3,oneof"`
+}
+
+type ClientEnvelope_Leave struct {
+	Leave *LeaveRequest `protobuf:"bytes,3,opt,name=leave,proto3,oneof"`
+}
+
+type ClientEnvelope_Resume struct {
+	Resume *ResumeRequest `protobuf:"bytes,4,opt,name=resume,proto3,oneof"`
+}
+
+type ClientEnvelope_Direct struct {
+	Direct *DirectMessageRequest `protobuf:"bytes,5,opt,name=direct,proto3,oneof"`
+}
+
+type ClientEnvelope_Typing struct {
+	Typing *TypingRequest `protobuf:"bytes,6,opt,name=typing,proto3,oneof"`
+}
+
+type ClientEnvelope_Ping struct {
+	Ping *PingRequest `protobuf:"bytes,7,opt,name=ping,proto3,oneof"`
+}
+
+type ClientEnvelope_Edit struct {
+	Edit *EditMessageRequest `protobuf:"bytes,9,opt,name=edit,proto3,oneof"`
+}
+
+type ClientEnvelope_Delete struct {
+	Delete *DeleteMessageRequest `protobuf:"bytes,10,opt,name=delete,proto3,oneof"`
+}
+
+type ClientEnvelope_React struct {
+	React *ReactionRequest `protobuf:"bytes,11,opt,name=react,proto3,oneof"`
+}
+
+type ClientEnvelope_Unreact struct {
+	Unreact *ReactionRequest `protobuf:"bytes,12,opt,name=unreact,proto3,oneof"`
+}
+
+type ClientEnvelope_Moderate struct {
+	Moderate *ModerationRequest `protobuf:"bytes,13,opt,name=moderate,proto3,oneof"`
+}
+
+type ClientEnvelope_SetRole struct {
+	SetRole *SetRoleRequest `protobuf:"bytes,14,opt,name=set_role,json=setRole,proto3,oneof"`
+}
+
+func (*ClientEnvelope_Join) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Chat) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Leave) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Resume) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Direct) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Typing) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Ping) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Edit) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Delete) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_React) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Unreact) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_Moderate) isClientEnvelope_Message() {}
+
+func (*ClientEnvelope_SetRole) isClientEnvelope_Message() {}
+
+// JoinAck confirms that the user joined the requested room.
+type JoinAck struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Room           string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+	WelcomeMessage string                 `protobuf:"bytes,3,opt,name=welcome_message,json=welcomeMessage,proto3" json:"welcome_message,omitempty"`
+	// resume_token must be presented in a ResumeRequest to reattach after a dropped connection.
+	// It is rotated on every resume.
+	ResumeToken string `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// resumed is set when the ack answers a ResumeRequest.
+	Resumed bool `protobuf:"varint,5,opt,name=resumed,proto3" json:"resumed,omitempty"`
+	// role is the user's role in the room.
+	Role          Role `protobuf:"varint,6,opt,name=role,proto3,enum=chat.v1.Role" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinAck) Reset() {
+	*x = JoinAck{}
+	mi := &file_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinAck) ProtoMessage() {}
+
+func (x *JoinAck) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinAck.ProtoReflect.Descriptor instead.
+func (*JoinAck) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *JoinAck) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *JoinAck) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *JoinAck) GetWelcomeMessage() string {
+	if x != nil {
+		return x.WelcomeMessage
+	}
+	return ""
+}
+
+func (x *JoinAck) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *JoinAck) GetResumed() bool {
+	if x != nil {
+		return x.Resumed
+	}
+	return false
+}
+
+func (x *JoinAck) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_ROLE_UNSPECIFIED
+}
+
+// DirectMessage is a message delivered to every stream of its recipient.
+type DirectMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// message_id is the server-assigned unique identifier of the message.
+	MessageId       string `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	FromUserId      string `protobuf:"bytes,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`
+	FromDisplayName string `protobuf:"bytes,3,opt,name=from_display_name,json=fromDisplayName,proto3" json:"from_display_name,omitempty"`
+	ToUserId        string `protobuf:"bytes,4,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`
+	Content         string `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	TimestampUtc    int64  `protobuf:"varint,6,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	// queued is set when the message waited for the recipient to come online.
+	Queued        bool `protobuf:"varint,7,opt,name=queued,proto3" json:"queued,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DirectMessage) Reset() {
+	*x = DirectMessage{}
+	mi := &file_chat_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DirectMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectMessage) ProtoMessage() {}
+
+func (x *DirectMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectMessage.ProtoReflect.Descriptor instead.
+func (*DirectMessage) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DirectMessage) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetFromUserId() string {
+	if x != nil {
+		return x.FromUserId
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetFromDisplayName() string {
+	if x != nil {
+		return x.FromDisplayName
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetToUserId() string {
+	if x != nil {
+		return x.ToUserId
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetTimestampUtc() int64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *DirectMessage) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
+// TypingIndicator is an ephemeral signal that a user of the event's room started or stopped
+// typing. It carries no message_id or sequence, is never replayed and may be dropped for
+// clients that fall behind.
+type TypingIndicator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Typing        bool                   `protobuf:"varint,3,opt,name=typing,proto3" json:"typing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypingIndicator) Reset() {
+	*x = TypingIndicator{}
+	mi := &file_chat_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypingIndicator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypingIndicator) ProtoMessage() {}
+
+func (x *TypingIndicator) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypingIndicator.ProtoReflect.Descriptor instead.
+func (*TypingIndicator) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TypingIndicator) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *TypingIndicator) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *TypingIndicator) GetTyping() bool {
+	if x != nil {
+		return x.Typing
+	}
+	return false
+}
+
+// Pong answers a PingRequest with its nonce and the server time (Unix milliseconds).
+type Pong struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nonce         uint64                 `protobuf:"varint,1,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	TimestampUtc  int64                  `protobuf:"varint,2,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_chat_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Pong) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Pong) GetTimestampUtc() int64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+// SendAck answers a ChatPayload sent with a request_id: it carries the message_id and
+// sequence the server assigned, or error_code (a google.rpc.Code) and error when the message
+// was rejected.
+type SendAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	ErrorCode     uint32                 `protobuf:"varint,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendAck) Reset() {
+	*x = SendAck{}
+	mi := &file_chat_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendAck) ProtoMessage() {}
+
+func (x *SendAck) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendAck.ProtoReflect.Descriptor instead.
+func (*SendAck) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SendAck) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SendAck) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SendAck) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *SendAck) GetErrorCode() uint32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *SendAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// MessageEdited announces new content for an earlier message of the event's room. event_id
+// and sequence place the edit among the room's events, following the rules of ChatPayload.
+type MessageEdited struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Content   string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	// user_id is the user who edited the message: its author or a moderator.
+	UserId        string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	EditedAtUtc   int64  `protobuf:"varint,4,opt,name=edited_at_utc,json=editedAtUtc,proto3" json:"edited_at_utc,omitempty"`
+	EventId       string `protobuf:"bytes,5,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Sequence      uint64 `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageEdited) Reset() {
+	*x = MessageEdited{}
+	mi := &file_chat_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageEdited) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageEdited) ProtoMessage() {}
+
+func (x *MessageEdited) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageEdited.ProtoReflect.Descriptor instead.
+func (*MessageEdited) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MessageEdited) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *MessageEdited) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *MessageEdited) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *MessageEdited) GetEditedAtUtc() int64 {
+	if x != nil {
+		return x.EditedAtUtc
+	}
+	return 0
+}
+
+func (x *MessageEdited) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *MessageEdited) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// MessageDeleted announces that an earlier message of the event's room was replaced by a
+// tombstone. event_id and sequence follow the rules of ChatPayload.
+type MessageDeleted struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// user_id is the user who deleted the message: its author or a moderator.
+	UserId        string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DeletedAtUtc  int64  `protobuf:"varint,3,opt,name=deleted_at_utc,json=deletedAtUtc,proto3" json:"deleted_at_utc,omitempty"`
+	EventId       string `protobuf:"bytes,4,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Sequence      uint64 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageDeleted) Reset() {
+	*x = MessageDeleted{}
+	mi := &file_chat_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageDeleted) ProtoMessage() {}
+
+func (x *MessageDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageDeleted.ProtoReflect.Descriptor instead.
+func (*MessageDeleted) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *MessageDeleted) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *MessageDeleted) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *MessageDeleted) GetDeletedAtUtc() int64 {
+	if x != nil {
+		return x.DeletedAtUtc
+	}
+	return 0
+}
+
+func (x *MessageDeleted) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *MessageDeleted) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// ReactionDelta announces that a user added or removed a reaction to an earlier message of
+// the event's room. count is the number of users reacting with the emoji afterwards. event_id
+// and sequence follow the rules of ChatPayload.
+type ReactionDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Emoji         string                 `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Added         bool                   `protobuf:"varint,4,opt,name=added,proto3" json:"added,omitempty"`
+	Count         uint32                 `protobuf:"varint,5,opt,name=count,proto3" json:"count,omitempty"`
+	EventId       string                 `protobuf:"bytes,6,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,7,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReactionDelta) Reset() {
+	*x = ReactionDelta{}
+	mi := &file_chat_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReactionDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReactionDelta) ProtoMessage() {}
+
+func (x *ReactionDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReactionDelta.ProtoReflect.Descriptor instead.
+func (*ReactionDelta) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ReactionDelta) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ReactionDelta) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *ReactionDelta) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ReactionDelta) GetAdded() bool {
+	if x != nil {
+		return x.Added
+	}
+	return false
+}
+
+func (x *ReactionDelta) GetCount() uint32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ReactionDelta) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ReactionDelta) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// ThreadUpdate announces that a thread root of the event's room received a reply. event_id
+// and sequence follow the rules of ChatPayload.
+type ThreadUpdate struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	MessageId  string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	ReplyCount uint32                 `protobuf:"varint,2,opt,name=reply_count,json=replyCount,proto3" json:"reply_count,omitempty"`
+	// user_id is the user who replied.
+	UserId        string `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TimestampUtc  int64  `protobuf:"varint,4,opt,name=timestamp_utc,json=timestampUtc,proto3" json:"timestamp_utc,omitempty"`
+	EventId       string `protobuf:"bytes,5,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Sequence      uint64 `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThreadUpdate) Reset() {
+	*x = ThreadUpdate{}
+	mi := &file_chat_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThreadUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreadUpdate) ProtoMessage() {}
+
+func (x *ThreadUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreadUpdate.ProtoReflect.Descriptor instead.
+func (*ThreadUpdate) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ThreadUpdate) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ThreadUpdate) GetReplyCount() uint32 {
+	if x != nil {
+		return x.ReplyCount
+	}
+	return 0
+}
+
+func (x *ThreadUpdate) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ThreadUpdate) GetTimestampUtc() int64 {
+	if x != nil {
+		return x.TimestampUtc
+	}
+	return 0
+}
+
+func (x *ThreadUpdate) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ThreadUpdate) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+// Broadcast envelope for server -> client communication.
+type ServerEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ServerEvent_Joined
+	//	*ServerEvent_Broadcast
+	//	*ServerEvent_Notice
+	//	*ServerEvent_Direct
+	//	*ServerEvent_Typing
+	//	*ServerEvent_Pong
+	//	*ServerEvent_Ack
+	//	*ServerEvent_Edited
+	//	*ServerEvent_Deleted
+	//	*ServerEvent_Reaction
+	//	*ServerEvent_Thread
+	Event isServerEvent_Event `protobuf_oneof:"event"`
+	// room is the room the event belongs to, so a stream following several rooms can route
+	// every event without inspecting its payload. It is empty on direct messages.
+	Room          string `protobuf:"bytes,4,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerEvent) Reset() {
+	*x = ServerEvent{}
+	mi := &file_chat_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerEvent) ProtoMessage() {}
+
+func (x *ServerEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerEvent.ProtoReflect.Descriptor instead.
+func (*ServerEvent) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ServerEvent) GetEvent() isServerEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetJoined() *JoinAck {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Joined); ok {
+			return x.Joined
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetBroadcast() *ChatPayload {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Broadcast); ok {
+			return x.Broadcast
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetNotice() *ServerNotice {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Notice); ok {
+			return x.Notice
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetDirect() *DirectMessage {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Direct); ok {
+			return x.Direct
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetTyping() *TypingIndicator {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Typing); ok {
+			return x.Typing
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetPong() *Pong {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Pong); ok {
+			return x.Pong
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetAck() *SendAck {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetEdited() *MessageEdited {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Edited); ok {
+			return x.Edited
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetDeleted() *MessageDeleted {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Deleted); ok {
+			return x.Deleted
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetReaction() *ReactionDelta {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Reaction); ok {
+			return x.Reaction
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetThread() *ThreadUpdate {
+	if x != nil {
+		if x, ok := x.Event.(*ServerEvent_Thread); ok {
+			return x.Thread
+		}
+	}
+	return nil
+}
+
+func (x *ServerEvent) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+type isServerEvent_Event interface {
+	isServerEvent_Event()
+}
+
+type ServerEvent_Joined struct {
+	Joined *JoinAck `protobuf:"bytes,1,opt,name=joined,proto3,oneof"`
+}
+
+type ServerEvent_Broadcast struct {
+	Broadcast *ChatPayload `protobuf:"bytes,2,opt,name=broadcast,proto3,oneof"`
+}
+
+type ServerEvent_Notice struct {
+	Notice *ServerNotice `protobuf:"bytes,3,opt,name=notice,proto3,oneof"`
+}
+
+type ServerEvent_Direct struct {
+	Direct *DirectMessage `protobuf:"bytes,5,opt,name=direct,proto3,oneof"`
+}
+
+type ServerEvent_Typing struct {
+	Typing *TypingIndicator `protobuf:"bytes,6,opt,name=typing,proto3,oneof"`
+}
+
+type ServerEvent_Pong struct {
+	Pong *Pong `protobuf:"bytes,7,opt,name=pong,proto3,oneof"`
+}
+
+type ServerEvent_Ack struct {
+	Ack *SendAck `protobuf:"bytes,8,opt,name=ack,proto3,oneof"`
+}
+
+type ServerEvent_Edited struct {
+	Edited *MessageEdited `protobuf:"bytes,9,opt,name=edited,proto3,oneof"`
+}
+
+type ServerEvent_Deleted struct {
+	Deleted *MessageDeleted `protobuf:"bytes,10,opt,name=deleted,proto3,oneof"`
+}
+
+type ServerEvent_Reaction struct {
+	Reaction *ReactionDelta `protobuf:"bytes,11,opt,name=reaction,proto3,oneof"`
+}
+
+type ServerEvent_Thread struct {
+	Thread *ThreadUpdate `protobuf:"bytes,12,opt,name=thread,proto3,oneof"`
+}
+
+func (*ServerEvent_Joined) isServerEvent_Event() {}
+
+func (*ServerEvent_Broadcast) isServerEvent_Event() {}
+
+func (*ServerEvent_Notice) isServerEvent_Event() {}
+
+func (*ServerEvent_Direct) isServerEvent_Event() {}
+
+func (*ServerEvent_Typing) isServerEvent_Event() {}
+
+func (*ServerEvent_Pong) isServerEvent_Event() {}
+
+func (*ServerEvent_Ack) isServerEvent_Event() {}
+
+func (*ServerEvent_Edited) isServerEvent_Event() {}
+
+func (*ServerEvent_Deleted) isServerEvent_Event() {}
+
+func (*ServerEvent_Reaction) isServerEvent_Event() {}
+
+func (*ServerEvent_Thread) isServerEvent_Event() {}
+
+// ServerNotice conveys system-level announcements (errors, user events).
+type ServerNotice struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Type    ServerNotice_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=chat.v1.ServerNotice_Type" json:"type,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	UserId  string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Room    string                 `protobuf:"bytes,4,opt,name=room,proto3" json:"room,omitempty"`
+	// message_id and sequence follow the same rules as in ChatPayload. Notices not tied to
+	// room activity (such as errors) leave them empty.
+	MessageId string `protobuf:"bytes,5,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Sequence  uint64 `protobuf:"varint,6,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// missed_events is set on TYPE_EVENTS_MISSED notices.
+	MissedEvents uint64 `protobuf:"varint,7,opt,name=missed_events,json=missedEvents,proto3" json:"missed_events,omitempty"`
+	// reason explains why the server removed the user, such as an idle timeout or a kick. It
+	// is set on TYPE_USER_LEFT and TYPE_SESSION_CLOSED notices of sessions the server closed,
+	// and on TYPE_MODERATION notices.
+	Reason string `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	// error_code is the google.rpc.Code of a TYPE_ERROR notice.
+	ErrorCode uint32 `protobuf:"varint,9,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// envelope names the payload field of the rejected ClientEnvelope, e.g. "chat".
+	Envelope string `protobuf:"bytes,10,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	// envelope_index is the 1-based position of the rejected envelope among those the client
+	// sent on this stream.
+	EnvelopeIndex uint64 `protobuf:"varint,11,opt,name=envelope_index,json=envelopeIndex,proto3" json:"envelope_index,omitempty"`
+	// request_id echoes the request_id of the rejected envelope, if it had one.
+	RequestId string `protobuf:"bytes,12,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// action, actor_user_id and until_utc (Unix milliseconds) are set on TYPE_MODERATION
+	// notices.
+	Action      ModerationAction `protobuf:"varint,13,opt,name=action,proto3,enum=chat.v1.ModerationAction" json:"action,omitempty"`
+	ActorUserId string           `protobuf:"bytes,14,opt,name=actor_user_id,json=actorUserId,proto3" json:"actor_user_id,omitempty"`
+	UntilUtc    int64            `protobuf:"varint,15,opt,name=until_utc,json=untilUtc,proto3" json:"until_utc,omitempty"`
+	// role is set on TYPE_ROLE_CHANGED notices.
+	Role Role `protobuf:"varint,16,opt,name=role,proto3,enum=chat.v1.Role" json:"role,omitempty"`
+	// topic is the new topic of a TYPE_TOPIC_CHANGED notice; empty when it was cleared.
+	Topic string `protobuf:"bytes,17,opt,name=topic,proto3" json:"topic,omitempty"`
+	// queue_position is the 1-based place in line of a TYPE_QUEUE_POSITION notice.
+	QueuePosition uint32 `protobuf:"varint,18,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerNotice) Reset() {
+	*x = ServerNotice{}
+	mi := &file_chat_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerNotice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerNotice) ProtoMessage() {}
+
+func (x *ServerNotice) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerNotice.ProtoReflect.Descriptor instead.
+func (*ServerNotice) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ServerNotice) GetType() ServerNotice_Type {
+	if x != nil {
+		return x.Type
+	}
+	return ServerNotice_TYPE_GENERIC
+}
+
+func (x *ServerNotice) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ServerNotice) GetMissedEvents() uint64 {
+	if x != nil {
+		return x.MissedEvents
+	}
+	return 0
+}
+
+func (x *ServerNotice) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetErrorCode() uint32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *ServerNotice) GetEnvelope() string {
+	if x != nil {
+		return x.Envelope
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetEnvelopeIndex() uint64 {
+	if x != nil {
+		return x.EnvelopeIndex
+	}
+	return 0
+}
+
+func (x *ServerNotice) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetAction() ModerationAction {
+	if x != nil {
+		return x.Action
+	}
+	return ModerationAction_MODERATION_ACTION_UNSPECIFIED
+}
+
+func (x *ServerNotice) GetActorUserId() string {
+	if x != nil {
+		return x.ActorUserId
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetUntilUtc() int64 {
+	if x != nil {
+		return x.UntilUtc
+	}
+	return 0
+}
+
+func (x *ServerNotice) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_ROLE_UNSPECIFIED
+}
+
+func (x *ServerNotice) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ServerNotice) GetQueuePosition() uint32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+// ListRoomsRequest pages through the public rooms that are not archived, ordered by name.
+type ListRoomsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name_prefix keeps only the rooms whose name starts with it.
+	NamePrefix string `protobuf:"bytes,1,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix,omitempty"`
+	// page_size caps the rooms returned; zero selects the server default.
+	PageSize uint32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page; empty for the first page.
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRoomsRequest) Reset() {
+	*x = ListRoomsRequest{}
+	mi := &file_chat_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRoomsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRoomsRequest) ProtoMessage() {}
+
+func (x *ListRoomsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRoomsRequest.ProtoReflect.Descriptor instead.
+func (*ListRoomsRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListRoomsRequest) GetNamePrefix() string {
+	if x != nil {
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *ListRoomsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRoomsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// RoomSummary describes a room and its metadata without listing its participants.
+type RoomSummary struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Room             string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	ParticipantCount uint32                 `protobuf:"varint,2,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	// last_sequence is the sequence of the room's latest event.
+	LastSequence uint64 `protobuf:"varint,3,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	Title        string `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Topic        string `protobuf:"bytes,5,opt,name=topic,proto3" json:"topic,omitempty"`
+	Description  string `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	// created_by is the user who created the room, explicitly or by joining it first.
+	CreatedBy string `protobuf:"bytes,7,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// created_at_utc is the Unix time in milliseconds at which the room was created.
+	CreatedAtUtc int64      `protobuf:"varint,8,opt,name=created_at_utc,json=createdAtUtc,proto3" json:"created_at_utc,omitempty"`
+	Visibility   Visibility `protobuf:"varint,9,opt,name=visibility,proto3,enum=chat.v1.Visibility" json:"visibility,omitempty"`
+	// persistent rooms were created with CreateRoom and outlive their participants; the
+	// others are removed once the last participant leaves.
+	Persistent bool `protobuf:"varint,10,opt,name=persistent,proto3" json:"persistent,omitempty"`
+	// archived rooms keep their history and settings but can no longer be joined.
+	Archived bool         `protobuf:"varint,11,opt,name=archived,proto3" json:"archived,omitempty"`
+	Access   AccessPolicy `protobuf:"varint,12,opt,name=access,proto3,enum=chat.v1.AccessPolicy" json:"access,omitempty"`
+	// max_participants caps how many users may be in the room at once; zero leaves it to the
+	// per-room limit the server applies to every room.
+	MaxParticipants uint32 `protobuf:"varint,13,opt,name=max_participants,json=maxParticipants,proto3" json:"max_participants,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RoomSummary) Reset() {
+	*x = RoomSummary{}
+	mi := &file_chat_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoomSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoomSummary) ProtoMessage() {}
+
+func (x *RoomSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoomSummary.ProtoReflect.Descriptor instead.
+func (*RoomSummary) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RoomSummary) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *RoomSummary) GetParticipantCount() uint32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
+}
+
+func (x *RoomSummary) GetLastSequence() uint64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+func (x *RoomSummary) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *RoomSummary) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *RoomSummary) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RoomSummary) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *RoomSummary) GetCreatedAtUtc() int64 {
+	if x != nil {
+		return x.CreatedAtUtc
+	}
+	return 0
+}
+
+func (x *RoomSummary) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *RoomSummary) GetPersistent() bool {
+	if x != nil {
+		return x.Persistent
+	}
+	return false
+}
+
+func (x *RoomSummary) GetArchived() bool {
+	if x != nil {
+		return x.Archived
+	}
+	return false
+}
+
+func (x *RoomSummary) GetAccess() AccessPolicy {
+	if x != nil {
+		return x.Access
+	}
+	return AccessPolicy_ACCESS_POLICY_UNSPECIFIED
+}
+
+func (x *RoomSummary) GetMaxParticipants() uint32 {
+	if x != nil {
+		return x.MaxParticipants
+	}
+	return 0
+}
+
+// CreateRoomRequest creates a persistent room owned by user_id, who does not join it.
+// When the call is authenticated, user_id may be omitted and must otherwise match the
+// authenticated user.
+type CreateRoomRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Room        string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title       string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Topic       string                 `protobuf:"bytes,4,opt,name=topic,proto3" json:"topic,omitempty"`
+	Description string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	// visibility defaults to VISIBILITY_PUBLIC.
+	Visibility Visibility `protobuf:"varint,6,opt,name=visibility,proto3,enum=chat.v1.Visibility" json:"visibility,omitempty"`
+	// access defaults to ACCESS_POLICY_PUBLIC; ACCESS_POLICY_PASSWORD requires a password,
+	// which is only accepted for that policy. Rooms that are not public are persistent.
+	Access   AccessPolicy `protobuf:"varint,7,opt,name=access,proto3,enum=chat.v1.AccessPolicy" json:"access,omitempty"`
+	Password string       `protobuf:"bytes,8,opt,name=password,proto3" json:"password,omitempty"`
+	// max_participants caps the users in the room at once; zero leaves it unlimited.
+	MaxParticipants uint32 `protobuf:"varint,9,opt,name=max_participants,json=maxParticipants,proto3" json:"max_participants,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateRoomRequest) Reset() {
+	*x = CreateRoomRequest{}
+	mi := &file_chat_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRoomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRoomRequest) ProtoMessage() {}
+
+func (x *CreateRoomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRoomRequest.ProtoReflect.Descriptor instead.
+func (*CreateRoomRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateRoomRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *CreateRoomRequest) GetAccess() AccessPolicy {
+	if x != nil {
+		return x.Access
+	}
+	return AccessPolicy_ACCESS_POLICY_UNSPECIFIED
+}
+
+func (x *CreateRoomRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *CreateRoomRequest) GetMaxParticipants() uint32 {
+	if x != nil {
+		return x.MaxParticipants
+	}
+	return 0
+}
+
+type CreateRoomResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *RoomSummary           `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRoomResponse) Reset() {
+	*x = CreateRoomResponse{}
+	mi := &file_chat_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRoomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRoomResponse) ProtoMessage() {}
+
+func (x *CreateRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRoomResponse.ProtoReflect.Descriptor instead.
+func (*CreateRoomResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateRoomResponse) GetRoom() *RoomSummary {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
+
+// UpdateRoomRequest changes the metadata of a room on behalf of user_id, following the
+// identity rules of CreateRoomRequest. Fields left unset, and VISIBILITY_UNSPECIFIED, keep
+// their current value. Changing the topic takes ROLE_MODERATOR and is announced to the room
+// with a TYPE_TOPIC_CHANGED notice; the other fields take ROLE_OWNER. Changing the access
+// policy or the password makes users admitted under the previous settings need the new
+// password or an invite the next time they join.
+type UpdateRoomRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Room        string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId      string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title       *string                `protobuf:"bytes,3,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Topic       *string                `protobuf:"bytes,4,opt,name=topic,proto3,oneof" json:"topic,omitempty"`
+	Description *string                `protobuf:"bytes,5,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Visibility  Visibility             `protobuf:"varint,6,opt,name=visibility,proto3,enum=chat.v1.Visibility" json:"visibility,omitempty"`
+	Access      AccessPolicy           `protobuf:"varint,7,opt,name=access,proto3,enum=chat.v1.AccessPolicy" json:"access,omitempty"`
+	// password replaces the password of an ACCESS_POLICY_PASSWORD room.
+	Password *string `protobuf:"bytes,8,opt,name=password,proto3,oneof" json:"password,omitempty"`
+	// max_participants replaces the room's participant limit; zero lifts it. Raising it
+	// admits the joins waiting for a seat.
+	MaxParticipants *uint32 `protobuf:"varint,9,opt,name=max_participants,json=maxParticipants,proto3,oneof" json:"max_participants,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateRoomRequest) Reset() {
+	*x = UpdateRoomRequest{}
+	mi := &file_chat_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRoomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRoomRequest) ProtoMessage() {}
+
+func (x *UpdateRoomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRoomRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRoomRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UpdateRoomRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetTopic() string {
+	if x != nil && x.Topic != nil {
+		return *x.Topic
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *UpdateRoomRequest) GetAccess() AccessPolicy {
+	if x != nil {
+		return x.Access
+	}
+	return AccessPolicy_ACCESS_POLICY_UNSPECIFIED
+}
+
+func (x *UpdateRoomRequest) GetPassword() string {
+	if x != nil && x.Password != nil {
+		return *x.Password
+	}
+	return ""
+}
+
+func (x *UpdateRoomRequest) GetMaxParticipants() uint32 {
+	if x != nil && x.MaxParticipants != nil {
+		return *x.MaxParticipants
+	}
+	return 0
+}
+
+type UpdateRoomResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *RoomSummary           `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRoomResponse) Reset() {
+	*x = UpdateRoomResponse{}
+	mi := &file_chat_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRoomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRoomResponse) ProtoMessage() {}
+
+func (x *UpdateRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRoomResponse.ProtoReflect.Descriptor instead.
+func (*UpdateRoomResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UpdateRoomResponse) GetRoom() *RoomSummary {
+	if x != nil {
+		return x.Room
+	}
+	return nil
 }
 
-type ClientEnvelope_Chat struct {
-	Chat *ChatPayload `protobuf:"bytes,2,opt,name=chat,proto3,oneof"`
+// ArchiveRoomRequest archives a room on behalf of its owner, following the identity rules
+// of CreateRoomRequest. Every session of the room receives a TYPE_SESSION_CLOSED notice.
+type ArchiveRoomRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveRoomRequest) Reset() {
+	*x = ArchiveRoomRequest{}
+	mi := &file_chat_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveRoomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveRoomRequest) ProtoMessage() {}
+
+func (x *ArchiveRoomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveRoomRequest.ProtoReflect.Descriptor instead.
+func (*ArchiveRoomRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ArchiveRoomRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ArchiveRoomRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ArchiveRoomResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          *RoomSummary           `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveRoomResponse) Reset() {
+	*x = ArchiveRoomResponse{}
+	mi := &file_chat_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveRoomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveRoomResponse) ProtoMessage() {}
+
+func (x *ArchiveRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveRoomResponse.ProtoReflect.Descriptor instead.
+func (*ArchiveRoomResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ArchiveRoomResponse) GetRoom() *RoomSummary {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
+
+// Invite admits its holder into a room that is not public, through JoinRequest.invite_token.
+type Invite struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Token     string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Room      string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
+	CreatedBy string                 `protobuf:"bytes,3,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// created_at_utc and expires_at_utc are Unix milliseconds; expires_at_utc is zero for
+	// invites that never expire.
+	CreatedAtUtc int64 `protobuf:"varint,4,opt,name=created_at_utc,json=createdAtUtc,proto3" json:"created_at_utc,omitempty"`
+	ExpiresAtUtc int64 `protobuf:"varint,5,opt,name=expires_at_utc,json=expiresAtUtc,proto3" json:"expires_at_utc,omitempty"`
+	// max_uses caps the joins the invite admits, zero meaning unlimited; uses counts them.
+	MaxUses       uint32 `protobuf:"varint,6,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	Uses          uint32 `protobuf:"varint,7,opt,name=uses,proto3" json:"uses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Invite) Reset() {
+	*x = Invite{}
+	mi := &file_chat_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invite) ProtoMessage() {}
+
+func (x *Invite) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invite.ProtoReflect.Descriptor instead.
+func (*Invite) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Invite) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Invite) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *Invite) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Invite) GetCreatedAtUtc() int64 {
+	if x != nil {
+		return x.CreatedAtUtc
+	}
+	return 0
+}
+
+func (x *Invite) GetExpiresAtUtc() int64 {
+	if x != nil {
+		return x.ExpiresAtUtc
+	}
+	return 0
+}
+
+func (x *Invite) GetMaxUses() uint32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+func (x *Invite) GetUses() uint32 {
+	if x != nil {
+		return x.Uses
+	}
+	return 0
+}
+
+// CreateInviteRequest issues an invite on behalf of the room's owner, following the
+// identity rules of CreateRoomRequest.
+type CreateInviteRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Room   string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// ttl_seconds is how long the invite is accepted; zero issues one that never expires.
+	TtlSeconds int64 `protobuf:"varint,3,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	// max_uses caps the joins the invite admits; zero leaves it unlimited.
+	MaxUses       uint32 `protobuf:"varint,4,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInviteRequest) Reset() {
+	*x = CreateInviteRequest{}
+	mi := &file_chat_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInviteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInviteRequest) ProtoMessage() {}
+
+func (x *CreateInviteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInviteRequest.ProtoReflect.Descriptor instead.
+func (*CreateInviteRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CreateInviteRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *CreateInviteRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateInviteRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *CreateInviteRequest) GetMaxUses() uint32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+type CreateInviteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Invite        *Invite                `protobuf:"bytes,1,opt,name=invite,proto3" json:"invite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateInviteResponse) Reset() {
+	*x = CreateInviteResponse{}
+	mi := &file_chat_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateInviteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateInviteResponse) ProtoMessage() {}
+
+func (x *CreateInviteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateInviteResponse.ProtoReflect.Descriptor instead.
+func (*CreateInviteResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CreateInviteResponse) GetInvite() *Invite {
+	if x != nil {
+		return x.Invite
+	}
+	return nil
+}
+
+// RevokeInviteRequest withdraws an invite on behalf of the room's owner. Users it already
+// admitted keep their access.
+type RevokeInviteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeInviteRequest) Reset() {
+	*x = RevokeInviteRequest{}
+	mi := &file_chat_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeInviteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeInviteRequest) ProtoMessage() {}
+
+func (x *RevokeInviteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeInviteRequest.ProtoReflect.Descriptor instead.
+func (*RevokeInviteRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RevokeInviteRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *RevokeInviteRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RevokeInviteRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RevokeInviteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeInviteResponse) Reset() {
+	*x = RevokeInviteResponse{}
+	mi := &file_chat_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type ClientEnvelope_Leave struct {
-	Leave *LeaveRequest `protobuf:"bytes,3,opt,name=leave,proto3,oneof"`
+func (x *RevokeInviteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientEnvelope_Join) isClientEnvelope_Message() {}
+func (*RevokeInviteResponse) ProtoMessage() {}
 
-func (*ClientEnvelope_Chat) isClientEnvelope_Message() {}
+func (x *RevokeInviteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-func (*ClientEnvelope_Leave) isClientEnvelope_Message() {}
+// Deprecated: Use RevokeInviteResponse.ProtoReflect.Descriptor instead.
+func (*RevokeInviteResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{37}
+}
 
-// JoinAck confirms that the user joined the requested room.
-type JoinAck struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	UserId         string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Room           string                 `protobuf:"bytes,2,opt,name=room,proto3" json:"room,omitempty"`
-	WelcomeMessage string                 `protobuf:"bytes,3,opt,name=welcome_message,json=welcomeMessage,proto3" json:"welcome_message,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type ListRoomsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Rooms []*RoomSummary         `protobuf:"bytes,1,rep,name=rooms,proto3" json:"rooms,omitempty"`
+	// next_page_token fetches the following page; empty on the last one.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JoinAck) Reset() {
-	*x = JoinAck{}
-	mi := &file_chat_proto_msgTypes[4]
+func (x *ListRoomsResponse) Reset() {
+	*x = ListRoomsResponse{}
+	mi := &file_chat_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JoinAck) String() string {
+func (x *ListRoomsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JoinAck) ProtoMessage() {}
+func (*ListRoomsResponse) ProtoMessage() {}
 
-func (x *JoinAck) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_proto_msgTypes[4]
+func (x *ListRoomsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -390,60 +3668,107 @@ func (x *JoinAck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JoinAck.ProtoReflect.Descriptor instead.
-func (*JoinAck) Descriptor() ([]byte, []int) {
-	return file_chat_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ListRoomsResponse.ProtoReflect.Descriptor instead.
+func (*ListRoomsResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *JoinAck) GetUserId() string {
+func (x *ListRoomsResponse) GetRooms() []*RoomSummary {
 	if x != nil {
-		return x.UserId
+		return x.Rooms
+	}
+	return nil
+}
+
+func (x *ListRoomsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
 	}
 	return ""
 }
 
-func (x *JoinAck) GetRoom() string {
+// GetRoomRequest asks for a room and its participants. The participants of rooms that are
+// not public are only listed to their members and owner; user_id names the caller and
+// follows the identity rules of CreateRoomRequest.
+type GetRoomRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRoomRequest) Reset() {
+	*x = GetRoomRequest{}
+	mi := &file_chat_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRoomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRoomRequest) ProtoMessage() {}
+
+func (x *GetRoomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRoomRequest.ProtoReflect.Descriptor instead.
+func (*GetRoomRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetRoomRequest) GetRoom() string {
 	if x != nil {
 		return x.Room
 	}
 	return ""
 }
 
-func (x *JoinAck) GetWelcomeMessage() string {
+func (x *GetRoomRequest) GetUserId() string {
 	if x != nil {
-		return x.WelcomeMessage
+		return x.UserId
 	}
 	return ""
 }
 
-// Broadcast envelope for server -> client communication.
-type ServerEvent struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Event:
-	//
-	//	*ServerEvent_Joined
-	//	*ServerEvent_Broadcast
-	//	*ServerEvent_Notice
-	Event         isServerEvent_Event `protobuf_oneof:"event"`
+// Participant is a member of a room.
+type Participant struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DisplayName string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// joined_at_utc is the Unix time in milliseconds at which the user joined.
+	JoinedAtUtc   int64 `protobuf:"varint,3,opt,name=joined_at_utc,json=joinedAtUtc,proto3" json:"joined_at_utc,omitempty"`
+	Role          Role  `protobuf:"varint,4,opt,name=role,proto3,enum=chat.v1.Role" json:"role,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServerEvent) Reset() {
-	*x = ServerEvent{}
-	mi := &file_chat_proto_msgTypes[5]
+func (x *Participant) Reset() {
+	*x = Participant{}
+	mi := &file_chat_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServerEvent) String() string {
+func (x *Participant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServerEvent) ProtoMessage() {}
+func (*Participant) ProtoMessage() {}
 
-func (x *ServerEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_proto_msgTypes[5]
+func (x *Participant) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -454,93 +3779,125 @@ func (x *ServerEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServerEvent.ProtoReflect.Descriptor instead.
-func (*ServerEvent) Descriptor() ([]byte, []int) {
-	return file_chat_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use Participant.ProtoReflect.Descriptor instead.
+func (*Participant) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *ServerEvent) GetEvent() isServerEvent_Event {
+func (x *Participant) GetUserId() string {
 	if x != nil {
-		return x.Event
+		return x.UserId
 	}
-	return nil
+	return ""
 }
 
-func (x *ServerEvent) GetJoined() *JoinAck {
+func (x *Participant) GetDisplayName() string {
 	if x != nil {
-		if x, ok := x.Event.(*ServerEvent_Joined); ok {
-			return x.Joined
-		}
+		return x.DisplayName
 	}
-	return nil
+	return ""
 }
 
-func (x *ServerEvent) GetBroadcast() *ChatPayload {
+func (x *Participant) GetJoinedAtUtc() int64 {
 	if x != nil {
-		if x, ok := x.Event.(*ServerEvent_Broadcast); ok {
-			return x.Broadcast
-		}
+		return x.JoinedAtUtc
 	}
-	return nil
+	return 0
 }
 
-func (x *ServerEvent) GetNotice() *ServerNotice {
+func (x *Participant) GetRole() Role {
 	if x != nil {
-		if x, ok := x.Event.(*ServerEvent_Notice); ok {
-			return x.Notice
-		}
+		return x.Role
 	}
-	return nil
+	return Role_ROLE_UNSPECIFIED
 }
 
-type isServerEvent_Event interface {
-	isServerEvent_Event()
+type GetRoomResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  *RoomSummary           `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// participants are ordered by user_id.
+	Participants  []*Participant `protobuf:"bytes,2,rep,name=participants,proto3" json:"participants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-type ServerEvent_Joined struct {
-	Joined *JoinAck `protobuf:"bytes,1,opt,name=joined,proto3,oneof"`
+func (x *GetRoomResponse) Reset() {
+	*x = GetRoomResponse{}
+	mi := &file_chat_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-type ServerEvent_Broadcast struct {
-	Broadcast *ChatPayload `protobuf:"bytes,2,opt,name=broadcast,proto3,oneof"`
+func (x *GetRoomResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type ServerEvent_Notice struct {
-	Notice *ServerNotice `protobuf:"bytes,3,opt,name=notice,proto3,oneof"`
+func (*GetRoomResponse) ProtoMessage() {}
+
+func (x *GetRoomResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (*ServerEvent_Joined) isServerEvent_Event() {}
+// Deprecated: Use GetRoomResponse.ProtoReflect.Descriptor instead.
+func (*GetRoomResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{41}
+}
 
-func (*ServerEvent_Broadcast) isServerEvent_Event() {}
+func (x *GetRoomResponse) GetRoom() *RoomSummary {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
 
-func (*ServerEvent_Notice) isServerEvent_Event() {}
+func (x *GetRoomResponse) GetParticipants() []*Participant {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
 
-// ServerNotice conveys system-level announcements (errors, user events).
-type ServerNotice struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          ServerNotice_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=chat.v1.ServerNotice_Type" json:"type,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Room          string                 `protobuf:"bytes,4,opt,name=room,proto3" json:"room,omitempty"`
+// GetThreadRequest pages through the replies of a thread, oldest first.
+type GetThreadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// message_id names the thread root; naming one of its replies selects the same thread.
+	MessageId string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// page_size caps the replies returned; zero selects the server default.
+	PageSize uint32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page; empty for the first page.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// user_id names the caller and follows the identity rules of CreateRoomRequest. Users
+	// banned from the room, and callers who are not members of a room that is not public,
+	// are rejected with PERMISSION_DENIED.
+	UserId        string `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ServerNotice) Reset() {
-	*x = ServerNotice{}
-	mi := &file_chat_proto_msgTypes[6]
+func (x *GetThreadRequest) Reset() {
+	*x = GetThreadRequest{}
+	mi := &file_chat_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ServerNotice) String() string {
+func (x *GetThreadRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServerNotice) ProtoMessage() {}
+func (*GetThreadRequest) ProtoMessage() {}
 
-func (x *ServerNotice) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_proto_msgTypes[6]
+func (x *GetThreadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -551,35 +3908,104 @@ func (x *ServerNotice) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ServerNotice.ProtoReflect.Descriptor instead.
-func (*ServerNotice) Descriptor() ([]byte, []int) {
-	return file_chat_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use GetThreadRequest.ProtoReflect.Descriptor instead.
+func (*GetThreadRequest) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ServerNotice) GetType() ServerNotice_Type {
+func (x *GetThreadRequest) GetRoom() string {
 	if x != nil {
-		return x.Type
+		return x.Room
 	}
-	return ServerNotice_TYPE_GENERIC
+	return ""
 }
 
-func (x *ServerNotice) GetMessage() string {
+func (x *GetThreadRequest) GetMessageId() string {
 	if x != nil {
-		return x.Message
+		return x.MessageId
 	}
 	return ""
 }
 
-func (x *ServerNotice) GetUserId() string {
+func (x *GetThreadRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetThreadRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetThreadRequest) GetUserId() string {
 	if x != nil {
 		return x.UserId
 	}
 	return ""
 }
 
-func (x *ServerNotice) GetRoom() string {
+type GetThreadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// root is the message that started the thread, with its current reply_count.
+	Root    *ChatPayload   `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	Replies []*ChatPayload `protobuf:"bytes,2,rep,name=replies,proto3" json:"replies,omitempty"`
+	// next_page_token fetches the following page; empty on the last one.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThreadResponse) Reset() {
+	*x = GetThreadResponse{}
+	mi := &file_chat_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThreadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThreadResponse) ProtoMessage() {}
+
+func (x *GetThreadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_proto_msgTypes[43]
 	if x != nil {
-		return x.Room
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThreadResponse.ProtoReflect.Descriptor instead.
+func (*GetThreadResponse) Descriptor() ([]byte, []int) {
+	return file_chat_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetThreadResponse) GetRoot() *ChatPayload {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *GetThreadResponse) GetReplies() []*ChatPayload {
+	if x != nil {
+		return x.Replies
+	}
+	return nil
+}
+
+func (x *GetThreadResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
 	}
 	return ""
 }
@@ -589,46 +4015,370 @@ var File_chat_proto protoreflect.FileDescriptor
 const file_chat_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"chat.proto\x12\achat.v1\"]\n" +
+	"chat.proto\x12\achat.v1\"\x91\x02\n" +
 	"\vJoinRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
 	"\x04room\x18\x02 \x01(\tR\x04room\x12!\n" +
-	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\"y\n" +
-	"\vChatPayload\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
-	"\x04room\x18\x02 \x01(\tR\x04room\x12\x18\n" +
+	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\x12#\n" +
+	"\rhistory_limit\x18\x04 \x01(\rR\fhistoryLimit\x12*\n" +
+	"\x11history_since_utc\x18\x05 \x01(\x03R\x0fhistorySinceUtc\x12\x1a\n" +
+	"\bpassword\x18\x06 \x01(\tR\bpassword\x12!\n" +
+	"\finvite_token\x18\a \x01(\tR\vinviteToken\x12\"\n" +
+	"\rwait_for_seat\x18\b \x01(\bR\vwaitForSeat\"\x9a\x03\n" +
+	"\vChatPayload\x12\x1c\n" +
+	"\auser_id\x18\x01 \x01(\tH\x00R\x06userId\x88\x01\x01\x12\x17\n" +
+	"\x04room\x18\x02 \x01(\tH\x01R\x04room\x88\x01\x01\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12#\n" +
-	"\rtimestamp_utc\x18\x04 \x01(\x03R\ftimestampUtc\";\n" +
-	"\fLeaveRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
-	"\x04room\x18\x02 \x01(\tR\x04room\"\xa2\x01\n" +
+	"\rtimestamp_utc\x18\x04 \x01(\x03R\ftimestampUtc\x12\x1a\n" +
+	"\breplayed\x18\x05 \x01(\bR\breplayed\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x06 \x01(\tR\tmessageId\x12\x1a\n" +
+	"\bsequence\x18\a \x01(\x04R\bsequence\x12\"\n" +
+	"\redited_at_utc\x18\b \x01(\x03R\veditedAtUtc\x12\x18\n" +
+	"\adeleted\x18\t \x01(\bR\adeleted\x12/\n" +
+	"\treactions\x18\n" +
+	" \x03(\v2\x11.chat.v1.ReactionR\treactions\x12\x19\n" +
+	"\breply_to\x18\v \x01(\tR\areplyTo\x12\x1f\n" +
+	"\vreply_count\x18\f \x01(\rR\n" +
+	"replyCountB\n" +
+	"\n" +
+	"\b_user_idB\a\n" +
+	"\x05_room\"Q\n" +
+	"\bReaction\x12\x14\n" +
+	"\x05emoji\x18\x01 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\rR\x05count\x12\x19\n" +
+	"\buser_ids\x18\x03 \x03(\tR\auserIds\"o\n" +
+	"\x12EditMessageRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontentB\a\n" +
+	"\x05_room\"W\n" +
+	"\x14DeleteMessageRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageIdB\a\n" +
+	"\x05_room\"h\n" +
+	"\x0fReactionRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x03 \x01(\tR\x05emojiB\a\n" +
+	"\x05_room\"n\n" +
+	"\x0eSetRoleRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12!\n" +
+	"\x04role\x18\x03 \x01(\x0e2\r.chat.v1.RoleR\x04roleB\a\n" +
+	"\x05_room\"\xc4\x01\n" +
+	"\x11ModerationRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x121\n" +
+	"\x06action\x18\x03 \x01(\x0e2\x19.chat.v1.ModerationActionR\x06action\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12)\n" +
+	"\x10duration_seconds\x18\x05 \x01(\rR\x0fdurationSecondsB\a\n" +
+	"\x05_room\"Z\n" +
+	"\fLeaveRequest\x12\x1c\n" +
+	"\auser_id\x18\x01 \x01(\tH\x00R\x06userId\x88\x01\x01\x12\x17\n" +
+	"\x04room\x18\x02 \x01(\tH\x01R\x04room\x88\x01\x01B\n" +
+	"\n" +
+	"\b_user_idB\a\n" +
+	"\x05_room\"W\n" +
+	"\rResumeRequest\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\x12#\n" +
+	"\rlast_sequence\x18\x02 \x01(\x04R\flastSequence\"N\n" +
+	"\x14DirectMessageRequest\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\x01 \x01(\tR\btoUserId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"I\n" +
+	"\rTypingRequest\x12\x17\n" +
+	"\x04room\x18\x01 \x01(\tH\x00R\x04room\x88\x01\x01\x12\x16\n" +
+	"\x06typing\x18\x02 \x01(\bR\x06typingB\a\n" +
+	"\x05_room\"#\n" +
+	"\vPingRequest\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\x04R\x05nonce\"\xce\x05\n" +
 	"\x0eClientEnvelope\x12*\n" +
 	"\x04join\x18\x01 \x01(\v2\x14.chat.v1.JoinRequestH\x00R\x04join\x12*\n" +
 	"\x04chat\x18\x02 \x01(\v2\x14.chat.v1.ChatPayloadH\x00R\x04chat\x12-\n" +
-	"\x05leave\x18\x03 \x01(\v2\x15.chat.v1.LeaveRequestH\x00R\x05leaveB\t\n" +
-	"\amessage\"_\n" +
+	"\x05leave\x18\x03 \x01(\v2\x15.chat.v1.LeaveRequestH\x00R\x05leave\x120\n" +
+	"\x06resume\x18\x04 \x01(\v2\x16.chat.v1.ResumeRequestH\x00R\x06resume\x127\n" +
+	"\x06direct\x18\x05 \x01(\v2\x1d.chat.v1.DirectMessageRequestH\x00R\x06direct\x120\n" +
+	"\x06typing\x18\x06 \x01(\v2\x16.chat.v1.TypingRequestH\x00R\x06typing\x12*\n" +
+	"\x04ping\x18\a \x01(\v2\x14.chat.v1.PingRequestH\x00R\x04ping\x121\n" +
+	"\x04edit\x18\t \x01(\v2\x1b.chat.v1.EditMessageRequestH\x00R\x04edit\x127\n" +
+	"\x06delete\x18\n" +
+	" \x01(\v2\x1d.chat.v1.DeleteMessageRequestH\x00R\x06delete\x120\n" +
+	"\x05react\x18\v \x01(\v2\x18.chat.v1.ReactionRequestH\x00R\x05react\x124\n" +
+	"\aunreact\x18\f \x01(\v2\x18.chat.v1.ReactionRequestH\x00R\aunreact\x128\n" +
+	"\bmoderate\x18\r \x01(\v2\x1a.chat.v1.ModerationRequestH\x00R\bmoderate\x124\n" +
+	"\bset_role\x18\x0e \x01(\v2\x17.chat.v1.SetRoleRequestH\x00R\asetRole\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\b \x01(\tR\trequestIdB\t\n" +
+	"\amessage\"\xbf\x01\n" +
 	"\aJoinAck\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
 	"\x04room\x18\x02 \x01(\tR\x04room\x12'\n" +
-	"\x0fwelcome_message\x18\x03 \x01(\tR\x0ewelcomeMessage\"\xa9\x01\n" +
+	"\x0fwelcome_message\x18\x03 \x01(\tR\x0ewelcomeMessage\x12!\n" +
+	"\fresume_token\x18\x04 \x01(\tR\vresumeToken\x12\x18\n" +
+	"\aresumed\x18\x05 \x01(\bR\aresumed\x12!\n" +
+	"\x04role\x18\x06 \x01(\x0e2\r.chat.v1.RoleR\x04role\"\xf1\x01\n" +
+	"\rDirectMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
+	"fromUserId\x12*\n" +
+	"\x11from_display_name\x18\x03 \x01(\tR\x0ffromDisplayName\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\x04 \x01(\tR\btoUserId\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12#\n" +
+	"\rtimestamp_utc\x18\x06 \x01(\x03R\ftimestampUtc\x12\x16\n" +
+	"\x06queued\x18\a \x01(\bR\x06queued\"e\n" +
+	"\x0fTypingIndicator\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x16\n" +
+	"\x06typing\x18\x03 \x01(\bR\x06typing\"A\n" +
+	"\x04Pong\x12\x14\n" +
+	"\x05nonce\x18\x01 \x01(\x04R\x05nonce\x12#\n" +
+	"\rtimestamp_utc\x18\x02 \x01(\x03R\ftimestampUtc\"\x98\x01\n" +
+	"\aSendAck\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x04R\bsequence\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\rR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xbc\x01\n" +
+	"\rMessageEdited\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\"\n" +
+	"\redited_at_utc\x18\x04 \x01(\x03R\veditedAtUtc\x12\x19\n" +
+	"\bevent_id\x18\x05 \x01(\tR\aeventId\x12\x1a\n" +
+	"\bsequence\x18\x06 \x01(\x04R\bsequence\"\xa5\x01\n" +
+	"\x0eMessageDeleted\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12$\n" +
+	"\x0edeleted_at_utc\x18\x03 \x01(\x03R\fdeletedAtUtc\x12\x19\n" +
+	"\bevent_id\x18\x04 \x01(\tR\aeventId\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x04R\bsequence\"\xc0\x01\n" +
+	"\rReactionDelta\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05added\x18\x04 \x01(\bR\x05added\x12\x14\n" +
+	"\x05count\x18\x05 \x01(\rR\x05count\x12\x19\n" +
+	"\bevent_id\x18\x06 \x01(\tR\aeventId\x12\x1a\n" +
+	"\bsequence\x18\a \x01(\x04R\bsequence\"\xc3\x01\n" +
+	"\fThreadUpdate\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x1f\n" +
+	"\vreply_count\x18\x02 \x01(\rR\n" +
+	"replyCount\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12#\n" +
+	"\rtimestamp_utc\x18\x04 \x01(\x03R\ftimestampUtc\x12\x19\n" +
+	"\bevent_id\x18\x05 \x01(\tR\aeventId\x12\x1a\n" +
+	"\bsequence\x18\x06 \x01(\x04R\bsequence\"\xbc\x04\n" +
 	"\vServerEvent\x12*\n" +
 	"\x06joined\x18\x01 \x01(\v2\x10.chat.v1.JoinAckH\x00R\x06joined\x124\n" +
 	"\tbroadcast\x18\x02 \x01(\v2\x14.chat.v1.ChatPayloadH\x00R\tbroadcast\x12/\n" +
-	"\x06notice\x18\x03 \x01(\v2\x15.chat.v1.ServerNoticeH\x00R\x06noticeB\a\n" +
-	"\x05event\"\xd9\x01\n" +
+	"\x06notice\x18\x03 \x01(\v2\x15.chat.v1.ServerNoticeH\x00R\x06notice\x120\n" +
+	"\x06direct\x18\x05 \x01(\v2\x16.chat.v1.DirectMessageH\x00R\x06direct\x122\n" +
+	"\x06typing\x18\x06 \x01(\v2\x18.chat.v1.TypingIndicatorH\x00R\x06typing\x12#\n" +
+	"\x04pong\x18\a \x01(\v2\r.chat.v1.PongH\x00R\x04pong\x12$\n" +
+	"\x03ack\x18\b \x01(\v2\x10.chat.v1.SendAckH\x00R\x03ack\x120\n" +
+	"\x06edited\x18\t \x01(\v2\x16.chat.v1.MessageEditedH\x00R\x06edited\x123\n" +
+	"\adeleted\x18\n" +
+	" \x01(\v2\x17.chat.v1.MessageDeletedH\x00R\adeleted\x124\n" +
+	"\breaction\x18\v \x01(\v2\x16.chat.v1.ReactionDeltaH\x00R\breaction\x12/\n" +
+	"\x06thread\x18\f \x01(\v2\x15.chat.v1.ThreadUpdateH\x00R\x06thread\x12\x12\n" +
+	"\x04room\x18\x04 \x01(\tR\x04roomB\a\n" +
+	"\x05event\"\xb5\x06\n" +
 	"\fServerNotice\x12.\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x1a.chat.v1.ServerNotice.TypeR\x04type\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x17\n" +
 	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x12\n" +
-	"\x04room\x18\x04 \x01(\tR\x04room\"R\n" +
+	"\x04room\x18\x04 \x01(\tR\x04room\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x05 \x01(\tR\tmessageId\x12\x1a\n" +
+	"\bsequence\x18\x06 \x01(\x04R\bsequence\x12#\n" +
+	"\rmissed_events\x18\a \x01(\x04R\fmissedEvents\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\t \x01(\rR\terrorCode\x12\x1a\n" +
+	"\benvelope\x18\n" +
+	" \x01(\tR\benvelope\x12%\n" +
+	"\x0eenvelope_index\x18\v \x01(\x04R\renvelopeIndex\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\f \x01(\tR\trequestId\x121\n" +
+	"\x06action\x18\r \x01(\x0e2\x19.chat.v1.ModerationActionR\x06action\x12\"\n" +
+	"\ractor_user_id\x18\x0e \x01(\tR\vactorUserId\x12\x1b\n" +
+	"\tuntil_utc\x18\x0f \x01(\x03R\buntilUtc\x12!\n" +
+	"\x04role\x18\x10 \x01(\x0e2\r.chat.v1.RoleR\x04role\x12\x14\n" +
+	"\x05topic\x18\x11 \x01(\tR\x05topic\x12%\n" +
+	"\x0equeue_position\x18\x12 \x01(\rR\rqueuePosition\"\xe0\x01\n" +
 	"\x04Type\x12\x10\n" +
 	"\fTYPE_GENERIC\x10\x00\x12\x14\n" +
 	"\x10TYPE_USER_JOINED\x10\x01\x12\x12\n" +
 	"\x0eTYPE_USER_LEFT\x10\x02\x12\x0e\n" +
 	"\n" +
-	"TYPE_ERROR\x10\x032K\n" +
+	"TYPE_ERROR\x10\x03\x12\x16\n" +
+	"\x12TYPE_EVENTS_MISSED\x10\x04\x12\x17\n" +
+	"\x13TYPE_SESSION_CLOSED\x10\x05\x12\x13\n" +
+	"\x0fTYPE_MODERATION\x10\x06\x12\x15\n" +
+	"\x11TYPE_ROLE_CHANGED\x10\a\x12\x16\n" +
+	"\x12TYPE_TOPIC_CHANGED\x10\b\x12\x17\n" +
+	"\x13TYPE_QUEUE_POSITION\x10\t\"o\n" +
+	"\x10ListRoomsRequest\x12\x1f\n" +
+	"\vname_prefix\x18\x01 \x01(\tR\n" +
+	"namePrefix\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\rR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xd1\x03\n" +
+	"\vRoomSummary\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12+\n" +
+	"\x11participant_count\x18\x02 \x01(\rR\x10participantCount\x12#\n" +
+	"\rlast_sequence\x18\x03 \x01(\x04R\flastSequence\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x14\n" +
+	"\x05topic\x18\x05 \x01(\tR\x05topic\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\a \x01(\tR\tcreatedBy\x12$\n" +
+	"\x0ecreated_at_utc\x18\b \x01(\x03R\fcreatedAtUtc\x123\n" +
+	"\n" +
+	"visibility\x18\t \x01(\x0e2\x13.chat.v1.VisibilityR\n" +
+	"visibility\x12\x1e\n" +
+	"\n" +
+	"persistent\x18\n" +
+	" \x01(\bR\n" +
+	"persistent\x12\x1a\n" +
+	"\barchived\x18\v \x01(\bR\barchived\x12-\n" +
+	"\x06access\x18\f \x01(\x0e2\x15.chat.v1.AccessPolicyR\x06access\x12)\n" +
+	"\x10max_participants\x18\r \x01(\rR\x0fmaxParticipants\"\xb9\x02\n" +
+	"\x11CreateRoomRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05topic\x18\x04 \x01(\tR\x05topic\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x123\n" +
+	"\n" +
+	"visibility\x18\x06 \x01(\x0e2\x13.chat.v1.VisibilityR\n" +
+	"visibility\x12-\n" +
+	"\x06access\x18\a \x01(\x0e2\x15.chat.v1.AccessPolicyR\x06access\x12\x1a\n" +
+	"\bpassword\x18\b \x01(\tR\bpassword\x12)\n" +
+	"\x10max_participants\x18\t \x01(\rR\x0fmaxParticipants\">\n" +
+	"\x12CreateRoomResponse\x12(\n" +
+	"\x04room\x18\x01 \x01(\v2\x14.chat.v1.RoomSummaryR\x04room\"\x98\x03\n" +
+	"\x11UpdateRoomRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\x05title\x18\x03 \x01(\tH\x00R\x05title\x88\x01\x01\x12\x19\n" +
+	"\x05topic\x18\x04 \x01(\tH\x01R\x05topic\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x05 \x01(\tH\x02R\vdescription\x88\x01\x01\x123\n" +
+	"\n" +
+	"visibility\x18\x06 \x01(\x0e2\x13.chat.v1.VisibilityR\n" +
+	"visibility\x12-\n" +
+	"\x06access\x18\a \x01(\x0e2\x15.chat.v1.AccessPolicyR\x06access\x12\x1f\n" +
+	"\bpassword\x18\b \x01(\tH\x03R\bpassword\x88\x01\x01\x12.\n" +
+	"\x10max_participants\x18\t \x01(\rH\x04R\x0fmaxParticipants\x88\x01\x01B\b\n" +
+	"\x06_titleB\b\n" +
+	"\x06_topicB\x0e\n" +
+	"\f_descriptionB\v\n" +
+	"\t_passwordB\x13\n" +
+	"\x11_max_participants\">\n" +
+	"\x12UpdateRoomResponse\x12(\n" +
+	"\x04room\x18\x01 \x01(\v2\x14.chat.v1.RoomSummaryR\x04room\"A\n" +
+	"\x12ArchiveRoomRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"?\n" +
+	"\x13ArchiveRoomResponse\x12(\n" +
+	"\x04room\x18\x01 \x01(\v2\x14.chat.v1.RoomSummaryR\x04room\"\xcc\x01\n" +
+	"\x06Invite\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04room\x18\x02 \x01(\tR\x04room\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x03 \x01(\tR\tcreatedBy\x12$\n" +
+	"\x0ecreated_at_utc\x18\x04 \x01(\x03R\fcreatedAtUtc\x12$\n" +
+	"\x0eexpires_at_utc\x18\x05 \x01(\x03R\fexpiresAtUtc\x12\x19\n" +
+	"\bmax_uses\x18\x06 \x01(\rR\amaxUses\x12\x12\n" +
+	"\x04uses\x18\a \x01(\rR\x04uses\"~\n" +
+	"\x13CreateInviteRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1f\n" +
+	"\vttl_seconds\x18\x03 \x01(\x03R\n" +
+	"ttlSeconds\x12\x19\n" +
+	"\bmax_uses\x18\x04 \x01(\rR\amaxUses\"?\n" +
+	"\x14CreateInviteResponse\x12'\n" +
+	"\x06invite\x18\x01 \x01(\v2\x0f.chat.v1.InviteR\x06invite\"X\n" +
+	"\x13RevokeInviteRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"\x16\n" +
+	"\x14RevokeInviteResponse\"g\n" +
+	"\x11ListRoomsResponse\x12*\n" +
+	"\x05rooms\x18\x01 \x03(\v2\x14.chat.v1.RoomSummaryR\x05rooms\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"=\n" +
+	"\x0eGetRoomRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x90\x01\n" +
+	"\vParticipant\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\"\n" +
+	"\rjoined_at_utc\x18\x03 \x01(\x03R\vjoinedAtUtc\x12!\n" +
+	"\x04role\x18\x04 \x01(\x0e2\r.chat.v1.RoleR\x04role\"u\n" +
+	"\x0fGetRoomResponse\x12(\n" +
+	"\x04room\x18\x01 \x01(\v2\x14.chat.v1.RoomSummaryR\x04room\x128\n" +
+	"\fparticipants\x18\x02 \x03(\v2\x14.chat.v1.ParticipantR\fparticipants\"\x9a\x01\n" +
+	"\x10GetThreadRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\rR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\"\x95\x01\n" +
+	"\x11GetThreadResponse\x12(\n" +
+	"\x04root\x18\x01 \x01(\v2\x14.chat.v1.ChatPayloadR\x04root\x12.\n" +
+	"\areplies\x18\x02 \x03(\v2\x14.chat.v1.ChatPayloadR\areplies\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken*e\n" +
+	"\x04Role\x12\x14\n" +
+	"\x10ROLE_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eROLE_READ_ONLY\x10\x01\x12\x0f\n" +
+	"\vROLE_MEMBER\x10\x02\x12\x12\n" +
+	"\x0eROLE_MODERATOR\x10\x03\x12\x0e\n" +
+	"\n" +
+	"ROLE_OWNER\x10\x04*\xc3\x01\n" +
+	"\x10ModerationAction\x12!\n" +
+	"\x1dMODERATION_ACTION_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16MODERATION_ACTION_KICK\x10\x01\x12\x19\n" +
+	"\x15MODERATION_ACTION_BAN\x10\x02\x12\x1b\n" +
+	"\x17MODERATION_ACTION_UNBAN\x10\x03\x12\x1a\n" +
+	"\x16MODERATION_ACTION_MUTE\x10\x04\x12\x1c\n" +
+	"\x18MODERATION_ACTION_UNMUTE\x10\x05*X\n" +
+	"\n" +
+	"Visibility\x12\x1a\n" +
+	"\x16VISIBILITY_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11VISIBILITY_PUBLIC\x10\x01\x12\x17\n" +
+	"\x13VISIBILITY_UNLISTED\x10\x02*\x82\x01\n" +
+	"\fAccessPolicy\x12\x1d\n" +
+	"\x19ACCESS_POLICY_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ACCESS_POLICY_PUBLIC\x10\x01\x12\x1a\n" +
+	"\x16ACCESS_POLICY_PASSWORD\x10\x02\x12\x1d\n" +
+	"\x19ACCESS_POLICY_INVITE_ONLY\x10\x032\x83\x05\n" +
 	"\vChatService\x12<\n" +
-	"\aChannel\x12\x17.chat.v1.ClientEnvelope\x1a\x14.chat.v1.ServerEvent(\x010\x01B6Z4github.com/lechitz/chat-grpc/api/proto/chatv1;chatv1b\x06proto3"
+	"\aChannel\x12\x17.chat.v1.ClientEnvelope\x1a\x14.chat.v1.ServerEvent(\x010\x01\x12B\n" +
+	"\tListRooms\x12\x19.chat.v1.ListRoomsRequest\x1a\x1a.chat.v1.ListRoomsResponse\x12<\n" +
+	"\aGetRoom\x12\x17.chat.v1.GetRoomRequest\x1a\x18.chat.v1.GetRoomResponse\x12B\n" +
+	"\tGetThread\x12\x19.chat.v1.GetThreadRequest\x1a\x1a.chat.v1.GetThreadResponse\x12E\n" +
+	"\n" +
+	"CreateRoom\x12\x1a.chat.v1.CreateRoomRequest\x1a\x1b.chat.v1.CreateRoomResponse\x12E\n" +
+	"\n" +
+	"UpdateRoom\x12\x1a.chat.v1.UpdateRoomRequest\x1a\x1b.chat.v1.UpdateRoomResponse\x12H\n" +
+	"\vArchiveRoom\x12\x1b.chat.v1.ArchiveRoomRequest\x1a\x1c.chat.v1.ArchiveRoomResponse\x12K\n" +
+	"\fCreateInvite\x12\x1c.chat.v1.CreateInviteRequest\x1a\x1d.chat.v1.CreateInviteResponse\x12K\n" +
+	"\fRevokeInvite\x12\x1c.chat.v1.RevokeInviteRequest\x1a\x1d.chat.v1.RevokeInviteResponseB6Z4github.com/lechitz/chat-grpc/api/proto/chatv1;chatv1b\x06proto3"
 
 var (
 	file_chat_proto_rawDescOnce sync.Once
@@ -642,33 +4392,130 @@ func file_chat_proto_rawDescGZIP() []byte {
 	return file_chat_proto_rawDescData
 }
 
-var file_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
 var file_chat_proto_goTypes = []any{
-	(ServerNotice_Type)(0), // 0: chat.v1.ServerNotice.Type
-	(*JoinRequest)(nil),    // 1: chat.v1.JoinRequest
-	(*ChatPayload)(nil),    // 2: chat.v1.ChatPayload
-	(*LeaveRequest)(nil),   // 3: chat.v1.LeaveRequest
-	(*ClientEnvelope)(nil), // 4: chat.v1.ClientEnvelope
-	(*JoinAck)(nil),        // 5: chat.v1.JoinAck
-	(*ServerEvent)(nil),    // 6: chat.v1.ServerEvent
-	(*ServerNotice)(nil),   // 7: chat.v1.ServerNotice
+	(Role)(0),                    // 0: chat.v1.Role
+	(ModerationAction)(0),        // 1: chat.v1.ModerationAction
+	(Visibility)(0),              // 2: chat.v1.Visibility
+	(AccessPolicy)(0),            // 3: chat.v1.AccessPolicy
+	(ServerNotice_Type)(0),       // 4: chat.v1.ServerNotice.Type
+	(*JoinRequest)(nil),          // 5: chat.v1.JoinRequest
+	(*ChatPayload)(nil),          // 6: chat.v1.ChatPayload
+	(*Reaction)(nil),             // 7: chat.v1.Reaction
+	(*EditMessageRequest)(nil),   // 8: chat.v1.EditMessageRequest
+	(*DeleteMessageRequest)(nil), // 9: chat.v1.DeleteMessageRequest
+	(*ReactionRequest)(nil),      // 10: chat.v1.ReactionRequest
+	(*SetRoleRequest)(nil),       // 11: chat.v1.SetRoleRequest
+	(*ModerationRequest)(nil),    // 12: chat.v1.ModerationRequest
+	(*LeaveRequest)(nil),         // 13: chat.v1.LeaveRequest
+	(*ResumeRequest)(nil),        // 14: chat.v1.ResumeRequest
+	(*DirectMessageRequest)(nil), // 15: chat.v1.DirectMessageRequest
+	(*TypingRequest)(nil),        // 16: chat.v1.TypingRequest
+	(*PingRequest)(nil),          // 17: chat.v1.PingRequest
+	(*ClientEnvelope)(nil),       // 18: chat.v1.ClientEnvelope
+	(*JoinAck)(nil),              // 19: chat.v1.JoinAck
+	(*DirectMessage)(nil),        // 20: chat.v1.DirectMessage
+	(*TypingIndicator)(nil),      // 21: chat.v1.TypingIndicator
+	(*Pong)(nil),                 // 22: chat.v1.Pong
+	(*SendAck)(nil),              // 23: chat.v1.SendAck
+	(*MessageEdited)(nil),        // 24: chat.v1.MessageEdited
+	(*MessageDeleted)(nil),       // 25: chat.v1.MessageDeleted
+	(*ReactionDelta)(nil),        // 26: chat.v1.ReactionDelta
+	(*ThreadUpdate)(nil),         // 27: chat.v1.ThreadUpdate
+	(*ServerEvent)(nil),          // 28: chat.v1.ServerEvent
+	(*ServerNotice)(nil),         // 29: chat.v1.ServerNotice
+	(*ListRoomsRequest)(nil),     // 30: chat.v1.ListRoomsRequest
+	(*RoomSummary)(nil),          // 31: chat.v1.RoomSummary
+	(*CreateRoomRequest)(nil),    // 32: chat.v1.CreateRoomRequest
+	(*CreateRoomResponse)(nil),   // 33: chat.v1.CreateRoomResponse
+	(*UpdateRoomRequest)(nil),    // 34: chat.v1.UpdateRoomRequest
+	(*UpdateRoomResponse)(nil),   // 35: chat.v1.UpdateRoomResponse
+	(*ArchiveRoomRequest)(nil),   // 36: chat.v1.ArchiveRoomRequest
+	(*ArchiveRoomResponse)(nil),  // 37: chat.v1.ArchiveRoomResponse
+	(*Invite)(nil),               // 38: chat.v1.Invite
+	(*CreateInviteRequest)(nil),  // 39: chat.v1.CreateInviteRequest
+	(*CreateInviteResponse)(nil), // 40: chat.v1.CreateInviteResponse
+	(*RevokeInviteRequest)(nil),  // 41: chat.v1.RevokeInviteRequest
+	(*RevokeInviteResponse)(nil), // 42: chat.v1.RevokeInviteResponse
+	(*ListRoomsResponse)(nil),    // 43: chat.v1.ListRoomsResponse
+	(*GetRoomRequest)(nil),       // 44: chat.v1.GetRoomRequest
+	(*Participant)(nil),          // 45: chat.v1.Participant
+	(*GetRoomResponse)(nil),      // 46: chat.v1.GetRoomResponse
+	(*GetThreadRequest)(nil),     // 47: chat.v1.GetThreadRequest
+	(*GetThreadResponse)(nil),    // 48: chat.v1.GetThreadResponse
 }
 var file_chat_proto_depIdxs = []int32{
-	1, // 0: chat.v1.ClientEnvelope.join:type_name -> chat.v1.JoinRequest
-	2, // 1: chat.v1.ClientEnvelope.chat:type_name -> chat.v1.ChatPayload
-	3, // 2: chat.v1.ClientEnvelope.leave:type_name -> chat.v1.LeaveRequest
-	5, // 3: chat.v1.ServerEvent.joined:type_name -> chat.v1.JoinAck
-	2, // 4: chat.v1.ServerEvent.broadcast:type_name -> chat.v1.ChatPayload
-	7, // 5: chat.v1.ServerEvent.notice:type_name -> chat.v1.ServerNotice
-	0, // 6: chat.v1.ServerNotice.type:type_name -> chat.v1.ServerNotice.Type
-	4, // 7: chat.v1.ChatService.Channel:input_type -> chat.v1.ClientEnvelope
-	6, // 8: chat.v1.ChatService.Channel:output_type -> chat.v1.ServerEvent
-	8, // [8:9] is the sub-list for method output_type
-	7, // [7:8] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	7,  // 0: chat.v1.ChatPayload.reactions:type_name -> chat.v1.Reaction
+	0,  // 1: chat.v1.SetRoleRequest.role:type_name -> chat.v1.Role
+	1,  // 2: chat.v1.ModerationRequest.action:type_name -> chat.v1.ModerationAction
+	5,  // 3: chat.v1.ClientEnvelope.join:type_name -> chat.v1.JoinRequest
+	6,  // 4: chat.v1.ClientEnvelope.chat:type_name -> chat.v1.ChatPayload
+	13, // 5: chat.v1.ClientEnvelope.leave:type_name -> chat.v1.LeaveRequest
+	14, // 6: chat.v1.ClientEnvelope.resume:type_name -> chat.v1.ResumeRequest
+	15, // 7: chat.v1.ClientEnvelope.direct:type_name -> chat.v1.DirectMessageRequest
+	16, // 8: chat.v1.ClientEnvelope.typing:type_name -> chat.v1.TypingRequest
+	17, // 9: chat.v1.ClientEnvelope.ping:type_name -> chat.v1.PingRequest
+	8,  // 10: chat.v1.ClientEnvelope.edit:type_name -> chat.v1.EditMessageRequest
+	9,  // 11: chat.v1.ClientEnvelope.delete:type_name -> chat.v1.DeleteMessageRequest
+	10, // 12: chat.v1.ClientEnvelope.react:type_name -> chat.v1.ReactionRequest
+	10, // 13: chat.v1.ClientEnvelope.unreact:type_name -> chat.v1.ReactionRequest
+	12, // 14: chat.v1.ClientEnvelope.moderate:type_name -> chat.v1.ModerationRequest
+	11, // 15: chat.v1.ClientEnvelope.set_role:type_name -> chat.v1.SetRoleRequest
+	0,  // 16: chat.v1.JoinAck.role:type_name -> chat.v1.Role
+	19, // 17: chat.v1.ServerEvent.joined:type_name -> chat.v1.JoinAck
+	6,  // 18: chat.v1.ServerEvent.broadcast:type_name -> chat.v1.ChatPayload
+	29, // 19: chat.v1.ServerEvent.notice:type_name -> chat.v1.ServerNotice
+	20, // 20: chat.v1.ServerEvent.direct:type_name -> chat.v1.DirectMessage
+	21, // 21: chat.v1.ServerEvent.typing:type_name -> chat.v1.TypingIndicator
+	22, // 22: chat.v1.ServerEvent.pong:type_name -> chat.v1.Pong
+	23, // 23: chat.v1.ServerEvent.ack:type_name -> chat.v1.SendAck
+	24, // 24: chat.v1.ServerEvent.edited:type_name -> chat.v1.MessageEdited
+	25, // 25: chat.v1.ServerEvent.deleted:type_name -> chat.v1.MessageDeleted
+	26, // 26: chat.v1.ServerEvent.reaction:type_name -> chat.v1.ReactionDelta
+	27, // 27: chat.v1.ServerEvent.thread:type_name -> chat.v1.ThreadUpdate
+	4,  // 28: chat.v1.ServerNotice.type:type_name -> chat.v1.ServerNotice.Type
+	1,  // 29: chat.v1.ServerNotice.action:type_name -> chat.v1.ModerationAction
+	0,  // 30: chat.v1.ServerNotice.role:type_name -> chat.v1.Role
+	2,  // 31: chat.v1.RoomSummary.visibility:type_name -> chat.v1.Visibility
+	3,  // 32: chat.v1.RoomSummary.access:type_name -> chat.v1.AccessPolicy
+	2,  // 33: chat.v1.CreateRoomRequest.visibility:type_name -> chat.v1.Visibility
+	3,  // 34: chat.v1.CreateRoomRequest.access:type_name -> chat.v1.AccessPolicy
+	31, // 35: chat.v1.CreateRoomResponse.room:type_name -> chat.v1.RoomSummary
+	2,  // 36: chat.v1.UpdateRoomRequest.visibility:type_name -> chat.v1.Visibility
+	3,  // 37: chat.v1.UpdateRoomRequest.access:type_name -> chat.v1.AccessPolicy
+	31, // 38: chat.v1.UpdateRoomResponse.room:type_name -> chat.v1.RoomSummary
+	31, // 39: chat.v1.ArchiveRoomResponse.room:type_name -> chat.v1.RoomSummary
+	38, // 40: chat.v1.CreateInviteResponse.invite:type_name -> chat.v1.Invite
+	31, // 41: chat.v1.ListRoomsResponse.rooms:type_name -> chat.v1.RoomSummary
+	0,  // 42: chat.v1.Participant.role:type_name -> chat.v1.Role
+	31, // 43: chat.v1.GetRoomResponse.room:type_name -> chat.v1.RoomSummary
+	45, // 44: chat.v1.GetRoomResponse.participants:type_name -> chat.v1.Participant
+	6,  // 45: chat.v1.GetThreadResponse.root:type_name -> chat.v1.ChatPayload
+	6,  // 46: chat.v1.GetThreadResponse.replies:type_name -> chat.v1.ChatPayload
+	18, // 47: chat.v1.ChatService.Channel:input_type -> chat.v1.ClientEnvelope
+	30, // 48: chat.v1.ChatService.ListRooms:input_type -> chat.v1.ListRoomsRequest
+	44, // 49: chat.v1.ChatService.GetRoom:input_type -> chat.v1.GetRoomRequest
+	47, // 50: chat.v1.ChatService.GetThread:input_type -> chat.v1.GetThreadRequest
+	32, // 51: chat.v1.ChatService.CreateRoom:input_type -> chat.v1.CreateRoomRequest
+	34, // 52: chat.v1.ChatService.UpdateRoom:input_type -> chat.v1.UpdateRoomRequest
+	36, // 53: chat.v1.ChatService.ArchiveRoom:input_type -> chat.v1.ArchiveRoomRequest
+	39, // 54: chat.v1.ChatService.CreateInvite:input_type -> chat.v1.CreateInviteRequest
+	41, // 55: chat.v1.ChatService.RevokeInvite:input_type -> chat.v1.RevokeInviteRequest
+	28, // 56: chat.v1.ChatService.Channel:output_type -> chat.v1.ServerEvent
+	43, // 57: chat.v1.ChatService.ListRooms:output_type -> chat.v1.ListRoomsResponse
+	46, // 58: chat.v1.ChatService.GetRoom:output_type -> chat.v1.GetRoomResponse
+	48, // 59: chat.v1.ChatService.GetThread:output_type -> chat.v1.GetThreadResponse
+	33, // 60: chat.v1.ChatService.CreateRoom:output_type -> chat.v1.CreateRoomResponse
+	35, // 61: chat.v1.ChatService.UpdateRoom:output_type -> chat.v1.UpdateRoomResponse
+	37, // 62: chat.v1.ChatService.ArchiveRoom:output_type -> chat.v1.ArchiveRoomResponse
+	40, // 63: chat.v1.ChatService.CreateInvite:output_type -> chat.v1.CreateInviteResponse
+	42, // 64: chat.v1.ChatService.RevokeInvite:output_type -> chat.v1.RevokeInviteResponse
+	56, // [56:65] is the sub-list for method output_type
+	47, // [47:56] is the sub-list for method input_type
+	47, // [47:47] is the sub-list for extension type_name
+	47, // [47:47] is the sub-list for extension extendee
+	0,  // [0:47] is the sub-list for field type_name
 }
 
 func init() { file_chat_proto_init() }
@@ -676,23 +4523,50 @@ func file_chat_proto_init() {
 	if File_chat_proto != nil {
 		return
 	}
-	file_chat_proto_msgTypes[3].OneofWrappers = []any{
+	file_chat_proto_msgTypes[1].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[3].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[4].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[5].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[6].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[7].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[8].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[11].OneofWrappers = []any{}
+	file_chat_proto_msgTypes[13].OneofWrappers = []any{
 		(*ClientEnvelope_Join)(nil),
 		(*ClientEnvelope_Chat)(nil),
 		(*ClientEnvelope_Leave)(nil),
+		(*ClientEnvelope_Resume)(nil),
+		(*ClientEnvelope_Direct)(nil),
+		(*ClientEnvelope_Typing)(nil),
+		(*ClientEnvelope_Ping)(nil),
+		(*ClientEnvelope_Edit)(nil),
+		(*ClientEnvelope_Delete)(nil),
+		(*ClientEnvelope_React)(nil),
+		(*ClientEnvelope_Unreact)(nil),
+		(*ClientEnvelope_Moderate)(nil),
+		(*ClientEnvelope_SetRole)(nil),
 	}
-	file_chat_proto_msgTypes[5].OneofWrappers = []any{
+	file_chat_proto_msgTypes[23].OneofWrappers = []any{
 		(*ServerEvent_Joined)(nil),
 		(*ServerEvent_Broadcast)(nil),
 		(*ServerEvent_Notice)(nil),
+		(*ServerEvent_Direct)(nil),
+		(*ServerEvent_Typing)(nil),
+		(*ServerEvent_Pong)(nil),
+		(*ServerEvent_Ack)(nil),
+		(*ServerEvent_Edited)(nil),
+		(*ServerEvent_Deleted)(nil),
+		(*ServerEvent_Reaction)(nil),
+		(*ServerEvent_Thread)(nil),
 	}
+	file_chat_proto_msgTypes[29].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chat_proto_rawDesc), len(file_chat_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   7,
+			NumEnums:      5,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
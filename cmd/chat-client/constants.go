@@ -1,33 +1,127 @@
 package main
 
+import "time"
+
 const (
 	envHostKey = "CHAT_GRPC_HOST"
 	envPortKey = "CHAT_GRPC_PORT"
+	// envAPIKeyKey and envTokenKey hold the credentials sent to servers that require them.
+	envAPIKeyKey = "CHAT_GRPC_API_KEY"
+	envTokenKey  = "CHAT_GRPC_TOKEN"
+	// envRoomPasswordKey and envInviteTokenKey are sent on join to enter rooms that are not
+	// public.
+	envRoomPasswordKey = "CHAT_GRPC_ROOM_PASSWORD"
+	envInviteTokenKey  = "CHAT_GRPC_INVITE_TOKEN"
+	// envWaitForSeatKey set to true queues for a seat when the room is full.
+	envWaitForSeatKey = "CHAT_GRPC_WAIT_FOR_SEAT"
+
+	// TLS settings; the flags of the same name override them.
+	envTLSKey           = "CHAT_GRPC_CLIENT_TLS"
+	envTLSCAFileKey     = "CHAT_GRPC_CLIENT_TLS_CA_FILE"
+	envTLSCertFileKey   = "CHAT_GRPC_CLIENT_TLS_CERT_FILE"
+	envTLSKeyFileKey    = "CHAT_GRPC_CLIENT_TLS_KEY_FILE"
+	envTLSServerNameKey = "CHAT_GRPC_CLIENT_TLS_SERVER_NAME"
+
+	flagTLS           = "tls"
+	flagTLSCAFile     = "tls-ca"
+	flagTLSCertFile   = "tls-cert"
+	flagTLSKeyFile    = "tls-key"
+	flagTLSServerName = "tls-server-name"
+
+	usageTLS           = "conecta usando TLS (implícito quando -tls-ca ou -tls-cert são informados)"
+	usageTLSCAFile     = "CA que valida o certificado do servidor (padrão: CAs do sistema)"
+	usageTLSCertFile   = "certificado do cliente para mTLS"
+	usageTLSKeyFile    = "chave privada do certificado do cliente"
+	usageTLSServerName = "nome esperado no certificado do servidor"
+
+	metadataAPIKey        = "x-api-key"
+	metadataAuthorization = "authorization"
+	bearerPrefix          = "Bearer "
 
 	defaultHost = "127.0.0.1"
 	defaultPort = "50051"
 	defaultRoom = "general"
 
+	historyLimit = 20
+	// pingInterval keeps quiet sessions well within the server's idle timeout.
+	pingInterval = time.Minute
+	// requestIDFormat builds request IDs from the run's start time and a counter.
+	requestIDFormat = "%x-%d"
+
 	promptDisplayName = "Qual nome você quer usar? "
 	promptRoom        = "Sala (deixe em branco para general): "
 	promptInput       = "> "
+	promptTypingOne   = "(%s está digitando…) "
+	promptTypingMany  = "(%s estão digitando…) "
+	// clearLine returns the cursor to the start of the line and erases it.
+	clearLine = "\r\033[K"
 
-	messageConnected        = "✅ Conectado à sala %q como %s"
-	messagePromptCommands   = "Digite mensagens e pressione Enter. Use !quit para sair."
-	messageServerClosed     = "⚠️ Conexão encerrada pelo servidor."
-	messageReceiveError     = "⚠️ Erro ao receber mensagens: %v\n"
-	messageSendError        = "⚠️ Erro ao enviar mensagem: %v\n"
-	messageLeaveError       = "⚠️ Erro ao sair da sala: %v\n"
-	messageInvalidJoinAck   = "⚠️ Resposta inesperada do servidor, encerrando..."
-	messageLeaving          = "Saindo da sala..."
-	messageDisconnected     = "👋 Até logo!"
-	messageNoticeUserJoined = "👤 %s entrou na sala"
-	messageNoticeUserLeft   = "👤 %s saiu da sala"
-	messageNoticeGeneric    = "💬 %s"
-	messageIncomingChat     = "[%s] %s: %s"
-	messageSystemError      = "❗ %s"
-	messageUnknownEvent     = "❗ Evento desconhecido recebido"
+	messageConnected         = "✅ Conectado à sala %q como %s"
+	messagePromptCommands    = "Digite mensagens e pressione Enter. Use !dm <usuário> <mensagem> para mensagens diretas, !edit <texto> e !del para corrigir ou apagar sua última mensagem, !react <emoji> e !unreact <emoji> para reagir à última mensagem da sala, !reply <texto> para respondê-la em uma conversa, !topic <texto> para mudar o tópico da sala, !archive para arquivá-la, !invite [horas] [usos] para convidar alguém a ela e !quit para sair."
+	messageServerClosed      = "⚠️ Conexão encerrada pelo servidor."
+	messageReceiveError      = "⚠️ Erro ao receber mensagens: %v\n"
+	messageSendError         = "⚠️ Erro ao enviar mensagem: %v\n"
+	messageSendRejected      = "⚠️ Mensagem rejeitada: %s"
+	messageLeaveError        = "⚠️ Erro ao sair da sala: %v\n"
+	messageInvalidJoinAck    = "⚠️ Resposta inesperada do servidor, encerrando..."
+	messageJoinRejected      = "⚠️ Entrada recusada: %s"
+	messageQueued            = "⏳ Sala cheia; você é o %dº da fila"
+	messageLeaving           = "Saindo da sala..."
+	messageDisconnected      = "👋 Até logo!"
+	messageNoticeUserJoined  = "👤 %s entrou na sala"
+	messageNoticeUserLeft    = "👤 %s saiu da sala"
+	messageNoticeUserRemoved = "👤 %s foi removido da sala: %s"
+	messageNoticeGeneric     = "💬 %s"
+	messageIncomingChat      = "[%s] %s: %s"
+	messageReplayedChat      = "↺ [%s] %s: %s"
+	messageIncomingDirect    = "✉️ [%s] %s (direta): %s"
+	messageQueuedDirect      = "✉️ ↺ [%s] %s (direta): %s"
+	messageDirectUsage       = "Uso: !dm <usuário> <mensagem>"
+	messageNothingToChange   = "Nenhuma mensagem sua para editar ou apagar ainda."
+	messageEditedChat        = "✏️ [%s] %s editou uma mensagem: %s"
+	messageDeletedChat       = "🗑️ [%s] %s apagou uma mensagem"
+	messageEditedSuffix      = " (editada)"
+	messageDeletedContent    = "(mensagem apagada)"
+	messageNothingToReact    = "Nenhuma mensagem para reagir ainda."
+	messageReactionAdded     = "%s reagiu com %s (%d)"
+	messageReactionRemoved   = "%s removeu a reação %s (%d)"
+	messageReactionSuffix    = " [%s %d]"
+	messageNothingToReply    = "Nenhuma mensagem para responder ainda."
+	messageReplyPrefix       = "  ↳ "
+	messageRepliesSuffix     = " (%d respostas)"
+	messageThreadUpdated     = "🧵 %s respondeu em uma conversa (%d respostas)"
+	messageRoleUsage         = "Uso: !role <usuário> <dono|moderador|membro|leitura>"
+	messageModerationUsage   = "Uso: !kick <usuário> [motivo], !ban|!mute <usuário> <minutos> [motivo], !unban|!unmute <usuário>"
+	messageRoomUpdateError   = "⚠️ Erro ao alterar a sala: %v\n"
+	messageInviteCreated     = "🔑 Convite: %s (expira: %s, usos: %s)"
+	messageInviteUsage       = "Uso: !invite [horas] [usos]"
+	messageInviteNever       = "nunca"
+	messageInviteUnlimited   = "ilimitados"
+	messageSystemError       = "❗ %s"
+	messageUnknownEvent      = "❗ Evento desconhecido recebido"
 
 	timeDisplayFormat = "15:04:05"
 	commandQuit       = "!quit"
+	commandDirect     = "!dm"
+	commandEdit       = "!edit"
+	commandDelete     = "!del"
+	commandReact      = "!react"
+	commandUnreact    = "!unreact"
+	commandReply      = "!reply"
+	commandRole       = "!role"
+	commandKick       = "!kick"
+	commandBan        = "!ban"
+	commandUnban      = "!unban"
+	commandMute       = "!mute"
+	commandUnmute     = "!unmute"
+	commandTopic      = "!topic"
+	commandArchive    = "!archive"
+	commandInvite     = "!invite"
+)
+
+const (
+	roleNameOwner     = "dono"
+	roleNameModerator = "moderador"
+	roleNameMember    = "membro"
+	roleNameReadOnly  = "leitura"
 )
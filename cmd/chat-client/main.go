@@ -4,17 +4,23 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 
 	chatv1 "github.com/lechitz/chat-grpc/api/proto/chatv1"
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 func main() {
@@ -25,6 +31,13 @@ func run() int {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	tlsFiles, useTLS := parseTLSFlags()
+	transportCreds, err := dialCredentials(tlsFiles, useTLS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração TLS inválida: %v\n", err)
+		return 1
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	displayName, err := prompt(reader, promptDisplayName)
 	if err != nil {
@@ -49,7 +62,7 @@ func run() int {
 	userID := buildUserID(displayName)
 	target := fmt.Sprintf("%s:%s", getenv(envHostKey, defaultHost), getenv(envPortKey, defaultPort))
 
-	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(transportCreds), grpc.WithBlock())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "falha ao conectar a %s: %v\n", target, err)
 		return 2
@@ -57,7 +70,7 @@ func run() int {
 	defer conn.Close()
 
 	client := chatv1.NewChatServiceClient(conn)
-	stream, err := client.Channel(ctx)
+	stream, err := client.Channel(withCredentials(ctx))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "falha ao abrir stream: %v\n", err)
 		return 2
@@ -66,9 +79,13 @@ func run() int {
 	if err := stream.Send(&chatv1.ClientEnvelope{
 		Message: &chatv1.ClientEnvelope_Join{
 			Join: &chatv1.JoinRequest{
-				UserId:      userID,
-				DisplayName: displayName,
-				Room:        room,
+				UserId:       userID,
+				DisplayName:  displayName,
+				Room:         room,
+				HistoryLimit: historyLimit,
+				Password:     getenv(envRoomPasswordKey, ""),
+				InviteToken:  getenv(envInviteTokenKey, ""),
+				WaitForSeat:  getenv(envWaitForSeatKey, "") == "true",
 			},
 		},
 	}); err != nil {
@@ -77,6 +94,11 @@ func run() int {
 	}
 
 	firstEvent, err := stream.Recv()
+	// A join waiting for a seat hears its place in line until it is acknowledged.
+	for err == nil && firstEvent.GetNotice().GetType() == chatv1.ServerNotice_TYPE_QUEUE_POSITION {
+		fmt.Printf(messageQueued+"\n", firstEvent.GetNotice().GetQueuePosition())
+		firstEvent, err = stream.Recv()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "falha ao receber ack: %v\n", err)
 		return 2
@@ -84,11 +106,30 @@ func run() int {
 	if ack := firstEvent.GetJoined(); ack != nil {
 		fmt.Printf(messageConnected+"\n", ack.GetRoom(), displayName)
 		fmt.Println(messagePromptCommands)
+	} else if notice := firstEvent.GetNotice(); notice.GetType() == chatv1.ServerNotice_TYPE_ERROR {
+		fmt.Printf(messageJoinRejected+"\n", notice.GetMessage())
+		return 2
 	} else {
 		fmt.Println(messageInvalidJoinAck)
 		return 2
 	}
 
+	typers := newTypingUsers()
+	// lastSent is the ID of the user's latest acknowledged message, the target of !edit and !del.
+	var lastSent atomic.Pointer[string]
+	// lastSeen is the ID of the room's latest message, the target of !react, !unreact and
+	// !reply.
+	var lastSeen atomic.Pointer[string]
+
+	// The prompt loop and the heartbeat both send on the stream, which is not safe for
+	// concurrent senders.
+	var sendMu sync.Mutex
+	send := func(env *chatv1.ClientEnvelope) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(env)
+	}
+
 	var wg sync.WaitGroup
 	recvDone := make(chan struct{})
 	wg.Add(1)
@@ -105,13 +146,60 @@ func run() int {
 				}
 				return
 			}
+			if event.GetPong() != nil {
+				continue
+			}
+			if ack := event.GetAck(); ack != nil {
+				// Accepted messages show up as broadcasts; only rejections are worth printing.
+				if ack.GetErrorCode() != 0 {
+					fmt.Printf("\n"+messageSendRejected+"\n", ack.GetError())
+					fmt.Print(typers.prompt())
+					continue
+				}
+				id := ack.GetMessageId()
+				lastSent.Store(&id)
+				continue
+			}
+			if indicator := event.GetTyping(); indicator != nil {
+				// Typing signals only redraw the prompt line instead of printing a new one.
+				typers.set(indicator.GetUserId(), indicator.GetDisplayName(), indicator.GetTyping())
+				fmt.Print(clearLine + typers.prompt())
+				continue
+			}
+			if broadcast := event.GetBroadcast(); broadcast != nil {
+				typers.set(broadcast.GetUserId(), "", false)
+				if id := broadcast.GetMessageId(); id != "" && !broadcast.GetDeleted() {
+					lastSeen.Store(&id)
+				}
+			}
 			renderEvent(event)
-			fmt.Print(promptInput)
+			fmt.Print(typers.prompt())
+		}
+	}()
+
+	// Request IDs are unique per run, so a restarted client never matches a previous run's
+	// requests in the server's de-duplication window.
+	runID := time.Now().UnixNano()
+	var requests uint64
+
+	// Pings keep the session from being evicted as idle while the user only reads.
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for nonce := uint64(1); ; nonce++ {
+			select {
+			case <-ticker.C:
+				_ = send(&chatv1.ClientEnvelope{
+					Message: &chatv1.ClientEnvelope_Ping{Ping: &chatv1.PingRequest{Nonce: nonce}},
+				})
+			case <-recvDone:
+				return
+			}
 		}
 	}()
 
 	for {
-		fmt.Print(promptInput)
+		fmt.Print(typers.prompt())
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if errors.Is(err, io.EOF) {
@@ -131,38 +219,252 @@ func run() int {
 		}
 		if line == commandQuit {
 			fmt.Println(messageLeaving)
-			if err := stream.Send(&chatv1.ClientEnvelope{
+			if err := send(&chatv1.ClientEnvelope{
 				Message: &chatv1.ClientEnvelope_Leave{
-					Leave: &chatv1.LeaveRequest{
-						UserId: userID,
-						Room:   room,
-					},
+					Leave: &chatv1.LeaveRequest{},
 				},
 			}); err != nil {
 				fmt.Printf(messageLeaveError, err)
 			}
+			sendMu.Lock()
 			_ = stream.CloseSend()
+			sendMu.Unlock()
 			<-recvDone
 			wg.Wait()
 			fmt.Println(messageDisconnected)
 			return 0
 		}
 
-		if err := stream.Send(&chatv1.ClientEnvelope{
-			Message: &chatv1.ClientEnvelope_Chat{
-				Chat: &chatv1.ChatPayload{
-					UserId:       userID,
-					Room:         room,
-					Content:      line,
-					TimestampUtc: time.Now().UTC().UnixMilli(),
+		if line == commandDelete || strings.HasPrefix(line, commandEdit+" ") {
+			last := lastSent.Load()
+			if last == nil {
+				fmt.Println(messageNothingToChange)
+				continue
+			}
+			env := &chatv1.ClientEnvelope{
+				Message: &chatv1.ClientEnvelope_Delete{Delete: &chatv1.DeleteMessageRequest{MessageId: *last}},
+			}
+			if content, ok := strings.CutPrefix(line, commandEdit+" "); ok {
+				env.Message = &chatv1.ClientEnvelope_Edit{
+					Edit: &chatv1.EditMessageRequest{MessageId: *last, Content: strings.TrimSpace(content)},
+				}
+			}
+			if err := send(env); err != nil {
+				fmt.Printf(messageSendError, err)
+			}
+			continue
+		}
+
+		if emoji, ok := strings.CutPrefix(line, commandReact+" "); ok || strings.HasPrefix(line, commandUnreact+" ") {
+			last := lastSeen.Load()
+			if last == nil {
+				fmt.Println(messageNothingToReact)
+				continue
+			}
+			req := &chatv1.ReactionRequest{MessageId: *last, Emoji: strings.TrimSpace(emoji)}
+			env := &chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_React{React: req}}
+			if !ok {
+				req.Emoji = strings.TrimSpace(strings.TrimPrefix(line, commandUnreact+" "))
+				env.Message = &chatv1.ClientEnvelope_Unreact{Unreact: req}
+			}
+			if err := send(env); err != nil {
+				fmt.Printf(messageSendError, err)
+			}
+			continue
+		}
+
+		if req, ok := moderationRequest(line); ok {
+			if req == nil {
+				fmt.Println(messageModerationUsage)
+				continue
+			}
+			if err := send(&chatv1.ClientEnvelope{
+				Message: &chatv1.ClientEnvelope_Moderate{Moderate: req},
+			}); err != nil {
+				fmt.Printf(messageSendError, err)
+			}
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, commandRole+" "); ok {
+			userID, name, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			role, known := roleNames[strings.TrimSpace(name)]
+			if userID == "" || !known {
+				fmt.Println(messageRoleUsage)
+				continue
+			}
+			if err := send(&chatv1.ClientEnvelope{
+				Message: &chatv1.ClientEnvelope_SetRole{SetRole: &chatv1.SetRoleRequest{UserId: userID, Role: role}},
+			}); err != nil {
+				fmt.Printf(messageSendError, err)
+			}
+			continue
+		}
+
+		// Room settings are changed through unary calls rather than the stream; the
+		// outcome reaches the room, this client included, as a notice.
+		if topic, ok := strings.CutPrefix(line, commandTopic+" "); ok || line == commandArchive {
+			var err error
+			if ok {
+				topic = strings.TrimSpace(topic)
+				_, err = client.UpdateRoom(withCredentials(ctx), &chatv1.UpdateRoomRequest{Room: room, UserId: userID, Topic: &topic})
+			} else {
+				_, err = client.ArchiveRoom(withCredentials(ctx), &chatv1.ArchiveRoomRequest{Room: room, UserId: userID})
+			}
+			if err != nil {
+				fmt.Printf(messageRoomUpdateError, err)
+			}
+			continue
+		}
+
+		if line == commandInvite || strings.HasPrefix(line, commandInvite+" ") {
+			req, ok := inviteRequest(line)
+			if !ok {
+				fmt.Println(messageInviteUsage)
+				continue
+			}
+			req.Room, req.UserId = room, userID
+			resp, err := client.CreateInvite(withCredentials(ctx), req)
+			if err != nil {
+				fmt.Printf(messageRoomUpdateError, err)
+				continue
+			}
+			printInvite(resp.GetInvite())
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, commandDirect+" "); ok {
+			to, content, _ := strings.Cut(strings.TrimSpace(rest), " ")
+			if to == "" || strings.TrimSpace(content) == "" {
+				fmt.Println(messageDirectUsage)
+				continue
+			}
+			if err := send(&chatv1.ClientEnvelope{
+				Message: &chatv1.ClientEnvelope_Direct{
+					Direct: &chatv1.DirectMessageRequest{
+						ToUserId: to,
+						Content:  strings.TrimSpace(content),
+					},
 				},
-			},
+			}); err != nil {
+				fmt.Printf(messageSendError, err)
+			}
+			continue
+		}
+
+		payload := &chatv1.ChatPayload{
+			Content:      line,
+			TimestampUtc: time.Now().UTC().UnixMilli(),
+		}
+		if content, ok := strings.CutPrefix(line, commandReply+" "); ok {
+			last := lastSeen.Load()
+			if last == nil {
+				fmt.Println(messageNothingToReply)
+				continue
+			}
+			payload.Content = strings.TrimSpace(content)
+			payload.ReplyTo = *last
+		}
+
+		requests++
+		if err := send(&chatv1.ClientEnvelope{
+			RequestId: fmt.Sprintf(requestIDFormat, runID, requests),
+			Message:   &chatv1.ClientEnvelope_Chat{Chat: payload},
 		}); err != nil {
 			fmt.Printf(messageSendError, err)
 		}
 	}
 }
 
+// moderationActions maps the moderation commands to their action and whether they take a
+// duration in minutes.
+var moderationActions = map[string]struct {
+	action   chatv1.ModerationAction
+	duration bool
+}{
+	commandKick:   {chatv1.ModerationAction_MODERATION_ACTION_KICK, false},
+	commandBan:    {chatv1.ModerationAction_MODERATION_ACTION_BAN, true},
+	commandUnban:  {chatv1.ModerationAction_MODERATION_ACTION_UNBAN, false},
+	commandMute:   {chatv1.ModerationAction_MODERATION_ACTION_MUTE, true},
+	commandUnmute: {chatv1.ModerationAction_MODERATION_ACTION_UNMUTE, false},
+}
+
+// roleNames maps the role names accepted by !role to their roles.
+var roleNames = map[string]chatv1.Role{
+	roleNameOwner:     chatv1.Role_ROLE_OWNER,
+	roleNameModerator: chatv1.Role_ROLE_MODERATOR,
+	roleNameMember:    chatv1.Role_ROLE_MEMBER,
+	roleNameReadOnly:  chatv1.Role_ROLE_READ_ONLY,
+}
+
+// moderationRequest parses a moderation command such as "!ban bob 10 spam". It reports
+// false for other lines, and a nil request for malformed commands.
+func moderationRequest(line string) (*chatv1.ModerationRequest, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	cmd, ok := moderationActions[fields[0]]
+	if !ok {
+		return nil, false
+	}
+	if len(fields) < 2 {
+		return nil, true
+	}
+
+	req := &chatv1.ModerationRequest{UserId: fields[1], Action: cmd.action}
+	rest := fields[2:]
+	if cmd.duration {
+		if len(rest) == 0 {
+			return nil, true
+		}
+		minutes, err := strconv.ParseUint(rest[0], 10, 32)
+		if err != nil || minutes == 0 {
+			return nil, true
+		}
+		req.DurationSeconds = uint32(minutes * 60)
+		rest = rest[1:]
+	}
+	req.Reason = strings.Join(rest, " ")
+	return req, true
+}
+
+// inviteRequest parses "!invite [hours] [uses]"; omitted values issue an invite that never
+// expires or has unlimited uses.
+func inviteRequest(line string) (*chatv1.CreateInviteRequest, bool) {
+	fields := strings.Fields(line)[1:]
+	if len(fields) > 2 {
+		return nil, false
+	}
+	req := &chatv1.CreateInviteRequest{}
+	if len(fields) > 0 {
+		hours, err := strconv.Atoi(fields[0])
+		if err != nil || hours < 0 {
+			return nil, false
+		}
+		req.TtlSeconds = int64(time.Duration(hours) * time.Hour / time.Second)
+	}
+	if len(fields) > 1 {
+		uses, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return nil, false
+		}
+		req.MaxUses = uint32(uses)
+	}
+	return req, true
+}
+
+func printInvite(invite *chatv1.Invite) {
+	expires, uses := messageInviteNever, messageInviteUnlimited
+	if at := invite.GetExpiresAtUtc(); at != 0 {
+		expires = time.UnixMilli(at).Local().Format(time.DateTime)
+	}
+	if limit := invite.GetMaxUses(); limit != 0 {
+		uses = strconv.FormatUint(uint64(limit), 10)
+	}
+	fmt.Printf(messageInviteCreated+"\n", invite.GetToken(), expires, uses)
+}
+
 func prompt(reader *bufio.Reader, question string) (string, error) {
 	fmt.Print(question)
 	text, err := reader.ReadString('\n')
@@ -225,14 +527,108 @@ func renderEvent(event *chatv1.ServerEvent) {
 		if tsVal != 0 {
 			timestamp = time.UnixMilli(tsVal)
 		}
-		fmt.Printf(messageIncomingChat+"\n", timestamp.Format(timeDisplayFormat), payload.Broadcast.GetUserId(), strings.ToValidUTF8(payload.Broadcast.GetContent(), ""))
+		format := messageIncomingChat
+		if payload.Broadcast.GetReplayed() {
+			format = messageReplayedChat
+		}
+		if payload.Broadcast.GetReplyTo() != "" {
+			format = messageReplyPrefix + format
+		}
+		content := strings.ToValidUTF8(payload.Broadcast.GetContent(), "")
+		switch {
+		case payload.Broadcast.GetDeleted():
+			content = messageDeletedContent
+		case payload.Broadcast.GetEditedAtUtc() != 0:
+			content += messageEditedSuffix
+		}
+		for _, reaction := range payload.Broadcast.GetReactions() {
+			content += fmt.Sprintf(messageReactionSuffix, reaction.GetEmoji(), reaction.GetCount())
+		}
+		if count := payload.Broadcast.GetReplyCount(); count > 0 {
+			content += fmt.Sprintf(messageRepliesSuffix, count)
+		}
+		fmt.Printf(format+"\n", timestamp.Format(timeDisplayFormat), payload.Broadcast.GetUserId(), content)
+	case *chatv1.ServerEvent_Edited:
+		timestamp := time.UnixMilli(payload.Edited.GetEditedAtUtc())
+		fmt.Printf(messageEditedChat+"\n", timestamp.Format(timeDisplayFormat), payload.Edited.GetUserId(), strings.ToValidUTF8(payload.Edited.GetContent(), ""))
+	case *chatv1.ServerEvent_Deleted:
+		timestamp := time.UnixMilli(payload.Deleted.GetDeletedAtUtc())
+		fmt.Printf(messageDeletedChat+"\n", timestamp.Format(timeDisplayFormat), payload.Deleted.GetUserId())
+	case *chatv1.ServerEvent_Reaction:
+		format := messageReactionAdded
+		if !payload.Reaction.GetAdded() {
+			format = messageReactionRemoved
+		}
+		fmt.Printf(format+"\n", payload.Reaction.GetUserId(), payload.Reaction.GetEmoji(), payload.Reaction.GetCount())
+	case *chatv1.ServerEvent_Thread:
+		fmt.Printf(messageThreadUpdated+"\n", payload.Thread.GetUserId(), payload.Thread.GetReplyCount())
 	case *chatv1.ServerEvent_Notice:
 		renderNotice(payload.Notice)
+	case *chatv1.ServerEvent_Direct:
+		if payload.Direct == nil {
+			return
+		}
+		format := messageIncomingDirect
+		if payload.Direct.GetQueued() {
+			format = messageQueuedDirect
+		}
+		timestamp := time.UnixMilli(payload.Direct.GetTimestampUtc())
+		fmt.Printf(format+"\n", timestamp.Format(timeDisplayFormat), payload.Direct.GetFromUserId(), strings.ToValidUTF8(payload.Direct.GetContent(), ""))
 	default:
 		fmt.Println(messageUnknownEvent)
 	}
 }
 
+// typingUsers tracks who is typing in the room so the prompt can show it.
+type typingUsers struct {
+	mu    sync.Mutex
+	names map[string]string
+	order []string
+}
+
+func newTypingUsers() *typingUsers {
+	return &typingUsers{names: make(map[string]string)}
+}
+
+func (t *typingUsers) set(userID, displayName string, typing bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, known := t.names[userID]
+	switch {
+	case typing && !known:
+		t.order = append(t.order, userID)
+		t.names[userID] = displayNameFallback(displayName)
+	case !typing && known:
+		delete(t.names, userID)
+		for i, id := range t.order {
+			if id == userID {
+				t.order = append(t.order[:i], t.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// prompt returns the input prompt, preceded by who is typing.
+func (t *typingUsers) prompt() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch len(t.order) {
+	case 0:
+		return promptInput
+	case 1:
+		return fmt.Sprintf(promptTypingOne, t.names[t.order[0]]) + promptInput
+	default:
+		names := make([]string, len(t.order))
+		for i, id := range t.order {
+			names[i] = t.names[id]
+		}
+		return fmt.Sprintf(promptTypingMany, strings.Join(names, ", ")) + promptInput
+	}
+}
+
 func renderNotice(notice *chatv1.ServerNotice) {
 	if notice == nil {
 		return
@@ -241,6 +637,10 @@ func renderNotice(notice *chatv1.ServerNotice) {
 	case chatv1.ServerNotice_TYPE_USER_JOINED:
 		fmt.Printf(messageNoticeUserJoined+"\n", displayNameFallback(notice.GetUserId()))
 	case chatv1.ServerNotice_TYPE_USER_LEFT:
+		if reason := notice.GetReason(); reason != "" {
+			fmt.Printf(messageNoticeUserRemoved+"\n", displayNameFallback(notice.GetUserId()), reason)
+			return
+		}
 		fmt.Printf(messageNoticeUserLeft+"\n", displayNameFallback(notice.GetUserId()))
 	case chatv1.ServerNotice_TYPE_GENERIC:
 		fmt.Printf(messageNoticeGeneric+"\n", notice.GetMessage())
@@ -257,3 +657,39 @@ func displayNameFallback(userID string) string {
 	}
 	return userID
 }
+
+// withCredentials attaches the credentials configured in the environment to outgoing calls.
+func withCredentials(ctx context.Context) context.Context {
+	if key := getenv(envAPIKeyKey, ""); key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataAPIKey, key)
+	}
+	if token := getenv(envTokenKey, ""); token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataAuthorization, bearerPrefix+token)
+	}
+	return ctx
+}
+
+// parseTLSFlags reads the TLS flags, defaulting each one to its environment variable.
+func parseTLSFlags() (tlsconfig.ClientFiles, bool) {
+	var files tlsconfig.ClientFiles
+	useTLS := flag.Bool(flagTLS, getenv(envTLSKey, "") == "true", usageTLS)
+	flag.StringVar(&files.CAFile, flagTLSCAFile, getenv(envTLSCAFileKey, ""), usageTLSCAFile)
+	flag.StringVar(&files.CertFile, flagTLSCertFile, getenv(envTLSCertFileKey, ""), usageTLSCertFile)
+	flag.StringVar(&files.KeyFile, flagTLSKeyFile, getenv(envTLSKeyFileKey, ""), usageTLSKeyFile)
+	flag.StringVar(&files.ServerName, flagTLSServerName, getenv(envTLSServerNameKey, ""), usageTLSServerName)
+	flag.Parse()
+
+	return files, *useTLS || files.CAFile != "" || files.CertFile != ""
+}
+
+// dialCredentials returns TLS credentials when enabled and plaintext ones otherwise.
+func dialCredentials(files tlsconfig.ClientFiles, useTLS bool) (credentials.TransportCredentials, error) {
+	if !useTLS {
+		return insecure.NewCredentials(), nil
+	}
+	cfg, err := tlsconfig.ClientConfig(files)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
@@ -2,21 +2,40 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	chatv1 "github.com/lechitz/chat-grpc/api/proto/chatv1"
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// userPlaceholder in the certificate and key paths is replaced by each client's user, so
+// every client can present its own certificate to a server requiring mutual TLS.
+const userPlaceholder = "{user}"
+
 func main() {
 	host := getenv("CHAT_GRPC_HOST", "127.0.0.1")
 	port := getenv("CHAT_GRPC_PORT", "50051")
 	target := fmt.Sprintf("%s:%s", host, port)
 
+	var files tlsconfig.ClientFiles
+	useTLS := flag.Bool("tls", getenv("CHAT_GRPC_CLIENT_TLS", "") == "true", "dial with TLS (implied by -tls-ca and -tls-cert)")
+	flag.StringVar(&files.CAFile, "tls-ca", getenv("CHAT_GRPC_CLIENT_TLS_CA_FILE", ""), "CA verifying the server certificate (default: system roots)")
+	flag.StringVar(&files.CertFile, "tls-cert", getenv("CHAT_GRPC_CLIENT_TLS_CERT_FILE", ""), "client certificate for mutual TLS; "+userPlaceholder+" is replaced by the user")
+	flag.StringVar(&files.KeyFile, "tls-key", getenv("CHAT_GRPC_CLIENT_TLS_KEY_FILE", ""), "client certificate key; "+userPlaceholder+" is replaced by the user")
+	flag.StringVar(&files.ServerName, "tls-server-name", getenv("CHAT_GRPC_CLIENT_TLS_SERVER_NAME", ""), "name expected in the server certificate")
+	flag.Parse()
+	if files.CAFile != "" || files.CertFile != "" {
+		*useTLS = true
+	}
+
 	names := []string{"alice", "bob", "carol"}
 	var wg sync.WaitGroup
 	wg.Add(len(names))
@@ -24,20 +43,25 @@ func main() {
 	for i, name := range names {
 		go func(idx int, user string) {
 			defer wg.Done()
-			runClient(target, user, "general", fmt.Sprintf("hello from %s", user), time.Duration(500*idx)*time.Millisecond)
+			creds, err := dialCredentials(files, user, *useTLS)
+			if err != nil {
+				fmt.Printf("%s: invalid tls settings: %v\n", user, err)
+				return
+			}
+			runClient(target, creds, user, "general", fmt.Sprintf("hello from %s", user), time.Duration(500*idx)*time.Millisecond)
 		}(i, name)
 	}
 
 	wg.Wait()
 }
 
-func runClient(target, name, room, message string, startDelay time.Duration) {
+func runClient(target string, creds credentials.TransportCredentials, name, room, message string, startDelay time.Duration) {
 	ctx := context.Background()
 	if startDelay > 0 {
 		time.Sleep(startDelay)
 	}
 
-	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(creds), grpc.WithBlock())
 	if err != nil {
 		fmt.Printf("%s: failed to dial: %v\n", name, err)
 		return
@@ -80,7 +104,7 @@ func runClient(target, name, room, message string, startDelay time.Duration) {
 				return
 			}
 			if b := evt.GetBroadcast(); b != nil {
-				fmt.Printf("%s received broadcast: %s: %s\n", name, b.UserId, b.Content)
+				fmt.Printf("%s received broadcast: %s: %s\n", name, b.GetUserId(), b.Content)
 			} else if n := evt.GetNotice(); n != nil {
 				fmt.Printf("%s received notice: %s\n", name, n.Message)
 			}
@@ -91,7 +115,7 @@ func runClient(target, name, room, message string, startDelay time.Duration) {
 	time.Sleep(500 * time.Millisecond)
 
 	// send chat
-	if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{UserId: name, Room: room, Content: message, TimestampUtc: time.Now().UTC().UnixMilli()}}}); err != nil {
+	if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: message, TimestampUtc: time.Now().UTC().UnixMilli()}}}); err != nil {
 		fmt.Printf("%s: send chat error: %v\n", name, err)
 	}
 
@@ -99,7 +123,7 @@ func runClient(target, name, room, message string, startDelay time.Duration) {
 	time.Sleep(800 * time.Millisecond)
 
 	// leave
-	if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{UserId: name, Room: room}}}); err != nil {
+	if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{}}}); err != nil {
 		fmt.Printf("%s: send leave error: %v\n", name, err)
 	}
 
@@ -109,6 +133,20 @@ func runClient(target, name, room, message string, startDelay time.Duration) {
 	fmt.Printf("%s: finished\n", name)
 }
 
+// dialCredentials returns the transport credentials of the given user's client.
+func dialCredentials(files tlsconfig.ClientFiles, user string, useTLS bool) (credentials.TransportCredentials, error) {
+	if !useTLS {
+		return insecure.NewCredentials(), nil
+	}
+	files.CertFile = strings.ReplaceAll(files.CertFile, userPlaceholder, user)
+	files.KeyFile = strings.ReplaceAll(files.KeyFile, userPlaceholder, user)
+	cfg, err := tlsconfig.ClientConfig(files)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
 func getenv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,91 @@
+// Package apikey implements the Authenticator port with static API keys loaded from a file.
+//
+// The file holds one "<user_id> <api_key>" pair per line. Blank lines and lines starting
+// with '#' are ignored.
+package apikey
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+)
+
+const (
+	commentPrefix = "#"
+
+	errFmtOpenFile    = "open api key file: %w"
+	errFmtReadFile    = "read api key file: %w"
+	errFmtInvalidLine = "api key file line %d: expected \"<user_id> <api_key>\""
+	errFmtDuplicate   = "api key file line %d: duplicate api key"
+)
+
+var (
+	// ErrNoKeys is returned when the file does not declare any key.
+	ErrNoKeys = errors.New("apikey: file declares no api keys")
+
+	errMissingKey = fmt.Errorf("%w: missing api key", auth.ErrUnauthenticated)
+	errUnknownKey = fmt.Errorf("%w: unknown api key", auth.ErrUnauthenticated)
+)
+
+// Authenticator resolves API keys to the users they were issued to.
+type Authenticator struct {
+	// users is indexed by the SHA-256 digest of each key, so lookups do not compare the
+	// secret itself byte by byte.
+	users map[[sha256.Size]byte]string
+}
+
+var _ auth.Authenticator = (*Authenticator)(nil)
+
+// Load reads the keys declared in the file at path.
+func Load(path string) (*Authenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtOpenFile, err)
+	}
+	defer file.Close()
+
+	a := &Authenticator{users: make(map[[sha256.Size]byte]string)}
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, commentPrefix) {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf(errFmtInvalidLine, line)
+		}
+		digest := sha256.Sum256([]byte(fields[1]))
+		if _, exists := a.users[digest]; exists {
+			return nil, fmt.Errorf(errFmtDuplicate, line)
+		}
+		a.users[digest] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(errFmtReadFile, err)
+	}
+	if len(a.users) == 0 {
+		return nil, ErrNoKeys
+	}
+	return a, nil
+}
+
+// Authenticate accepts credentials carrying a known API key.
+func (a *Authenticator) Authenticate(_ context.Context, creds auth.Credentials) (auth.Principal, error) {
+	if creds.APIKey == "" {
+		return auth.Principal{}, errMissingKey
+	}
+	userID, ok := a.users[sha256.Sum256([]byte(creds.APIKey))]
+	if !ok {
+		return auth.Principal{}, errUnknownKey
+	}
+	return auth.Principal{UserID: userID}, nil
+}
@@ -0,0 +1,52 @@
+package apikey
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeys(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestAuthenticateResolvesKnownKeys(t *testing.T) {
+	a, err := Load(writeKeys(t, "# issued keys\nalice key-a\n\nbob   key-b\n"))
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate(context.Background(), auth.Credentials{APIKey: "key-b"})
+	require.NoError(t, err)
+	require.Equal(t, auth.Principal{UserID: "bob"}, principal)
+
+	_, err = a.Authenticate(context.Background(), auth.Credentials{APIKey: "key-c"})
+	require.ErrorIs(t, err, auth.ErrUnauthenticated)
+
+	_, err = a.Authenticate(context.Background(), auth.Credentials{BearerToken: "key-a"})
+	require.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	for name, content := range map[string]string{
+		"missing key":   "alice\n",
+		"extra field":   "alice key-a admin\n",
+		"duplicate key": "alice key-a\nbob key-a\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeKeys(t, content))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := Load(writeKeys(t, "# nothing yet\n"))
+	require.ErrorIs(t, err, ErrNoKeys)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
@@ -0,0 +1,222 @@
+// Package jwt implements the Authenticator port with HMAC-signed JSON Web Tokens.
+//
+// Tokens must be signed with HS256, HS384 or HS512 and carry the user ID in the "sub"
+// claim. The "exp" and "nbf" claims are enforced when present, and "iss" and "aud" are
+// checked when the authenticator is configured with an expected issuer or audience.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+)
+
+const (
+	defaultLeeway = 30 * time.Second
+
+	errFmtInvalidToken = "%w: %s"
+
+	reasonMissingToken     = "missing bearer token"
+	reasonMalformed        = "malformed token"
+	reasonUnsupportedAlg   = "unsupported signing algorithm"
+	reasonBadSignature     = "signature mismatch"
+	reasonExpired          = "token expired"
+	reasonNotYetValid      = "token not valid yet"
+	reasonMissingSubject   = "missing subject"
+	reasonIssuerMismatch   = "unexpected issuer"
+	reasonAudienceMismatch = "unexpected audience"
+)
+
+// ErrEmptySecret is returned when the authenticator is built without a signing secret.
+var ErrEmptySecret = errors.New("jwt: signing secret must not be empty")
+
+var algorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+type header struct {
+	Alg string `json:"alg"`
+}
+
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// audience accepts both the single-string and the array forms of the "aud" claim.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]string)(a))
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*a = audience{single}
+	return nil
+}
+
+func (a audience) contains(want string) bool {
+	for _, aud := range a {
+		if aud == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies HMAC-signed tokens.
+type Authenticator struct {
+	secret   []byte
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+var _ auth.Authenticator = (*Authenticator)(nil)
+
+// Option customises the authenticator.
+type Option func(*Authenticator)
+
+// WithIssuer requires tokens to carry the given "iss" claim.
+func WithIssuer(issuer string) Option {
+	return func(a *Authenticator) {
+		a.issuer = issuer
+	}
+}
+
+// WithAudience requires tokens to list the given value in their "aud" claim.
+func WithAudience(aud string) Option {
+	return func(a *Authenticator) {
+		a.audience = aud
+	}
+}
+
+// WithLeeway overrides the clock skew tolerated when checking "exp" and "nbf".
+func WithLeeway(leeway time.Duration) Option {
+	return func(a *Authenticator) {
+		if leeway >= 0 {
+			a.leeway = leeway
+		}
+	}
+}
+
+// WithNow overrides the time source used to check "exp" and "nbf".
+func WithNow(now func() time.Time) Option {
+	return func(a *Authenticator) {
+		if now != nil {
+			a.now = now
+		}
+	}
+}
+
+// New returns an authenticator for tokens signed with secret.
+func New(secret []byte, opts ...Option) (*Authenticator, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+	a := &Authenticator{
+		secret: append([]byte(nil), secret...),
+		leeway: defaultLeeway,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+// Authenticate verifies the bearer token and returns its subject as the principal.
+func (a *Authenticator) Authenticate(_ context.Context, creds auth.Credentials) (auth.Principal, error) {
+	if creds.BearerToken == "" {
+		return auth.Principal{}, invalid(reasonMissingToken)
+	}
+	c, err := a.verify(creds.BearerToken)
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	return auth.Principal{UserID: c.Subject}, nil
+}
+
+func (a *Authenticator) verify(token string) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims{}, invalid(reasonMalformed)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return claims{}, invalid(reasonMalformed)
+	}
+	newHash, ok := algorithms[h.Alg]
+	if !ok {
+		return claims{}, invalid(reasonUnsupportedAlg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims{}, invalid(reasonMalformed)
+	}
+	mac := hmac.New(newHash, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return claims{}, invalid(reasonBadSignature)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return claims{}, invalid(reasonMalformed)
+	}
+	return c, a.validate(c)
+}
+
+func (a *Authenticator) validate(c claims) error {
+	now := a.now()
+	if c.ExpiresAt != 0 && now.After(time.Unix(c.ExpiresAt, 0).Add(a.leeway)) {
+		return invalid(reasonExpired)
+	}
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-a.leeway)) {
+		return invalid(reasonNotYetValid)
+	}
+	if c.Subject == "" {
+		return invalid(reasonMissingSubject)
+	}
+	if a.issuer != "" && c.Issuer != a.issuer {
+		return invalid(reasonIssuerMismatch)
+	}
+	if a.audience != "" && !c.Audience.contains(a.audience) {
+		return invalid(reasonAudienceMismatch)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func invalid(reason string) error {
+	return fmt.Errorf(errFmtInvalidToken, auth.ErrUnauthenticated, reason)
+}
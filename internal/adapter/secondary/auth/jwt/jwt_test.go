@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	secret = []byte("test-secret")
+	now    = time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+)
+
+func sign(t *testing.T, key []byte, alg string, claims map[string]any) string {
+	t.Helper()
+	segment := func(v any) string {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	unsigned := segment(map[string]string{"alg": alg, "typ": "JWT"}) + "." + segment(claims)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newAuthenticator(t *testing.T, opts ...Option) *Authenticator {
+	t.Helper()
+	a, err := New(secret, append([]Option{WithNow(func() time.Time { return now })}, opts...)...)
+	require.NoError(t, err)
+	return a
+}
+
+func TestAuthenticateAcceptsValidToken(t *testing.T) {
+	a := newAuthenticator(t, WithIssuer("chat"), WithAudience("chat-grpc"))
+	token := sign(t, secret, "HS256", map[string]any{
+		"sub": "alice",
+		"iss": "chat",
+		"aud": []string{"other", "chat-grpc"},
+		"exp": now.Add(time.Minute).Unix(),
+	})
+
+	principal, err := a.Authenticate(context.Background(), auth.Credentials{BearerToken: token})
+	require.NoError(t, err)
+	require.Equal(t, auth.Principal{UserID: "alice"}, principal)
+}
+
+func TestAuthenticateRejectsInvalidTokens(t *testing.T) {
+	a := newAuthenticator(t, WithIssuer("chat"), WithAudience("chat-grpc"), WithLeeway(0))
+	valid := map[string]any{"sub": "alice", "iss": "chat", "aud": "chat-grpc"}
+	with := func(key string, value any) map[string]any {
+		claims := map[string]any{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	for name, token := range map[string]string{
+		"missing":           "",
+		"malformed":         "not-a-token",
+		"wrong secret":      sign(t, []byte("other"), "HS256", valid),
+		"unsupported alg":   sign(t, secret, "none", valid),
+		"expired":           sign(t, secret, "HS256", with("exp", now.Add(-time.Second).Unix())),
+		"not yet valid":     sign(t, secret, "HS256", with("nbf", now.Add(time.Minute).Unix())),
+		"missing subject":   sign(t, secret, "HS256", with("sub", "")),
+		"unexpected issuer": sign(t, secret, "HS256", with("iss", "elsewhere")),
+		"wrong audience":    sign(t, secret, "HS256", with("aud", "other")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(context.Background(), auth.Credentials{BearerToken: token})
+			require.ErrorIs(t, err, auth.ErrUnauthenticated)
+		})
+	}
+}
+
+func TestNewRequiresSecret(t *testing.T) {
+	_, err := New(nil)
+	require.ErrorIs(t, err, ErrEmptySecret)
+}
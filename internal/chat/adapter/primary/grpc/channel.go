@@ -0,0 +1,675 @@
+package grpcadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lechitz/chat-grpc/api/proto/chatv1"
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/lechitz/chat-grpc/internal/chat/core/usecase"
+	"github.com/lechitz/chat-grpc/internal/shared/ctxkeys"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// channel is the state of a single Channel stream: the user it belongs to and the room
+// sessions it follows. Only the handler goroutine touches rooms and userID; forwarders
+// report back through the closed and failed channels.
+type channel struct {
+	srv    *Server
+	stream chatv1.ChatService_ChannelServer
+	ctx    context.Context
+
+	principal     string
+	authenticated bool
+
+	// userID is fixed by the first session the stream joins or resumes, which also opens the
+	// stream's direct message inbox.
+	userID      string
+	displayName string
+	rooms       map[string]*subscription
+	inbox       *subscription
+	inboxID     string
+
+	// received counts the envelopes read from the client, so error notices can name the
+	// envelope that caused them.
+	received uint64
+	// unread is an envelope read from the client after it went away, before the handler could
+	// take it. It is only set by the receiving goroutine before it reports on recvErr.
+	unread *chatv1.ClientEnvelope
+
+	sendMu sync.Mutex
+	closed chan *subscription
+	failed chan error
+}
+
+// subscription is a room session followed by the stream and the goroutine forwarding its events.
+type subscription struct {
+	session domain.Session
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newChannel(s *Server, stream chatv1.ChatService_ChannelServer) *channel {
+	ctx := stream.Context()
+	principal, authenticated := principalFromContext(ctx)
+	return &channel{
+		srv:           s,
+		stream:        stream,
+		ctx:           ctx,
+		principal:     principal,
+		authenticated: authenticated,
+		rooms:         make(map[string]*subscription),
+		closed:        make(chan *subscription),
+		failed:        make(chan error, 1),
+	}
+}
+
+// run serves the stream until the client ends it, leaves its last room or the server closes
+// its last session.
+func (c *channel) run() error {
+	// Envelopes are received on their own goroutine so the stream can also end when the
+	// server closes a session. The goroutine exits once the handler returns and the
+	// stream context is cancelled, always reporting why on recvErr.
+	incoming := make(chan *chatv1.ClientEnvelope)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := c.stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case incoming <- req:
+			case <-c.ctx.Done():
+				c.unread = req
+				recvErr <- c.ctx.Err()
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return c.end(<-recvErr)
+		case err := <-c.failed:
+			if c.ctx.Err() != nil {
+				// The forwarder failed because the client went away.
+				return c.end(<-recvErr)
+			}
+			return err
+		case sub := <-c.closed:
+			if c.rooms[sub.session.RoomID] != sub {
+				continue
+			}
+			// The service removed the session; its SESSION_CLOSED notice was already sent.
+			delete(c.rooms, sub.session.RoomID)
+			if len(c.rooms) == 0 {
+				return status.Error(codes.Aborted, errMsgSessionClosed)
+			}
+		case err := <-recvErr:
+			return c.end(err)
+		case req := <-incoming:
+			c.received++
+			c.heartbeat()
+			done, err := c.handle(req)
+			if err != nil && recoverable(err) {
+				err = c.reject(req, err)
+			}
+			if err != nil || done {
+				return err
+			}
+		}
+	}
+}
+
+// end settles the stream once the receiving goroutine stopped with err. A Leave the client
+// sent just before going away is still honoured, so the room sees the user leave right away
+// rather than the session being detached for the resume grace period.
+func (c *channel) end(err error) error {
+	if leave := c.unread.GetLeave(); leave != nil {
+		// Sessions the Leave does not resolve to are detached with the others.
+		_, _ = c.leave(leave)
+	}
+	if err == io.EOF {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		return status.Error(codes.Canceled, errMsgClientCanceled)
+	}
+	return err
+}
+
+// handle processes one envelope. It reports true when the stream must end gracefully.
+func (c *channel) handle(req *chatv1.ClientEnvelope) (bool, error) {
+	switch msg := req.GetMessage().(type) {
+	case *chatv1.ClientEnvelope_Join:
+		return false, c.join(msg.Join)
+	case *chatv1.ClientEnvelope_Resume:
+		return false, c.resume(msg.Resume)
+	case *chatv1.ClientEnvelope_Chat:
+		return false, c.chat(req.GetRequestId(), msg.Chat)
+	case *chatv1.ClientEnvelope_Leave:
+		return c.leave(msg.Leave)
+	case *chatv1.ClientEnvelope_Direct:
+		return false, c.direct(msg.Direct)
+	case *chatv1.ClientEnvelope_Typing:
+		return false, c.typing(msg.Typing)
+	case *chatv1.ClientEnvelope_Edit:
+		return false, c.edit(msg.Edit)
+	case *chatv1.ClientEnvelope_Delete:
+		return false, c.delete(msg.Delete)
+	case *chatv1.ClientEnvelope_React:
+		return false, c.react(msg.React, true)
+	case *chatv1.ClientEnvelope_Unreact:
+		return false, c.react(msg.Unreact, false)
+	case *chatv1.ClientEnvelope_Moderate:
+		return false, c.moderate(msg.Moderate)
+	case *chatv1.ClientEnvelope_SetRole:
+		return false, c.setRole(msg.SetRole)
+	case *chatv1.ClientEnvelope_Ping:
+		return false, c.ping(msg.Ping)
+	default:
+		return false, violation(codes.InvalidArgument, errMsgInvalidPayload)
+	}
+}
+
+func (c *channel) join(in *chatv1.JoinRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgJoinPayloadRequired)
+	}
+
+	userID := in.GetUserId()
+	if c.authenticated {
+		if userID != "" && userID != c.principal {
+			return violation(codes.PermissionDenied, errMsgPrincipalMismatch)
+		}
+		userID = c.principal
+	}
+	if c.userID != "" {
+		if userID != "" && userID != c.userID {
+			return violation(codes.PermissionDenied, errMsgStreamUserMismatch)
+		}
+		userID = c.userID
+	}
+	if _, ok := c.rooms[in.GetRoom()]; ok {
+		return status.Error(codes.FailedPrecondition, errMsgRoomAlreadyJoined)
+	}
+
+	joinReq := domain.JoinRequest{
+		UserID:       userID,
+		DisplayName:  in.GetDisplayName(),
+		RoomID:       in.GetRoom(),
+		HistoryLimit: int(in.GetHistoryLimit()),
+		Password:     in.GetPassword(),
+		InviteToken:  in.GetInviteToken(),
+		Wait:         in.GetWaitForSeat(),
+	}
+	if since := in.GetHistorySinceUtc(); since != zeroUnixTimestamp {
+		joinReq.HistorySince = time.UnixMilli(since).UTC()
+	}
+
+	session, events, err := c.srv.chat.Join(c.ctx, joinReq)
+	if err != nil {
+		return translateError(err)
+	}
+	return c.attach(session, events, false)
+}
+
+func (c *channel) resume(in *chatv1.ResumeRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgResumePayloadReq)
+	}
+
+	owner := c.principal
+	if c.userID != "" {
+		owner = c.userID
+	}
+
+	session, events, err := c.srv.chat.Resume(c.ctx, domain.ResumeRequest{
+		Token:        in.GetResumeToken(),
+		LastSequence: in.GetLastSequence(),
+		UserID:       owner,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+	return c.attach(session, events, true)
+}
+
+// chat broadcasts a message and, when the client supplied a request ID, acknowledges it.
+func (c *channel) chat(requestID string, payload *chatv1.ChatPayload) error {
+	if payload == nil {
+		return violation(codes.InvalidArgument, errMsgChatPayloadRequired)
+	}
+	sub, err := c.resolve(payload.UserId, payload.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	stored, err := c.srv.chat.Broadcast(c.ctx, domain.Message{
+		UserID:    sub.session.UserID,
+		RoomID:    sub.session.RoomID,
+		Content:   payload.GetContent(),
+		ReplyTo:   payload.GetReplyTo(),
+		RequestID: requestID,
+	})
+	if err != nil {
+		return translateError(err)
+	}
+	if requestID == "" {
+		return nil
+	}
+	return c.send(&chatv1.ServerEvent{
+		Room: stored.RoomID,
+		Event: &chatv1.ServerEvent_Ack{
+			Ack: &chatv1.SendAck{
+				RequestId: requestID,
+				MessageId: stored.ID,
+				Sequence:  stored.Sequence,
+			},
+		},
+	})
+}
+
+func (c *channel) edit(in *chatv1.EditMessageRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgEditPayloadReq)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.srv.chat.EditMessage(c.ctx, sub.session.RoomID, sub.session.UserID, in.GetMessageId(), in.GetContent()); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (c *channel) delete(in *chatv1.DeleteMessageRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgDeletePayloadReq)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.srv.chat.DeleteMessage(c.ctx, sub.session.RoomID, sub.session.UserID, in.GetMessageId()); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (c *channel) react(in *chatv1.ReactionRequest, add bool) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgReactionPayloadReq)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.srv.chat.React(c.ctx, sub.session.RoomID, sub.session.UserID, in.GetMessageId(), in.GetEmoji(), add); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// moderate applies a sanction on behalf of the stream's user, who must be a moderator.
+func (c *channel) moderate(in *chatv1.ModerationRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgModerationPayload)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	roomID, actorID := sub.session.RoomID, sub.session.UserID
+	duration := time.Duration(in.GetDurationSeconds()) * time.Second
+	switch in.GetAction() {
+	case chatv1.ModerationAction_MODERATION_ACTION_KICK:
+		err = c.srv.chat.Kick(c.ctx, roomID, actorID, in.GetUserId(), in.GetReason())
+	case chatv1.ModerationAction_MODERATION_ACTION_BAN:
+		if duration == 0 {
+			return status.Error(codes.InvalidArgument, errMsgSanctionDuration)
+		}
+		err = c.srv.chat.Ban(c.ctx, roomID, actorID, in.GetUserId(), in.GetReason(), duration)
+	case chatv1.ModerationAction_MODERATION_ACTION_UNBAN:
+		err = c.srv.chat.Ban(c.ctx, roomID, actorID, in.GetUserId(), in.GetReason(), 0)
+	case chatv1.ModerationAction_MODERATION_ACTION_MUTE:
+		if duration == 0 {
+			return status.Error(codes.InvalidArgument, errMsgSanctionDuration)
+		}
+		err = c.srv.chat.Mute(c.ctx, roomID, actorID, in.GetUserId(), in.GetReason(), duration)
+	case chatv1.ModerationAction_MODERATION_ACTION_UNMUTE:
+		err = c.srv.chat.Mute(c.ctx, roomID, actorID, in.GetUserId(), in.GetReason(), 0)
+	default:
+		return status.Error(codes.InvalidArgument, errMsgModerationAction)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (c *channel) setRole(in *chatv1.SetRoleRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgSetRolePayloadReq)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	if err := c.srv.chat.SetRole(c.ctx, sub.session.RoomID, sub.session.UserID, in.GetUserId(), roleFromProto(in.GetRole())); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (c *channel) direct(in *chatv1.DirectMessageRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgDirectPayloadReq)
+	}
+	if c.userID == "" {
+		return status.Error(codes.FailedPrecondition, errMsgJoinRequired)
+	}
+
+	if err := c.srv.chat.SendDirect(c.ctx, domain.DirectMessage{
+		FromUserID:      c.userID,
+		FromDisplayName: c.displayName,
+		ToUserID:        in.GetToUserId(),
+		Content:         in.GetContent(),
+	}); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+func (c *channel) typing(in *chatv1.TypingRequest) error {
+	if in == nil {
+		return violation(codes.InvalidArgument, errMsgTypingPayloadReq)
+	}
+	sub, err := c.resolve(nil, in.Room, errMsgJoinRequired)
+	if err != nil {
+		return err
+	}
+
+	if err := c.srv.chat.Typing(c.ctx, sub.session.RoomID, sub.session.UserID, in.GetTyping()); err != nil {
+		return translateError(err)
+	}
+	return nil
+}
+
+// ping answers a heartbeat; the activity itself was recorded when the envelope arrived.
+func (c *channel) ping(in *chatv1.PingRequest) error {
+	return c.send(&chatv1.ServerEvent{
+		Event: &chatv1.ServerEvent_Pong{
+			Pong: &chatv1.Pong{
+				Nonce:        in.GetNonce(),
+				TimestampUtc: time.Now().UnixMilli(),
+			},
+		},
+	})
+}
+
+// heartbeat records activity on every session of the stream, since any envelope proves the
+// client is alive. Sessions the server just removed are reported through the closed channel,
+// so their errors are ignored here.
+func (c *channel) heartbeat() {
+	if len(c.rooms) == 0 {
+		return
+	}
+	connections := make([]string, 0, len(c.rooms))
+	for _, sub := range c.rooms {
+		connections = append(connections, sub.session.ConnectionID)
+	}
+	_ = c.srv.chat.Heartbeat(c.ctx, connections...)
+}
+
+// reject reports a recoverable error as a TYPE_ERROR notice instead of ending the stream.
+// The notice names the envelope by its kind and its 1-based position on the stream. Chat
+// envelopes carrying a request ID are answered with a failed SendAck instead.
+func (c *channel) reject(req *chatv1.ClientEnvelope, err error) error {
+	st := status.Convert(err)
+	if req.GetChat() != nil && req.GetRequestId() != "" {
+		return c.send(&chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Ack{
+				Ack: &chatv1.SendAck{
+					RequestId: req.GetRequestId(),
+					ErrorCode: uint32(st.Code()),
+					Error:     st.Message(),
+				},
+			},
+		})
+	}
+	return c.send(&chatv1.ServerEvent{
+		Event: &chatv1.ServerEvent_Notice{
+			Notice: &chatv1.ServerNotice{
+				Type:          chatv1.ServerNotice_TYPE_ERROR,
+				Message:       st.Message(),
+				ErrorCode:     uint32(st.Code()),
+				Envelope:      envelopeKind(req),
+				EnvelopeIndex: c.received,
+				RequestId:     req.GetRequestId(),
+			},
+		},
+	})
+}
+
+// leave removes one room from the stream and reports true once no room is left.
+func (c *channel) leave(in *chatv1.LeaveRequest) (bool, error) {
+	if in == nil {
+		return false, violation(codes.InvalidArgument, errMsgLeavePayloadReq)
+	}
+	sub, err := c.resolve(in.UserId, in.Room, errMsgNoActiveSession)
+	if err != nil {
+		return false, err
+	}
+
+	sub.stop()
+	delete(c.rooms, sub.session.RoomID)
+	if err := c.srv.chat.Leave(c.ctx, sub.session.RoomID, sub.session.ConnectionID); err != nil {
+		return false, translateError(err)
+	}
+	return len(c.rooms) == 0, nil
+}
+
+// resolve finds the subscription an envelope addresses. The room may be omitted while the
+// stream follows a single room; otherwise it must name one of the stream's rooms. Naming
+// another room only fails the envelope: the server may have removed the stream from it while
+// the envelope was in flight.
+func (c *channel) resolve(userID, room *string, errMsgNoRoom string) (*subscription, error) {
+	if len(c.rooms) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, errMsgNoRoom)
+	}
+	if userID != nil && *userID != c.userID {
+		return nil, violation(codes.PermissionDenied, errMsgIdentityMismatch)
+	}
+	if room != nil {
+		sub, ok := c.rooms[*room]
+		if !ok {
+			return nil, status.Error(codes.FailedPrecondition, errMsgRoomNotJoined)
+		}
+		return sub, nil
+	}
+	if len(c.rooms) > 1 {
+		return nil, status.Error(codes.InvalidArgument, errMsgRoomRequired)
+	}
+	for _, sub := range c.rooms {
+		return sub, nil
+	}
+	return nil, status.Error(codes.FailedPrecondition, errMsgNoRoom)
+}
+
+// attach acknowledges a joined or resumed session and starts forwarding its events. The
+// ack must precede the forwarder so the replayed backlog, queued first on the events
+// channel, is delivered right after it. A join waiting for a seat is not acknowledged yet:
+// its events carry the queue positions and then the ack, ahead of the backlog.
+func (c *channel) attach(session domain.Session, events <-chan domain.Event, resumed bool) error {
+	sub := &subscription{session: session, done: make(chan struct{})}
+	c.rooms[session.RoomID] = sub
+	if c.userID == "" {
+		c.userID = session.UserID
+		c.displayName = session.DisplayName
+	}
+
+	if session.QueuePosition == 0 {
+		if err := c.send(&chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Joined{Joined: joinAck(session, resumed)},
+			Room:  session.RoomID,
+		}); err != nil {
+			close(sub.done)
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	sub.cancel = cancel
+	go c.forward(ctx, sub, events)
+
+	if c.inbox == nil {
+		return c.openInbox()
+	}
+	return nil
+}
+
+// openInbox starts forwarding the direct messages of the stream's user.
+func (c *channel) openInbox() error {
+	inboxID, events, err := c.srv.chat.OpenInbox(c.ctx, c.userID)
+	if err != nil {
+		return translateError(err)
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.inboxID = inboxID
+	c.inbox = &subscription{
+		session: domain.Session{UserID: c.userID},
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go c.forward(ctx, c.inbox, events)
+	return nil
+}
+
+func (c *channel) forward(ctx context.Context, sub *subscription, events <-chan domain.Event) {
+	defer close(sub.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				// The service closed the stream: either the session left on request, in which
+				// case the handler already stopped this forwarder, or the server removed it.
+				select {
+				case c.closed <- sub:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if err := c.send(domainEventToProto(ev)); err != nil {
+				select {
+				case c.failed <- err:
+				default:
+				}
+				return
+			}
+		}
+	}
+}
+
+func (c *channel) send(evt *chatv1.ServerEvent) error {
+	if evt == nil {
+		return nil
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return c.stream.Send(evt)
+}
+
+// detachAll stops every forwarder, detaches the sessions still followed by the stream and
+// closes its inbox.
+func (c *channel) detachAll() {
+	for roomID, sub := range c.rooms {
+		sub.stop()
+		delete(c.rooms, roomID)
+		c.detach(sub.session)
+	}
+	if c.inbox != nil {
+		c.inbox.stop()
+		if err := c.srv.chat.CloseInbox(context.Background(), c.userID, c.inboxID); err != nil {
+			c.srv.log.Warnw(logMsgCloseInboxFailure, logFieldUser, c.userID, logFieldError, err)
+		}
+	}
+}
+
+func (c *channel) detach(session domain.Session) {
+	if err := c.srv.chat.Detach(context.Background(), session.RoomID, session.ConnectionID); err != nil && !errors.Is(err, usecase.ErrUserNotInRoom) {
+		c.srv.log.Warnw(logMsgCleanupSessionFailure, logFieldRoom, session.RoomID, logFieldUser, session.UserID, logFieldError, err)
+	}
+}
+
+// stop cancels the forwarder and waits for it to return.
+func (sub *subscription) stop() {
+	if sub.cancel != nil {
+		sub.cancel()
+	}
+	<-sub.done
+}
+
+// principalFromContext returns the user ID the stream authenticated as, if any.
+func principalFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(ctxkeys.UserID).(string)
+	return userID, ok && userID != ""
+}
+
+// protocolViolation is an envelope the client should never have sent, such as a missing
+// payload or another user's identity. Unlike the other errors of a handler, it ends the stream.
+type protocolViolation struct {
+	st *status.Status
+}
+
+func violation(code codes.Code, msg string) error {
+	return &protocolViolation{st: status.New(code, msg)}
+}
+
+func (v *protocolViolation) Error() string { return v.st.Err().Error() }
+
+// GRPCStatus lets the stream end with the violation's status.
+func (v *protocolViolation) GRPCStatus() *status.Status { return v.st }
+
+// recoverable reports whether a handler error concerns only the envelope that caused it, so
+// the stream can carry on after a TYPE_ERROR notice. Protocol violations, internal failures
+// and transport errors end the stream.
+func recoverable(err error) bool {
+	var v *protocolViolation
+	if errors.As(err, &v) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition,
+		codes.PermissionDenied, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// envelopeKind names the payload of an envelope after its field in ClientEnvelope.
+func envelopeKind(req *chatv1.ClientEnvelope) string {
+	msg := req.ProtoReflect()
+	field := msg.WhichOneof(msg.Descriptor().Oneofs().ByName(envelopeOneof))
+	if field == nil {
+		return ""
+	}
+	return string(field.Name())
+}
@@ -2,6 +2,7 @@ package grpcadapter
 
 const (
 	logMsgCleanupSessionFailure = "failed to cleanup session"
+	logMsgCloseInboxFailure     = "failed to close direct message inbox"
 	logFieldRoom                = "room"
 	logFieldUser                = "user"
 	logFieldError               = "error"
@@ -9,17 +10,52 @@ const (
 	welcomeMessageFormat = "Bem-vindo %s!"
 	noticeJoinedFormat   = "%s entrou na sala"
 	noticeLeftFormat     = "%s saiu da sala"
+	noticeRemovedFormat  = "%s foi removido da sala: %s"
+	noticeMissedFormat   = "%d eventos perdidos; ressincronize a partir da última sequência"
+	noticeKickedFormat   = "%s foi expulso da sala por %s"
+	noticeBannedFormat   = "%s foi banido da sala por %s"
+	noticeUnbannedFormat = "%s teve o banimento removido por %s"
+	noticeMutedFormat    = "%s foi silenciado por %s"
+	noticeUnmutedFormat  = "%s teve o silêncio removido por %s"
+	noticeReasonFormat   = "%s: %s"
+	noticeRoleFormat     = "%s agora é %s"
+	noticeTopicFormat    = "%s mudou o tópico para: %s"
+	noticeTopicCleared   = "%s removeu o tópico"
+	noticeQueueFormat    = "Sala cheia; você é o %dº da fila"
+	roleNameReadOnly     = "somente leitura"
+	roleNameMember       = "membro"
+	roleNameModerator    = "moderador"
+	roleNameOwner        = "dono"
 
 	errMsgClientCanceled      = "client canceled stream"
-	errMsgSessionExists       = "session already established"
+	errMsgRoomAlreadyJoined   = "room already joined on this stream"
+	errMsgRoomRequired        = "room required when the stream follows several rooms"
 	errMsgJoinPayloadRequired = "join payload required"
 	errMsgChatPayloadRequired = "chat payload required"
 	errMsgJoinRequired        = "join required before sending messages"
 	errMsgLeavePayloadReq     = "leave payload required"
+	errMsgResumePayloadReq    = "resume payload required"
+	errMsgDirectPayloadReq    = "direct message payload required"
+	errMsgTypingPayloadReq    = "typing payload required"
+	errMsgEditPayloadReq      = "edit payload required"
+	errMsgDeletePayloadReq    = "delete payload required"
+	errMsgReactionPayloadReq  = "reaction payload required"
+	errMsgModerationPayload   = "moderation payload required"
+	errMsgModerationAction    = "unknown moderation action"
+	errMsgSanctionDuration    = "bans and mutes require a duration"
+	errMsgSetRolePayloadReq   = "set role payload required"
 	errMsgNoActiveSession     = "no active session"
 	errMsgInvalidPayload      = "invalid payload"
+	errMsgSessionClosed       = "session closed by server"
+	errMsgIdentityMismatch    = "envelope user does not match the stream's user"
+	errMsgRoomNotJoined       = "room not joined on this stream"
+	errMsgStreamUserMismatch  = "join user does not match the stream's user"
+	errMsgPrincipalMismatch   = "join user does not match the authenticated principal"
+	errMsgCallerMismatch      = "request user does not match the authenticated principal"
 )
 
 const (
 	zeroUnixTimestamp = 0
+	// envelopeOneof is the name of the oneof holding a ClientEnvelope's payload.
+	envelopeOneof = "message"
 )
@@ -0,0 +1,284 @@
+package grpcadapter
+
+import (
+	"context"
+	"time"
+
+	"github.com/lechitz/chat-grpc/api/proto/chatv1"
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ListRooms returns a page of the public rooms.
+func (s *Server) ListRooms(ctx context.Context, req *chatv1.ListRoomsRequest) (*chatv1.ListRoomsResponse, error) {
+	page, err := s.chat.ListRooms(ctx, domain.ListRoomsRequest{
+		Prefix:    req.GetNamePrefix(),
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	resp := &chatv1.ListRoomsResponse{
+		Rooms:         make([]*chatv1.RoomSummary, 0, len(page.Rooms)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, room := range page.Rooms {
+		resp.Rooms = append(resp.Rooms, roomSummaryToProto(room))
+	}
+	return resp, nil
+}
+
+// GetRoom returns a room and, when the caller may see them, its participants.
+func (s *Server) GetRoom(ctx context.Context, req *chatv1.GetRoomRequest) (*chatv1.GetRoomResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.chat.GetRoom(ctx, req.GetRoom(), userID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	resp := &chatv1.GetRoomResponse{
+		Room:         roomSummaryToProto(room.RoomSummary),
+		Participants: make([]*chatv1.Participant, 0, len(room.Participants)),
+	}
+	for _, p := range room.Participants {
+		resp.Participants = append(resp.Participants, &chatv1.Participant{
+			UserId:      p.UserID,
+			DisplayName: p.DisplayName,
+			JoinedAtUtc: p.JoinedAt.UnixMilli(),
+			Role:        roles[p.Role],
+		})
+	}
+	return resp, nil
+}
+
+// GetThread returns a thread root and a page of its replies on behalf of the caller.
+func (s *Server) GetThread(ctx context.Context, req *chatv1.GetThreadRequest) (*chatv1.GetThreadResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.chat.GetThread(ctx, domain.ThreadRequest{
+		RoomID:    req.GetRoom(),
+		UserID:    userID,
+		RootID:    req.GetMessageId(),
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	resp := &chatv1.GetThreadResponse{
+		Root:          messageToProto(page.Root),
+		Replies:       make([]*chatv1.ChatPayload, 0, len(page.Replies)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, reply := range page.Replies {
+		resp.Replies = append(resp.Replies, messageToProto(reply))
+	}
+	return resp, nil
+}
+
+// CreateRoom creates a persistent room owned by the caller.
+func (s *Server) CreateRoom(ctx context.Context, req *chatv1.CreateRoomRequest) (*chatv1.CreateRoomResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.chat.CreateRoom(ctx, domain.CreateRoomRequest{
+		RoomID:          req.GetRoom(),
+		UserID:          userID,
+		Title:           req.GetTitle(),
+		Topic:           req.GetTopic(),
+		Description:     req.GetDescription(),
+		Visibility:      visibilityFromProto(req.GetVisibility()),
+		Access:          accessFromProto(req.GetAccess()),
+		Password:        req.GetPassword(),
+		MaxParticipants: int(req.GetMaxParticipants()),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &chatv1.CreateRoomResponse{Room: roomSummaryToProto(room)}, nil
+}
+
+// UpdateRoom changes the metadata of a room on behalf of the caller.
+func (s *Server) UpdateRoom(ctx context.Context, req *chatv1.UpdateRoomRequest) (*chatv1.UpdateRoomResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.chat.UpdateRoom(ctx, domain.UpdateRoomRequest{
+		RoomID:          req.GetRoom(),
+		UserID:          userID,
+		Title:           req.Title,
+		Topic:           req.Topic,
+		Description:     req.Description,
+		Visibility:      visibilityFromProto(req.GetVisibility()),
+		Access:          accessFromProto(req.GetAccess()),
+		Password:        req.Password,
+		MaxParticipants: optionalInt(req.MaxParticipants),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &chatv1.UpdateRoomResponse{Room: roomSummaryToProto(room)}, nil
+}
+
+// ArchiveRoom archives a room on behalf of the caller, who must own it.
+func (s *Server) ArchiveRoom(ctx context.Context, req *chatv1.ArchiveRoomRequest) (*chatv1.ArchiveRoomResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	room, err := s.chat.ArchiveRoom(ctx, req.GetRoom(), userID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &chatv1.ArchiveRoomResponse{Room: roomSummaryToProto(room)}, nil
+}
+
+// CreateInvite issues an invite to a room on behalf of the caller, who must own it.
+func (s *Server) CreateInvite(ctx context.Context, req *chatv1.CreateInviteRequest) (*chatv1.CreateInviteResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	invite, err := s.chat.CreateInvite(ctx, domain.CreateInviteRequest{
+		RoomID:  req.GetRoom(),
+		UserID:  userID,
+		TTL:     time.Duration(req.GetTtlSeconds()) * time.Second,
+		MaxUses: int(req.GetMaxUses()),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &chatv1.CreateInviteResponse{
+		Invite: &chatv1.Invite{
+			Token:        invite.Token,
+			Room:         invite.RoomID,
+			CreatedBy:    invite.CreatedBy,
+			CreatedAtUtc: invite.CreatedAt.UnixMilli(),
+			ExpiresAtUtc: optionalUnixMilli(invite.ExpiresAt),
+			MaxUses:      uint32(invite.MaxUses),
+			Uses:         uint32(invite.Uses),
+		},
+	}, nil
+}
+
+// RevokeInvite withdraws an invite on behalf of the caller, who must own the room.
+func (s *Server) RevokeInvite(ctx context.Context, req *chatv1.RevokeInviteRequest) (*chatv1.RevokeInviteResponse, error) {
+	userID, err := caller(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.chat.RevokeInvite(ctx, req.GetRoom(), userID, req.GetToken()); err != nil {
+		return nil, translateError(err)
+	}
+	return &chatv1.RevokeInviteResponse{}, nil
+}
+
+// caller returns the user a unary call acts for: the authenticated principal, which a
+// user ID given in the request must match, or that user ID when the server runs without
+// authentication.
+func caller(ctx context.Context, userID string) (string, error) {
+	principal, ok := principalFromContext(ctx)
+	if !ok {
+		return userID, nil
+	}
+	if userID != "" && userID != principal {
+		return "", status.Error(codes.PermissionDenied, errMsgCallerMismatch)
+	}
+	return principal, nil
+}
+
+// messageToProto converts a stored message, such as those returned by thread queries.
+func messageToProto(msg domain.Message) *chatv1.ChatPayload {
+	return &chatv1.ChatPayload{
+		UserId:       proto.String(msg.UserID),
+		Room:         proto.String(msg.RoomID),
+		Content:      msg.Content,
+		TimestampUtc: msg.SentAt.UnixMilli(),
+		MessageId:    msg.ID,
+		Sequence:     msg.Sequence,
+		EditedAtUtc:  optionalUnixMilli(msg.EditedAt),
+		Deleted:      msg.Deleted,
+		Reactions:    reactionsToProto(msg.Reactions),
+		ReplyTo:      msg.ReplyTo,
+		ReplyCount:   uint32(msg.ReplyCount),
+	}
+}
+
+func roomSummaryToProto(room domain.RoomSummary) *chatv1.RoomSummary {
+	return &chatv1.RoomSummary{
+		Room:             room.RoomID,
+		ParticipantCount: uint32(room.ParticipantCount),
+		LastSequence:     room.LastSequence,
+		Title:            room.Title,
+		Topic:            room.Topic,
+		Description:      room.Description,
+		CreatedBy:        room.CreatedBy,
+		CreatedAtUtc:     optionalUnixMilli(room.CreatedAt),
+		Visibility:       visibilities[room.Visibility],
+		Persistent:       room.Persistent,
+		Archived:         room.Archived,
+		Access:           accessPolicies[room.Access],
+		MaxParticipants:  uint32(room.MaxParticipants),
+	}
+}
+
+// optionalInt converts an optional count, keeping an unset field unset.
+func optionalInt(v *uint32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+var visibilities = map[domain.Visibility]chatv1.Visibility{
+	domain.VisibilityPublic:   chatv1.Visibility_VISIBILITY_PUBLIC,
+	domain.VisibilityUnlisted: chatv1.Visibility_VISIBILITY_UNLISTED,
+}
+
+// visibilityFromProto converts a visibility; VISIBILITY_UNSPECIFIED yields the zero value,
+// which leaves the service's default or the current visibility in place.
+func visibilityFromProto(visibility chatv1.Visibility) domain.Visibility {
+	for v, p := range visibilities {
+		if p == visibility {
+			return v
+		}
+	}
+	return 0
+}
+
+var accessPolicies = map[domain.AccessPolicy]chatv1.AccessPolicy{
+	domain.AccessPublic:     chatv1.AccessPolicy_ACCESS_POLICY_PUBLIC,
+	domain.AccessPassword:   chatv1.AccessPolicy_ACCESS_POLICY_PASSWORD,
+	domain.AccessInviteOnly: chatv1.AccessPolicy_ACCESS_POLICY_INVITE_ONLY,
+}
+
+// accessFromProto converts an access policy; ACCESS_POLICY_UNSPECIFIED yields the zero
+// value, which leaves the service's default or the current policy in place.
+func accessFromProto(access chatv1.AccessPolicy) domain.AccessPolicy {
+	for a, p := range accessPolicies {
+		if p == access {
+			return a
+		}
+	}
+	return 0
+}
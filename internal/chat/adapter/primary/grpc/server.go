@@ -1,11 +1,8 @@
 package grpcadapter
 
 import (
-	"context"
 	"errors"
 	"fmt"
-	"io"
-	"sync"
 	"time"
 
 	"github.com/lechitz/chat-grpc/api/proto/chatv1"
@@ -15,18 +12,19 @@ import (
 	"github.com/lechitz/chat-grpc/internal/platform/ports/logger"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // Server implements the generated gRPC ChatServiceServer.
 type Server struct {
 	chatv1.UnimplementedChatServiceServer
 
-	chat input.StreamService
+	chat input.ChatService
 	log  logger.ContextLogger
 }
 
 // NewServer constructs a gRPC adapter backed by the domain chat service.
-func NewServer(chat input.StreamService, log logger.ContextLogger) *Server {
+func NewServer(chat input.ChatService, log logger.ContextLogger) *Server {
 	return &Server{
 		chat: chat,
 		log:  log,
@@ -34,179 +32,101 @@ func NewServer(chat input.StreamService, log logger.ContextLogger) *Server {
 }
 
 // Channel handles the bidirectional chat stream lifecycle.
+//
+// A stream belongs to a single user and may follow several rooms at once: every JoinRequest
+// or ResumeRequest adds a room, and every LeaveRequest removes one. The stream ends once its
+// last room is left. Once the stream's user is known it also receives their direct
+// messages. When the stream was authenticated, its principal is the only identity
+// it may join or resume as; the user_id of the JoinRequest may then be omitted.
 func (s *Server) Channel(stream chatv1.ChatService_ChannelServer) error {
-	ctx := stream.Context()
+	ch := newChannel(s, stream)
 
-	var (
-		session      domain.Session
-		events       <-chan domain.Event
-		hasSession   bool
-		sendMu       sync.Mutex
-		eventsCancel context.CancelFunc
-		eventsWG     sync.WaitGroup
-		eventErr     = make(chan error, 1)
-	)
+	// A stream that ends without an explicit LeaveRequest only detaches its sessions, so the
+	// client can resume them within the grace period.
+	defer ch.detachAll()
 
-	send := func(evt *chatv1.ServerEvent) error {
-		if evt == nil {
-			return nil
-		}
-		sendMu.Lock()
-		defer sendMu.Unlock()
-		if err := stream.Send(evt); err != nil {
-			return err
-		}
-		return nil
-	}
-
-	defer func() {
-		if eventsCancel != nil {
-			eventsCancel()
-		}
-		eventsWG.Wait()
-		if hasSession {
-			if err := s.chat.Leave(context.Background(), session.RoomID, session.UserID); err != nil && !errors.Is(err, usecase.ErrUserNotInRoom) {
-				s.log.Warnw(logMsgCleanupSessionFailure, logFieldRoom, session.RoomID, logFieldUser, session.UserID, logFieldError, err)
-			}
-		}
-	}()
-
-	for {
-		select {
-		case err := <-eventErr:
-			if err != nil {
-				return err
-			}
-		default:
-		}
-
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		}
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				return status.Error(codes.Canceled, errMsgClientCanceled)
-			}
-			return err
-		}
-
-		switch msg := req.GetMessage().(type) {
-		case *chatv1.ClientEnvelope_Join:
-			if hasSession {
-				return status.Error(codes.FailedPrecondition, errMsgSessionExists)
-			}
-
-			in := msg.Join
-			if in == nil {
-				return status.Error(codes.InvalidArgument, errMsgJoinPayloadRequired)
-			}
-
-			session, events, err = s.chat.Join(ctx, domain.JoinRequest{
-				UserID:      in.GetUserId(),
-				DisplayName: in.GetDisplayName(),
-				RoomID:      in.GetRoom(),
-			})
-			if err != nil {
-				return translateError(err)
-			}
-
-			hasSession = true
-
-			eventsCtx, cancel := context.WithCancel(ctx)
-			eventsCancel = cancel
-			eventsWG.Add(1)
-			go s.forwardEvents(eventsCtx, &eventsWG, events, send, eventErr)
-
-			if err := send(&chatv1.ServerEvent{
-				Event: &chatv1.ServerEvent_Joined{
-					Joined: &chatv1.JoinAck{
-						UserId:         session.UserID,
-						Room:           session.RoomID,
-						WelcomeMessage: fmt.Sprintf(welcomeMessageFormat, session.DisplayName),
-					},
-				},
-			}); err != nil {
-				return err
-			}
-
-		case *chatv1.ClientEnvelope_Chat:
-			if !hasSession {
-				return status.Error(codes.FailedPrecondition, errMsgJoinRequired)
-			}
-			payload := msg.Chat
-			if payload == nil {
-				return status.Error(codes.InvalidArgument, errMsgChatPayloadRequired)
-			}
-
-			sentAt := time.Unix(payload.GetTimestampUtc(), 0).UTC()
-			if payload.GetTimestampUtc() == zeroUnixTimestamp {
-				sentAt = time.Now().UTC()
-			}
-
-			if err := s.chat.Broadcast(ctx, domain.Message{
-				UserID:      payload.GetUserId(),
-				DisplayName: "",
-				RoomID:      payload.GetRoom(),
-				Content:     payload.GetContent(),
-				SentAt:      sentAt,
-			}); err != nil {
-				return translateError(err)
-			}
-
-		case *chatv1.ClientEnvelope_Leave:
-			if !hasSession {
-				return status.Error(codes.FailedPrecondition, errMsgNoActiveSession)
-			}
-			leave := msg.Leave
-			if leave == nil {
-				return status.Error(codes.InvalidArgument, errMsgLeavePayloadReq)
-			}
-			if err := s.chat.Leave(ctx, leave.GetRoom(), leave.GetUserId()); err != nil {
-				return translateError(err)
-			}
-			hasSession = false
-			return nil
-
-		default:
-			return status.Error(codes.InvalidArgument, errMsgInvalidPayload)
-		}
-	}
+	return ch.run()
 }
 
-func (s *Server) forwardEvents(ctx context.Context, wg *sync.WaitGroup, events <-chan domain.Event, send func(*chatv1.ServerEvent) error, errCh chan<- error) {
-	defer wg.Done()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case ev, ok := <-events:
-			if !ok {
-				return
-			}
-			if evt := domainEventToProto(ev); evt != nil {
-				if err := send(evt); err != nil {
-					select {
-					case errCh <- err:
-					default:
-					}
-					return
-				}
-			}
-		}
+// domainEventToProto converts a room event, tagging the envelope with the event's room.
+func domainEventToProto(ev domain.Event) *chatv1.ServerEvent {
+	evt := eventPayloadToProto(ev)
+	if evt != nil {
+		evt.Room = ev.RoomID
 	}
+	return evt
 }
 
-func domainEventToProto(ev domain.Event) *chatv1.ServerEvent {
+func eventPayloadToProto(ev domain.Event) *chatv1.ServerEvent {
 	switch ev.Type {
 	case domain.EventMessage:
 		return &chatv1.ServerEvent{
 			Event: &chatv1.ServerEvent_Broadcast{
 				Broadcast: &chatv1.ChatPayload{
-					UserId:       ev.UserID,
-					Room:         ev.RoomID,
+					UserId:       proto.String(ev.UserID),
+					Room:         proto.String(ev.RoomID),
 					Content:      ev.Content,
 					TimestampUtc: ev.Timestamp.UnixMilli(),
+					Replayed:     ev.Replayed,
+					MessageId:    ev.ID,
+					Sequence:     ev.Sequence,
+					EditedAtUtc:  optionalUnixMilli(ev.EditedAt),
+					Deleted:      ev.Deleted,
+					Reactions:    reactionsToProto(ev.Reactions),
+					ReplyTo:      ev.ReplyTo,
+					ReplyCount:   uint32(ev.ReplyCount),
+				},
+			},
+		}
+	case domain.EventMessageEdited:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Edited{
+				Edited: &chatv1.MessageEdited{
+					MessageId:   ev.MessageID,
+					Content:     ev.Content,
+					UserId:      ev.UserID,
+					EditedAtUtc: ev.Timestamp.UnixMilli(),
+					EventId:     ev.ID,
+					Sequence:    ev.Sequence,
+				},
+			},
+		}
+	case domain.EventMessageDeleted:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Deleted{
+				Deleted: &chatv1.MessageDeleted{
+					MessageId:    ev.MessageID,
+					UserId:       ev.UserID,
+					DeletedAtUtc: ev.Timestamp.UnixMilli(),
+					EventId:      ev.ID,
+					Sequence:     ev.Sequence,
+				},
+			},
+		}
+	case domain.EventReaction:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Reaction{
+				Reaction: &chatv1.ReactionDelta{
+					MessageId: ev.MessageID,
+					Emoji:     ev.Content,
+					UserId:    ev.UserID,
+					Added:     ev.Added,
+					Count:     uint32(ev.Count),
+					EventId:   ev.ID,
+					Sequence:  ev.Sequence,
+				},
+			},
+		}
+	case domain.EventThreadUpdated:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Thread{
+				Thread: &chatv1.ThreadUpdate{
+					MessageId:    ev.MessageID,
+					ReplyCount:   uint32(ev.Count),
+					UserId:       ev.UserID,
+					TimestampUtc: ev.Timestamp.UnixMilli(),
+					EventId:      ev.ID,
+					Sequence:     ev.Sequence,
 				},
 			},
 		}
@@ -214,10 +134,12 @@ func domainEventToProto(ev domain.Event) *chatv1.ServerEvent {
 		return &chatv1.ServerEvent{
 			Event: &chatv1.ServerEvent_Notice{
 				Notice: &chatv1.ServerNotice{
-					Type:    chatv1.ServerNotice_TYPE_USER_JOINED,
-					Message: fmt.Sprintf(noticeJoinedFormat, ev.DisplayName),
-					UserId:  ev.UserID,
-					Room:    ev.RoomID,
+					Type:      chatv1.ServerNotice_TYPE_USER_JOINED,
+					Message:   fmt.Sprintf(noticeJoinedFormat, ev.DisplayName),
+					UserId:    ev.UserID,
+					Room:      ev.RoomID,
+					MessageId: ev.ID,
+					Sequence:  ev.Sequence,
 				},
 			},
 		}
@@ -225,41 +147,314 @@ func domainEventToProto(ev domain.Event) *chatv1.ServerEvent {
 		return &chatv1.ServerEvent{
 			Event: &chatv1.ServerEvent_Notice{
 				Notice: &chatv1.ServerNotice{
-					Type:    chatv1.ServerNotice_TYPE_USER_LEFT,
-					Message: fmt.Sprintf(noticeLeftFormat, ev.DisplayName),
-					UserId:  ev.UserID,
-					Room:    ev.RoomID,
+					Type:      chatv1.ServerNotice_TYPE_USER_LEFT,
+					Message:   leftMessage(ev),
+					Reason:    ev.Content,
+					UserId:    ev.UserID,
+					Room:      ev.RoomID,
+					MessageId: ev.ID,
+					Sequence:  ev.Sequence,
 				},
 			},
 		}
-	case domain.EventSystem:
+	case domain.EventModeration:
 		return &chatv1.ServerEvent{
 			Event: &chatv1.ServerEvent_Notice{
 				Notice: &chatv1.ServerNotice{
-					Type:    chatv1.ServerNotice_TYPE_GENERIC,
+					Type:        chatv1.ServerNotice_TYPE_MODERATION,
+					Message:     moderationMessage(ev),
+					Reason:      ev.Content,
+					UserId:      ev.UserID,
+					Room:        ev.RoomID,
+					MessageId:   ev.ID,
+					Sequence:    ev.Sequence,
+					Action:      moderationActions[ev.Action],
+					ActorUserId: ev.ActorID,
+					UntilUtc:    optionalUnixMilli(ev.Until),
+				},
+			},
+		}
+	case domain.EventRoleChanged:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:        chatv1.ServerNotice_TYPE_ROLE_CHANGED,
+					Message:     fmt.Sprintf(noticeRoleFormat, displayName(ev), roleNames[ev.Role]),
+					UserId:      ev.UserID,
+					Room:        ev.RoomID,
+					MessageId:   ev.ID,
+					Sequence:    ev.Sequence,
+					ActorUserId: ev.ActorID,
+					Role:        roles[ev.Role],
+				},
+			},
+		}
+	case domain.EventTopicChanged:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:      chatv1.ServerNotice_TYPE_TOPIC_CHANGED,
+					Message:   topicMessage(ev),
+					UserId:    ev.UserID,
+					Room:      ev.RoomID,
+					MessageId: ev.ID,
+					Sequence:  ev.Sequence,
+					Topic:     ev.Content,
+				},
+			},
+		}
+	case domain.EventQueuePosition:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:          chatv1.ServerNotice_TYPE_QUEUE_POSITION,
+					Message:       fmt.Sprintf(noticeQueueFormat, ev.Count),
+					UserId:        ev.UserID,
+					Room:          ev.RoomID,
+					QueuePosition: uint32(ev.Count),
+				},
+			},
+		}
+	case domain.EventAdmitted:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Joined{Joined: joinAck(*ev.Session, false)},
+			Room:  ev.RoomID,
+		}
+	case domain.EventMissed:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:         chatv1.ServerNotice_TYPE_EVENTS_MISSED,
+					Message:      fmt.Sprintf(noticeMissedFormat, ev.Missed),
+					Room:         ev.RoomID,
+					MissedEvents: ev.Missed,
+				},
+			},
+		}
+	case domain.EventSessionClosed:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:    chatv1.ServerNotice_TYPE_SESSION_CLOSED,
 					Message: ev.Content,
+					Reason:  ev.Content,
 					UserId:  ev.UserID,
 					Room:    ev.RoomID,
 				},
 			},
 		}
+	case domain.EventDirectMessage:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Direct{
+				Direct: &chatv1.DirectMessage{
+					MessageId:       ev.ID,
+					FromUserId:      ev.UserID,
+					FromDisplayName: ev.DisplayName,
+					ToUserId:        ev.Recipient,
+					Content:         ev.Content,
+					TimestampUtc:    ev.Timestamp.UnixMilli(),
+					Queued:          ev.Replayed,
+				},
+			},
+		}
+	case domain.EventTyping:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Typing{
+				Typing: &chatv1.TypingIndicator{
+					UserId:      ev.UserID,
+					DisplayName: ev.DisplayName,
+					Typing:      ev.Typing,
+				},
+			},
+		}
+	case domain.EventSystem:
+		return &chatv1.ServerEvent{
+			Event: &chatv1.ServerEvent_Notice{
+				Notice: &chatv1.ServerNotice{
+					Type:      chatv1.ServerNotice_TYPE_GENERIC,
+					Message:   ev.Content,
+					UserId:    ev.UserID,
+					Room:      ev.RoomID,
+					MessageId: ev.ID,
+					Sequence:  ev.Sequence,
+				},
+			},
+		}
 	default:
 		return nil
 	}
 }
 
+// joinAck acknowledges a session that joined, resumed or was admitted from the queue.
+func joinAck(session domain.Session, resumed bool) *chatv1.JoinAck {
+	return &chatv1.JoinAck{
+		UserId:         session.UserID,
+		Room:           session.RoomID,
+		WelcomeMessage: fmt.Sprintf(welcomeMessageFormat, session.DisplayName),
+		ResumeToken:    session.ResumeToken,
+		Resumed:        resumed,
+		Role:           roles[session.Role],
+	}
+}
+
+// optionalUnixMilli converts an optional instant, leaving the zero time as zero.
+func optionalUnixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return zeroUnixTimestamp
+	}
+	return t.UnixMilli()
+}
+
+func reactionsToProto(reactions []domain.Reaction) []*chatv1.Reaction {
+	if len(reactions) == 0 {
+		return nil
+	}
+	out := make([]*chatv1.Reaction, 0, len(reactions))
+	for _, r := range reactions {
+		out = append(out, &chatv1.Reaction{
+			Emoji:   r.Emoji,
+			Count:   uint32(len(r.UserIDs)),
+			UserIds: r.UserIDs,
+		})
+	}
+	return out
+}
+
+// leftMessage names the reason when the server removed the user rather than the user leaving.
+func leftMessage(ev domain.Event) string {
+	if ev.Content != "" {
+		return fmt.Sprintf(noticeRemovedFormat, ev.DisplayName, ev.Content)
+	}
+	return fmt.Sprintf(noticeLeftFormat, ev.DisplayName)
+}
+
+var moderationActions = map[domain.ModerationAction]chatv1.ModerationAction{
+	domain.ModerationKick:   chatv1.ModerationAction_MODERATION_ACTION_KICK,
+	domain.ModerationBan:    chatv1.ModerationAction_MODERATION_ACTION_BAN,
+	domain.ModerationUnban:  chatv1.ModerationAction_MODERATION_ACTION_UNBAN,
+	domain.ModerationMute:   chatv1.ModerationAction_MODERATION_ACTION_MUTE,
+	domain.ModerationUnmute: chatv1.ModerationAction_MODERATION_ACTION_UNMUTE,
+}
+
+var moderationFormats = map[domain.ModerationAction]string{
+	domain.ModerationKick:   noticeKickedFormat,
+	domain.ModerationBan:    noticeBannedFormat,
+	domain.ModerationUnban:  noticeUnbannedFormat,
+	domain.ModerationMute:   noticeMutedFormat,
+	domain.ModerationUnmute: noticeUnmutedFormat,
+}
+
+var roles = map[domain.Role]chatv1.Role{
+	domain.RoleReadOnly:  chatv1.Role_ROLE_READ_ONLY,
+	domain.RoleMember:    chatv1.Role_ROLE_MEMBER,
+	domain.RoleModerator: chatv1.Role_ROLE_MODERATOR,
+	domain.RoleOwner:     chatv1.Role_ROLE_OWNER,
+}
+
+var roleNames = map[domain.Role]string{
+	domain.RoleReadOnly:  roleNameReadOnly,
+	domain.RoleMember:    roleNameMember,
+	domain.RoleModerator: roleNameModerator,
+	domain.RoleOwner:     roleNameOwner,
+}
+
+// roleFromProto converts a role; ROLE_UNSPECIFIED yields an invalid role.
+func roleFromProto(role chatv1.Role) domain.Role {
+	for r, p := range roles {
+		if p == role {
+			return r
+		}
+	}
+	return 0
+}
+
+// displayName names the user of an event, falling back to the ID of users who are away.
+func displayName(ev domain.Event) string {
+	if ev.DisplayName == "" {
+		return ev.UserID
+	}
+	return ev.DisplayName
+}
+
+// moderationMessage describes a sanction.
+func moderationMessage(ev domain.Event) string {
+	msg := fmt.Sprintf(moderationFormats[ev.Action], displayName(ev), ev.ActorID)
+	if ev.Content != "" {
+		msg = fmt.Sprintf(noticeReasonFormat, msg, ev.Content)
+	}
+	return msg
+}
+
+// topicMessage describes a topic change.
+func topicMessage(ev domain.Event) string {
+	if ev.Content == "" {
+		return fmt.Sprintf(noticeTopicCleared, displayName(ev))
+	}
+	return fmt.Sprintf(noticeTopicFormat, displayName(ev), ev.Content)
+}
+
 func translateError(err error) error {
 	switch {
 	case errors.Is(err, usecase.ErrEmptyFields):
 		return status.Error(codes.InvalidArgument, err.Error())
 	case errors.Is(err, usecase.ErrEmptyMessage):
 		return status.Error(codes.InvalidArgument, err.Error())
-	case errors.Is(err, usecase.ErrAlreadyJoined):
-		return status.Error(codes.AlreadyExists, err.Error())
 	case errors.Is(err, usecase.ErrRoomNotFound):
 		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, usecase.ErrUserNotInRoom):
 		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrInvalidResumeToken):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrSessionActive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrRecipientOffline):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrDirectQueueFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, usecase.ErrMessageNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrNotMessageAuthor):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidReaction):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrNotModerator):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrSelfModeration):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInvalidDuration):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrUserBanned):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrUserMuted):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrRoomExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, usecase.ErrRoomArchived):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrInvalidVisibility):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrRoomInfoTooLong):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInvalidAccess):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInvalidPassword):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrRoomAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrInvalidInvite):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInviteNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrRoomFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, usecase.ErrInvalidParticipantLimit):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, err.Error())
 	default:
 		return status.Error(codes.Internal, err.Error())
 	}
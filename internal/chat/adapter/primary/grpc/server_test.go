@@ -10,13 +10,18 @@ import (
 
 	chatv1 "github.com/lechitz/chat-grpc/api/proto/chatv1"
 	grpcadapter "github.com/lechitz/chat-grpc/internal/chat/adapter/primary/grpc"
+	"github.com/lechitz/chat-grpc/internal/chat/adapter/secondary/memstore"
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
 	"github.com/lechitz/chat-grpc/internal/chat/core/usecase"
 	"github.com/lechitz/chat-grpc/internal/platform/logger"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 )
 
 const bufSize = 1024 * 1024
@@ -77,8 +82,8 @@ func TestChannel_BasicFlow(t *testing.T) {
 	err = stream.Send(&chatv1.ClientEnvelope{
 		Message: &chatv1.ClientEnvelope_Chat{
 			Chat: &chatv1.ChatPayload{
-				UserId:  "alice",
-				Room:    "general",
+				UserId:  proto.String("alice"),
+				Room:    proto.String("general"),
 				Content: "olá mundo",
 			},
 		},
@@ -99,10 +104,7 @@ func TestChannel_BasicFlow(t *testing.T) {
 
 	err = stream.Send(&chatv1.ClientEnvelope{
 		Message: &chatv1.ClientEnvelope_Leave{
-			Leave: &chatv1.LeaveRequest{
-				UserId: "alice",
-				Room:   "general",
-			},
+			Leave: &chatv1.LeaveRequest{},
 		},
 	})
 	require.NoError(t, err)
@@ -112,6 +114,1097 @@ func TestChannel_BasicFlow(t *testing.T) {
 	require.True(t, errors.Is(err, io.EOF) || errors.Is(err, context.Canceled))
 }
 
+func TestChannel_ReplaysHistoryAfterAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := memstore.New()
+	for i, content := range []string{"first", "second"} {
+		err := store.Append(ctx, domain.Message{
+			ID:       content,
+			RoomID:   "general",
+			UserID:   "bob",
+			Content:  content,
+			Sequence: uint64(i + 1),
+		})
+		require.NoError(t, err)
+	}
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithMessageStore(store)))
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+
+	err = stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{
+			Join: &chatv1.JoinRequest{
+				UserId:       "alice",
+				Room:         "general",
+				HistoryLimit: 5,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, ev.GetJoined())
+
+	for _, want := range []string{"first", "second"} {
+		ev, err = stream.Recv()
+		require.NoError(t, err)
+		msg := ev.GetBroadcast()
+		require.NotNil(t, msg)
+		require.True(t, msg.GetReplayed())
+		require.Equal(t, want, msg.GetContent())
+		require.Equal(t, want, msg.GetMessageId())
+	}
+}
+
+func TestChannel_ResumeAfterDroppedStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithResumeGrace(time.Minute)))
+
+	firstCtx, dropFirst := context.WithCancel(ctx)
+	first, err := client.Channel(firstCtx)
+	require.NoError(t, err)
+	require.NoError(t, first.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{
+			Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"},
+		},
+	}))
+	ev, err := first.Recv()
+	require.NoError(t, err)
+	token := ev.GetJoined().GetResumeToken()
+	require.NotEmpty(t, token)
+
+	dropFirst()
+
+	// The server notices the dropped stream asynchronously; retry until it detached.
+	var ack *chatv1.JoinAck
+	assertWithin(t, time.Second, func() bool {
+		stream, err := client.Channel(ctx)
+		if err != nil {
+			return false
+		}
+		if err := stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Resume{
+				Resume: &chatv1.ResumeRequest{ResumeToken: token},
+			},
+		}); err != nil {
+			return false
+		}
+		ev, err := stream.Recv()
+		if err != nil {
+			return false
+		}
+		ack = ev.GetJoined()
+		return ack != nil
+	})
+	require.True(t, ack.GetResumed())
+	require.Equal(t, "alice", ack.GetUserId())
+	require.NotEqual(t, token, ack.GetResumeToken())
+}
+
+func TestChannel_RejectsEnvelopesForAnotherSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Without a resume grace period every rejected stream leaves the room right away.
+	app := usecase.NewService()
+	_, bobEvents, err := app.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "general"})
+	require.NoError(t, err)
+	client := newTestClient(t, ctx, app)
+
+	for name, env := range map[string]*chatv1.ClientEnvelope{
+		"chat as another user": {Message: &chatv1.ClientEnvelope_Chat{
+			Chat: &chatv1.ChatPayload{UserId: proto.String("bob"), Content: "spoofed"},
+		}},
+		"leave for another user": {Message: &chatv1.ClientEnvelope_Leave{
+			Leave: &chatv1.LeaveRequest{UserId: proto.String("bob"), Room: proto.String("general")},
+		}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stream, err := client.Channel(ctx)
+			require.NoError(t, err)
+			require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+				Message: &chatv1.ClientEnvelope_Join{
+					Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"},
+				},
+			}))
+			ev, err := stream.Recv()
+			require.NoError(t, err)
+			require.NotNil(t, ev.GetJoined())
+
+			require.NoError(t, stream.Send(env))
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+			}
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+
+	// Bob only ever sees alice come and go; nothing was posted or left on his behalf.
+	for range 2 {
+		for _, want := range []domain.EventType{domain.EventUserJoined, domain.EventUserLeft} {
+			select {
+			case ev := <-bobEvents:
+				require.Equal(t, want, ev.Type)
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for event type %v", want)
+			}
+		}
+	}
+}
+
+func TestChannel_RecoverableErrorsKeepStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: ""}},
+	}))
+	notice := recvErrorNotice(t, stream)
+	require.Equal(t, uint32(codes.InvalidArgument), notice.GetErrorCode())
+	require.Equal(t, "chat", notice.GetEnvelope())
+	require.Equal(t, uint64(2), notice.GetEnvelopeIndex())
+	require.NotEmpty(t, notice.GetMessage())
+
+	// A room the stream does not follow, for instance one it was just removed from, only
+	// fails the envelope.
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Room: proto.String("random"), Content: "elsewhere"}},
+	}))
+	notice = recvErrorNotice(t, stream)
+	require.Equal(t, uint32(codes.FailedPrecondition), notice.GetErrorCode())
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "still here"}},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "still here", ev.GetBroadcast().GetContent())
+
+	// An envelope without a payload violates the protocol and ends the stream.
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{}))
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestChannel_AcknowledgesRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// The retry is acknowledged with the original message and not broadcast again.
+	var acks []*chatv1.SendAck
+	broadcasts := 0
+	for range 2 {
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			RequestId: "r-1",
+			Message:   &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "once"}},
+		}))
+	}
+	for len(acks) < 2 || broadcasts < 1 {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		if ack := ev.GetAck(); ack != nil {
+			acks = append(acks, ack)
+		}
+		if ev.GetBroadcast() != nil {
+			broadcasts++
+		}
+	}
+	require.Equal(t, 1, broadcasts)
+	require.Equal(t, "r-1", acks[0].GetRequestId())
+	require.NotEmpty(t, acks[0].GetMessageId())
+	require.Equal(t, uint64(2), acks[0].GetSequence())
+	require.Equal(t, acks[0].GetMessageId(), acks[1].GetMessageId())
+	require.Zero(t, acks[0].GetErrorCode())
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		RequestId: "r-2",
+		Message:   &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: ""}},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "r-2", ev.GetAck().GetRequestId())
+	require.Equal(t, uint32(codes.InvalidArgument), ev.GetAck().GetErrorCode())
+	require.Empty(t, ev.GetAck().GetMessageId())
+
+	require.NoError(t, stream.CloseSend())
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		require.Nil(t, ev.GetBroadcast(), "the retry must not be broadcast")
+	}
+}
+
+func TestChannel_EditAndDeleteMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := memstore.New()
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithMessageStore(store)))
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "helo"}},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	messageID := ev.GetBroadcast().GetMessageId()
+	require.NotEmpty(t, messageID)
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Edit{Edit: &chatv1.EditMessageRequest{MessageId: messageID, Content: "hello"}},
+	}))
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	edited := ev.GetEdited()
+	require.NotNil(t, edited)
+	require.Equal(t, messageID, edited.GetMessageId())
+	require.Equal(t, "hello", edited.GetContent())
+	require.Equal(t, "alice", edited.GetUserId())
+	require.Equal(t, uint64(3), edited.GetSequence())
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Delete{Delete: &chatv1.DeleteMessageRequest{MessageId: messageID}},
+	}))
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, messageID, ev.GetDeleted().GetMessageId())
+	require.Equal(t, "general", ev.GetRoom())
+
+	stored, found, err := store.Get(ctx, "general", messageID)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.True(t, stored.Deleted)
+
+	// Tombstones cannot be edited; the failure is reported without ending the stream.
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Edit{Edit: &chatv1.EditMessageRequest{MessageId: messageID, Content: "again"}},
+	}))
+	notice := recvErrorNotice(t, stream)
+	require.Equal(t, uint32(codes.NotFound), notice.GetErrorCode())
+	require.Equal(t, "edit", notice.GetEnvelope())
+}
+
+func TestChannel_ReactionsAreBroadcastAndReplayed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithMessageStore(memstore.New())))
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "ship it?"}},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	messageID := ev.GetBroadcast().GetMessageId()
+
+	for _, emoji := range []string{"👍", "🎉"} {
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_React{React: &chatv1.ReactionRequest{MessageId: messageID, Emoji: emoji}},
+		}))
+		ev, err = stream.Recv()
+		require.NoError(t, err)
+		delta := ev.GetReaction()
+		require.NotNil(t, delta)
+		require.Equal(t, messageID, delta.GetMessageId())
+		require.Equal(t, emoji, delta.GetEmoji())
+		require.True(t, delta.GetAdded())
+		require.Equal(t, uint32(1), delta.GetCount())
+	}
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Unreact{Unreact: &chatv1.ReactionRequest{MessageId: messageID, Emoji: "🎉"}},
+	}))
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	require.False(t, ev.GetReaction().GetAdded())
+	require.Zero(t, ev.GetReaction().GetCount())
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_React{React: &chatv1.ReactionRequest{MessageId: messageID}},
+	}))
+	notice := recvErrorNotice(t, stream)
+	require.Equal(t, uint32(codes.InvalidArgument), notice.GetErrorCode())
+	require.Equal(t, "react", notice.GetEnvelope())
+
+	late, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, late.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "bob", Room: "general", HistoryLimit: 10}},
+	}))
+	_, err = late.Recv()
+	require.NoError(t, err)
+	ev, err = late.Recv()
+	require.NoError(t, err)
+	replayed := ev.GetBroadcast()
+	require.True(t, replayed.GetReplayed())
+	require.Len(t, replayed.GetReactions(), 1)
+	require.Equal(t, "👍", replayed.GetReactions()[0].GetEmoji())
+	require.Equal(t, uint32(1), replayed.GetReactions()[0].GetCount())
+	require.Equal(t, []string{"alice"}, replayed.GetReactions()[0].GetUserIds())
+}
+
+func TestChannel_FollowsSeveralRooms(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := usecase.NewService()
+	client := newTestClient(t, ctx, app)
+
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+
+	recv := func() *chatv1.ServerEvent {
+		t.Helper()
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		return ev
+	}
+
+	for _, room := range []string{"general", "random"} {
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{
+				Join: &chatv1.JoinRequest{UserId: "alice", Room: room},
+			},
+		}))
+		ack := recv()
+		require.Equal(t, room, ack.GetRoom())
+		require.Equal(t, room, ack.GetJoined().GetRoom())
+	}
+
+	for _, room := range []string{"random", "general"} {
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Room: proto.String(room), Content: "hi " + room}},
+		}))
+		ev := recv()
+		require.Equal(t, room, ev.GetRoom())
+		require.Equal(t, "hi "+room, ev.GetBroadcast().GetContent())
+	}
+
+	// Other users' events reach the stream tagged with their room.
+	_, bobEvents, err := app.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "random"})
+	require.NoError(t, err)
+	ev := recv()
+	require.Equal(t, "random", ev.GetRoom())
+	require.Equal(t, chatv1.ServerNotice_TYPE_USER_JOINED, ev.GetNotice().GetType())
+
+	// Leaving one room keeps the stream open for the other.
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{Room: proto.String("random")}},
+	}))
+	select {
+	case ev := <-bobEvents:
+		require.Equal(t, domain.EventUserLeft, ev.Type)
+		require.Equal(t, "alice", ev.UserID)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for alice to leave random")
+	}
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "only general now"}},
+	}))
+	ev = recv()
+	require.Equal(t, "general", ev.GetRoom())
+	require.Equal(t, "only general now", ev.GetBroadcast().GetContent())
+
+	// Leaving the last room ends the stream.
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{}},
+	}))
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+
+	page, err := app.ListRooms(ctx, domain.ListRoomsRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Rooms, 1)
+	require.Equal(t, "random", page.Rooms[0].RoomID)
+}
+
+func TestChannel_RequiresRoomWhenFollowingSeveral(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+
+	for name, tc := range map[string]struct {
+		env   *chatv1.ClientEnvelope
+		code  codes.Code
+		fatal bool
+	}{
+		"chat without a room": {
+			env:  &chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "where?"}}},
+			code: codes.InvalidArgument,
+		},
+		"room joined twice": {
+			env:  &chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{Room: "general"}}},
+			code: codes.FailedPrecondition,
+		},
+		"join as another user": {
+			env:   &chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "bob", Room: "lobby"}}},
+			code:  codes.PermissionDenied,
+			fatal: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stream, err := client.Channel(ctx)
+			require.NoError(t, err)
+			for _, room := range []string{"general", "random"} {
+				require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+					Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: room}},
+				}))
+				ev, err := stream.Recv()
+				require.NoError(t, err)
+				require.NotNil(t, ev.GetJoined())
+			}
+
+			require.NoError(t, stream.Send(tc.env))
+			if tc.fatal {
+				for {
+					_, err = stream.Recv()
+					if err != nil {
+						break
+					}
+				}
+				require.Equal(t, tc.code, status.Code(err))
+				return
+			}
+
+			notice := recvErrorNotice(t, stream)
+			require.Equal(t, uint32(tc.code), notice.GetErrorCode())
+			require.Equal(t, uint64(3), notice.GetEnvelopeIndex())
+			require.NoError(t, stream.CloseSend())
+		})
+	}
+}
+
+func TestChannel_SameUserOnSeveralStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := usecase.NewService()
+	_, bobEvents, err := app.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "general"})
+	require.NoError(t, err)
+	client := newTestClient(t, ctx, app)
+
+	devices := make([]chatv1.ChatService_ChannelClient, 2)
+	for i := range devices {
+		stream, err := client.Channel(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, ev.GetJoined())
+		devices[i] = stream
+	}
+
+	require.NoError(t, devices[0].Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "from the laptop"}},
+	}))
+	for _, stream := range devices {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "from the laptop", ev.GetBroadcast().GetContent())
+	}
+
+	// Closing the first device only ends its own stream; bob sees alice leave with the last one.
+	for _, stream := range devices {
+		require.NoError(t, stream.CloseSend())
+		_, err := stream.Recv()
+		require.Equal(t, io.EOF, err)
+	}
+
+	for _, want := range []domain.EventType{domain.EventUserJoined, domain.EventMessage, domain.EventUserLeft} {
+		select {
+		case ev := <-bobEvents:
+			require.Equal(t, want, ev.Type)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event type %v", want)
+		}
+	}
+	select {
+	case ev := <-bobEvents:
+		t.Fatalf("unexpected event %v", ev.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestChannel_DisconnectRightAfterLeave(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := usecase.NewService(usecase.WithResumeGrace(time.Minute))
+	_, bobEvents, err := app.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "general"})
+	require.NoError(t, err)
+	client := newTestClient(t, ctx, app)
+
+	// The client goes away while its Leave may still be on the way to the handler; either
+	// way the handler must end and the room must see alice leave, without waiting for the
+	// resume grace period.
+	for range 20 {
+		streamCtx, disconnect := context.WithCancel(ctx)
+		stream, err := client.Channel(streamCtx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, ev.GetJoined())
+
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{}},
+		}))
+		require.NoError(t, stream.CloseSend())
+		disconnect()
+
+		for _, want := range []domain.EventType{domain.EventUserJoined, domain.EventUserLeft} {
+			select {
+			case ev := <-bobEvents:
+				require.Equal(t, want, ev.Type)
+			case <-time.After(time.Second):
+				t.Fatalf("timed out waiting for event type %v", want)
+			}
+		}
+	}
+}
+
+func TestChannel_DirectMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+
+	open := func(userID, room string) chatv1.ChatService_ChannelClient {
+		stream, err := client.Channel(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: userID, DisplayName: userID + "!", Room: room}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.NotNil(t, ev.GetJoined())
+		return stream
+	}
+	alice := open("alice", "general")
+	bob := open("bob", "random")
+
+	require.NoError(t, bob.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Direct{Direct: &chatv1.DirectMessageRequest{ToUserId: "alice", Content: "psst"}},
+	}))
+	ev, err := alice.Recv()
+	require.NoError(t, err)
+	require.Empty(t, ev.GetRoom())
+	dm := ev.GetDirect()
+	require.NotNil(t, dm)
+	require.Equal(t, "bob", dm.GetFromUserId())
+	require.Equal(t, "bob!", dm.GetFromDisplayName())
+	require.Equal(t, "alice", dm.GetToUserId())
+	require.Equal(t, "psst", dm.GetContent())
+	require.NotEmpty(t, dm.GetMessageId())
+
+	// Without a message store, direct messages to offline users are rejected.
+	require.NoError(t, bob.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Direct{Direct: &chatv1.DirectMessageRequest{ToUserId: "carol", Content: "hello?"}},
+	}))
+	notice := recvErrorNotice(t, bob)
+	require.Equal(t, uint32(codes.NotFound), notice.GetErrorCode())
+	require.Equal(t, "direct", notice.GetEnvelope())
+}
+
+func TestChannel_TypingIndicator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := usecase.NewService()
+	_, bobEvents, err := app.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "general"})
+	require.NoError(t, err)
+	client := newTestClient(t, ctx, app)
+
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, app.Typing(ctx, "general", "bob", true))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "general", ev.GetRoom())
+	require.Equal(t, "bob", ev.GetTyping().GetUserId())
+	require.True(t, ev.GetTyping().GetTyping())
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Typing{Typing: &chatv1.TypingRequest{Typing: true}},
+	}))
+	for _, want := range []domain.EventType{domain.EventUserJoined, domain.EventTyping} {
+		select {
+		case ev := <-bobEvents:
+			require.Equal(t, want, ev.Type)
+			require.Equal(t, "alice", ev.UserID)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event type %v", want)
+		}
+	}
+}
+
+func TestChannel_PingKeepsSessionUntilIdle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const idleTimeout = 150 * time.Millisecond
+	app := usecase.NewService(usecase.WithIdleTimeout(idleTimeout))
+	client := newTestClient(t, ctx, app)
+
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// Pinging for longer than the idle timeout keeps the session in the room.
+	for nonce := uint64(1); nonce <= 6; nonce++ {
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Ping{Ping: &chatv1.PingRequest{Nonce: nonce}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, nonce, ev.GetPong().GetNonce())
+		require.NotZero(t, ev.GetPong().GetTimestampUtc())
+		time.Sleep(idleTimeout / 3)
+	}
+
+	// Once the client goes quiet the server evicts the session and says why.
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	notice := ev.GetNotice()
+	require.Equal(t, chatv1.ServerNotice_TYPE_SESSION_CLOSED, notice.GetType())
+	require.NotEmpty(t, notice.GetReason())
+
+	_, err = stream.Recv()
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = app.GetRoom(ctx, "general", "")
+	require.ErrorIs(t, err, usecase.ErrRoomNotFound)
+}
+
+func TestListRoomsAndGetRoom(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := usecase.NewService()
+	for _, room := range []string{"general", "random"} {
+		_, _, err := app.Join(ctx, domain.JoinRequest{UserID: "alice", DisplayName: "Alice", RoomID: room})
+		require.NoError(t, err)
+	}
+	client := newTestClient(t, ctx, app)
+
+	list, err := client.ListRooms(ctx, &chatv1.ListRoomsRequest{NamePrefix: "gen"})
+	require.NoError(t, err)
+	require.Len(t, list.GetRooms(), 1)
+	require.Equal(t, "general", list.GetRooms()[0].GetRoom())
+	require.Equal(t, uint32(1), list.GetRooms()[0].GetParticipantCount())
+
+	room, err := client.GetRoom(ctx, &chatv1.GetRoomRequest{Room: "random"})
+	require.NoError(t, err)
+	require.Len(t, room.GetParticipants(), 1)
+	require.Equal(t, "Alice", room.GetParticipants()[0].GetDisplayName())
+	require.NotZero(t, room.GetParticipants()[0].GetJoinedAtUtc())
+
+	_, err = client.GetRoom(ctx, &chatv1.GetRoomRequest{Room: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.ListRooms(ctx, &chatv1.ListRoomsRequest{PageToken: "%%%"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestChannel_RepliesFormThreads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithMessageStore(memstore.New())))
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "alice", Room: "general"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "deploy at 5?"}},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	rootID := ev.GetBroadcast().GetMessageId()
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "sure", ReplyTo: rootID}},
+	}))
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, rootID, ev.GetBroadcast().GetReplyTo())
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	update := ev.GetThread()
+	require.NotNil(t, update)
+	require.Equal(t, rootID, update.GetMessageId())
+	require.Equal(t, uint32(1), update.GetReplyCount())
+	require.Equal(t, "alice", update.GetUserId())
+
+	thread, err := client.GetThread(ctx, &chatv1.GetThreadRequest{Room: "general", MessageId: rootID})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), thread.GetRoot().GetReplyCount())
+	require.Len(t, thread.GetReplies(), 1)
+	require.Equal(t, "sure", thread.GetReplies()[0].GetContent())
+	require.Empty(t, thread.GetNextPageToken())
+
+	_, err = client.GetThread(ctx, &chatv1.GetThreadRequest{Room: "general", MessageId: "missing"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "?", ReplyTo: "missing"}},
+	}))
+	notice := recvErrorNotice(t, stream)
+	require.Equal(t, uint32(codes.NotFound), notice.GetErrorCode())
+}
+
+func TestChannel_ModeratorKicksUser(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithModerators("mod")))
+	join := func(userID string) chatv1.ChatService_ChannelClient {
+		t.Helper()
+		stream, err := client.Channel(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: userID, Room: "general"}},
+		}))
+		_, err = stream.Recv()
+		require.NoError(t, err)
+		return stream
+	}
+	mod := join("mod")
+	bob := join("bob")
+	_, err := mod.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, bob.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Moderate{Moderate: &chatv1.ModerationRequest{
+			UserId: "mod",
+			Action: chatv1.ModerationAction_MODERATION_ACTION_KICK,
+		}},
+	}))
+	notice := recvErrorNotice(t, bob)
+	require.Equal(t, uint32(codes.PermissionDenied), notice.GetErrorCode())
+	require.Equal(t, "moderate", notice.GetEnvelope())
+
+	require.NoError(t, mod.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Moderate{Moderate: &chatv1.ModerationRequest{
+			UserId: "bob",
+			Action: chatv1.ModerationAction_MODERATION_ACTION_BAN,
+		}},
+	}))
+	notice = recvErrorNotice(t, mod)
+	require.Equal(t, uint32(codes.InvalidArgument), notice.GetErrorCode())
+
+	require.NoError(t, mod.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Moderate{Moderate: &chatv1.ModerationRequest{
+			UserId: "bob",
+			Action: chatv1.ModerationAction_MODERATION_ACTION_KICK,
+			Reason: "spam",
+		}},
+	}))
+	for _, stream := range []chatv1.ChatService_ChannelClient{mod, bob} {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		notice := ev.GetNotice()
+		require.Equal(t, chatv1.ServerNotice_TYPE_MODERATION, notice.GetType())
+		require.Equal(t, chatv1.ModerationAction_MODERATION_ACTION_KICK, notice.GetAction())
+		require.Equal(t, "bob", notice.GetUserId())
+		require.Equal(t, "mod", notice.GetActorUserId())
+		require.Equal(t, "spam", notice.GetReason())
+	}
+
+	ev, err := bob.Recv()
+	require.NoError(t, err)
+	require.Equal(t, chatv1.ServerNotice_TYPE_SESSION_CLOSED, ev.GetNotice().GetType())
+	require.Equal(t, "spam", ev.GetNotice().GetReason())
+	_, err = bob.Recv()
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	ev, err = mod.Recv()
+	require.NoError(t, err)
+	require.Equal(t, chatv1.ServerNotice_TYPE_USER_LEFT, ev.GetNotice().GetType())
+}
+
+func TestChannel_RolesGateMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+	join := func(userID string, role chatv1.Role) chatv1.ChatService_ChannelClient {
+		t.Helper()
+		stream, err := client.Channel(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: userID, Room: "general"}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, role, ev.GetJoined().GetRole())
+		return stream
+	}
+	alice := join("alice", chatv1.Role_ROLE_OWNER)
+	bob := join("bob", chatv1.Role_ROLE_MEMBER)
+	_, err := alice.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, alice.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_SetRole{SetRole: &chatv1.SetRoleRequest{UserId: "bob", Role: chatv1.Role_ROLE_READ_ONLY}},
+	}))
+	for _, stream := range []chatv1.ChatService_ChannelClient{alice, bob} {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		notice := ev.GetNotice()
+		require.Equal(t, chatv1.ServerNotice_TYPE_ROLE_CHANGED, notice.GetType())
+		require.Equal(t, "bob", notice.GetUserId())
+		require.Equal(t, "alice", notice.GetActorUserId())
+		require.Equal(t, chatv1.Role_ROLE_READ_ONLY, notice.GetRole())
+	}
+
+	require.NoError(t, bob.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "hello?"}},
+	}))
+	notice := recvErrorNotice(t, bob)
+	require.Equal(t, uint32(codes.PermissionDenied), notice.GetErrorCode())
+
+	require.NoError(t, bob.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_SetRole{SetRole: &chatv1.SetRoleRequest{UserId: "alice"}},
+	}))
+	notice = recvErrorNotice(t, bob)
+	require.Equal(t, uint32(codes.InvalidArgument), notice.GetErrorCode())
+}
+
+func TestRoomLifecycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+
+	created, err := client.CreateRoom(ctx, &chatv1.CreateRoomRequest{Room: "design", UserId: "alice", Title: "Design"})
+	require.NoError(t, err)
+	require.Equal(t, "alice", created.GetRoom().GetCreatedBy())
+	require.Equal(t, chatv1.Visibility_VISIBILITY_PUBLIC, created.GetRoom().GetVisibility())
+	require.True(t, created.GetRoom().GetPersistent())
+	_, err = client.CreateRoom(ctx, &chatv1.CreateRoomRequest{Room: "design", UserId: "bob"})
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "bob", Room: "design"}},
+	}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	_, err = client.UpdateRoom(ctx, &chatv1.UpdateRoomRequest{Room: "design", UserId: "bob", Topic: proto.String("mockups")})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	updated, err := client.UpdateRoom(ctx, &chatv1.UpdateRoomRequest{Room: "design", UserId: "alice", Topic: proto.String("mockups")})
+	require.NoError(t, err)
+	require.Equal(t, "mockups", updated.GetRoom().GetTopic())
+	require.Equal(t, "Design", updated.GetRoom().GetTitle())
+
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	notice := ev.GetNotice()
+	require.Equal(t, chatv1.ServerNotice_TYPE_TOPIC_CHANGED, notice.GetType())
+	require.Equal(t, "alice", notice.GetUserId())
+	require.Equal(t, "mockups", notice.GetTopic())
+
+	_, err = client.ArchiveRoom(ctx, &chatv1.ArchiveRoomRequest{Room: "design", UserId: "alice"})
+	require.NoError(t, err)
+	ev, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, chatv1.ServerNotice_TYPE_SESSION_CLOSED, ev.GetNotice().GetType())
+	_, err = stream.Recv()
+	require.Equal(t, codes.Aborted, status.Code(err))
+
+	room, err := client.GetRoom(ctx, &chatv1.GetRoomRequest{Room: "design"})
+	require.NoError(t, err)
+	require.True(t, room.GetRoom().GetArchived())
+	_, err = client.ArchiveRoom(ctx, &chatv1.ArchiveRoomRequest{Room: "design", UserId: "alice"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestChannel_PrivateRoomRequiresInvite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService())
+	_, err := client.CreateRoom(ctx, &chatv1.CreateRoomRequest{
+		Room: "secret", UserId: "alice", Access: chatv1.AccessPolicy_ACCESS_POLICY_INVITE_ONLY,
+	})
+	require.NoError(t, err)
+
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	join := func(token string) *chatv1.ServerEvent {
+		t.Helper()
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: "bob", Room: "secret", InviteToken: token}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		return ev
+	}
+
+	// The rejection leaves the stream open for another attempt.
+	notice := join("").GetNotice()
+	require.Equal(t, chatv1.ServerNotice_TYPE_ERROR, notice.GetType())
+	require.Equal(t, uint32(codes.PermissionDenied), notice.GetErrorCode())
+
+	_, err = client.CreateInvite(ctx, &chatv1.CreateInviteRequest{Room: "secret", UserId: "bob"})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	resp, err := client.CreateInvite(ctx, &chatv1.CreateInviteRequest{Room: "secret", UserId: "alice", TtlSeconds: 60, MaxUses: 1})
+	require.NoError(t, err)
+	invite := resp.GetInvite()
+	require.NotEmpty(t, invite.GetToken())
+	require.Equal(t, uint32(1), invite.GetMaxUses())
+	require.NotZero(t, invite.GetExpiresAtUtc())
+
+	require.Equal(t, "secret", join(invite.GetToken()).GetJoined().GetRoom())
+
+	_, err = client.RevokeInvite(ctx, &chatv1.RevokeInviteRequest{Room: "secret", UserId: "alice", Token: invite.GetToken()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestChannel_FullRoomQueuesJoins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, ctx, usecase.NewService(usecase.WithRoomParticipantLimit(1), usecase.WithJoinQueue(1)))
+	join := func(userID string, wait bool) (chatv1.ChatService_ChannelClient, *chatv1.ServerEvent) {
+		t.Helper()
+		stream, err := client.Channel(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+			Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: userID, Room: "stage", WaitForSeat: wait}},
+		}))
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		return stream, ev
+	}
+
+	alice, ev := join("alice", false)
+	require.NotNil(t, ev.GetJoined())
+
+	_, ev = join("bob", false)
+	require.Equal(t, chatv1.ServerNotice_TYPE_ERROR, ev.GetNotice().GetType())
+	require.Equal(t, uint32(codes.ResourceExhausted), ev.GetNotice().GetErrorCode())
+
+	carol, ev := join("carol", true)
+	notice := ev.GetNotice()
+	require.Equal(t, chatv1.ServerNotice_TYPE_QUEUE_POSITION, notice.GetType())
+	require.Equal(t, uint32(1), notice.GetQueuePosition())
+
+	// The seat alice frees is acknowledged to carol as a regular join.
+	require.NoError(t, alice.CloseSend())
+	ev, err := carol.Recv()
+	require.NoError(t, err)
+	joined := ev.GetJoined()
+	require.NotNil(t, joined)
+	require.Equal(t, "stage", joined.GetRoom())
+	require.NotEmpty(t, joined.GetResumeToken())
+}
+
+func newTestClient(t *testing.T, ctx context.Context, app *usecase.Service) chatv1.ChatServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(bufSize)
+	srv := grpc.NewServer()
+	t.Cleanup(srv.Stop)
+
+	chatv1.RegisterChatServiceServer(srv, grpcadapter.NewServer(app, logger.NoopLogger{}))
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(
+		ctx,
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return chatv1.NewChatServiceClient(conn)
+}
+
+// recvErrorNotice reads events until a TYPE_ERROR notice, failing if the stream ends first.
+func recvErrorNotice(t *testing.T, stream chatv1.ChatService_ChannelClient) *chatv1.ServerNotice {
+	t.Helper()
+	for {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		if notice := ev.GetNotice(); notice.GetType() == chatv1.ServerNotice_TYPE_ERROR {
+			return notice
+		}
+	}
+}
+
 func assertWithin(t *testing.T, timeout time.Duration, fn func() bool) {
 	t.Helper()
 	expire := time.Now().Add(timeout)
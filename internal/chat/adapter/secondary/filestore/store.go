@@ -0,0 +1,414 @@
+// Package filestore provides an embedded, file-backed implementation of the MessageStore and
+// DirectMessageStore ports.
+//
+// Messages are appended to a single JSON Lines file and indexed in memory when the store
+// is opened, so reads never touch the disk. Every record is synced to disk before the write
+// returns, and a final record left partially written by a crash is cut from the file on
+// open. The index may keep only the latest messages of each room; the file keeps them all. Edits and deletions append the new version of
+// the message, which replaces the earlier one when the file is loaded. Queued direct
+// messages live in the same file: taking a user's queue appends a marker that discards the
+// messages queued before it, and expiring it one that discards those sent before a cutoff.
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/adapter/secondary/memstore"
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/lechitz/chat-grpc/internal/chat/core/ports/output"
+)
+
+const (
+	filePerm = 0o600
+	dirPerm  = 0o750
+
+	errFmtCreateDir    = "create store directory: %w"
+	errFmtOpenFile     = "open store file: %w"
+	errFmtDecodeRecord = "decode store record at line %d: %w"
+	errFmtReadFile     = "read store file: %w"
+	errFmtEncodeRecord = "encode store record: %w"
+	errFmtWriteRecord  = "write store record: %w"
+	errFmtRepairFile   = "repair store file: %w"
+	errFmtSyncFile     = "sync store file: %w"
+
+	kindReplace      = "replace"
+	kindDirect       = "direct"
+	kindDirectTaken  = "direct_taken"
+	kindDirectExpiry = "direct_expired"
+)
+
+// record is the on-disk representation of a message. Room messages have no kind, and their
+// edited or deleted versions are replace records; direct messages and the markers of taken
+// or expired queues set Kind and ToUserID, and expiry markers keep their cutoff in SentAt.
+type record struct {
+	Kind        string    `json:"kind,omitempty"`
+	ID          string    `json:"id"`
+	RoomID      string    `json:"room_id"`
+	Sequence    uint64    `json:"seq"`
+	UserID      string    `json:"user_id"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Content     string    `json:"content"`
+	SentAt      time.Time `json:"sent_at"`
+	ToUserID    string    `json:"to_user_id,omitempty"`
+	// EditedAt is a pointer so unedited messages omit it.
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+	Deleted  bool       `json:"deleted,omitempty"`
+	// Reactions is the full reaction state as of this version of the message.
+	Reactions  []reactionRecord `json:"reactions,omitempty"`
+	ReplyTo    string           `json:"reply_to,omitempty"`
+	ReplyCount int              `json:"reply_count,omitempty"`
+}
+
+// reactionRecord is the on-disk representation of the users reacting with one emoji.
+type reactionRecord struct {
+	Emoji   string   `json:"emoji"`
+	UserIDs []string `json:"user_ids"`
+}
+
+// Store appends messages to a JSON Lines file and serves reads from an in-memory index.
+type Store struct {
+	mu    sync.Mutex
+	file  *os.File
+	index *memstore.Store
+	// truncated is how many bytes of a partially written final record Open cut from the file.
+	truncated int64
+}
+
+var (
+	_ output.MessageStore       = (*Store)(nil)
+	_ output.DirectMessageStore = (*Store)(nil)
+)
+
+// Open loads the messages persisted at path, creating the file when it does not exist. The
+// options configure the in-memory index, such as how many messages of each room it keeps.
+func Open(path string, opts ...memstore.Option) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, fmt.Errorf(errFmtCreateDir, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtOpenFile, err)
+	}
+
+	store := &Store{
+		file:  file,
+		index: memstore.New(opts...),
+	}
+	if err := store.load(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Append writes the message to disk before making it visible to readers.
+func (s *Store) Append(ctx context.Context, msg domain.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, err := s.index.LastSequence(ctx, msg.RoomID)
+	if err != nil {
+		return err
+	}
+	if msg.Sequence <= last {
+		return memstore.ErrSequenceOutOfOrder
+	}
+
+	if err := s.writeLocked(toRecord(msg)); err != nil {
+		return err
+	}
+	return s.index.Append(ctx, msg)
+}
+
+// Get returns the message of a room with the given ID.
+func (s *Store) Get(ctx context.Context, roomID, messageID string) (domain.Message, bool, error) {
+	return s.index.Get(ctx, roomID, messageID)
+}
+
+// Replace writes the new version of the message to disk before making it visible to readers.
+func (s *Store) Replace(ctx context.Context, msg domain.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found, err := s.index.Get(ctx, msg.RoomID, msg.ID); err != nil || !found {
+		if err == nil {
+			err = memstore.ErrMessageNotFound
+		}
+		return err
+	}
+
+	rec := toRecord(msg)
+	rec.Kind = kindReplace
+	if err := s.writeLocked(rec); err != nil {
+		return err
+	}
+	return s.index.Replace(ctx, msg)
+}
+
+// EnqueueDirect writes the direct message to disk before queueing it for its recipient.
+func (s *Store) EnqueueDirect(ctx context.Context, msg domain.DirectMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writeLocked(directToRecord(msg)); err != nil {
+		return err
+	}
+	return s.index.EnqueueDirect(ctx, msg)
+}
+
+// TakeDirect removes and returns the messages queued for a user, oldest first. The removal
+// is recorded on disk so the messages are not queued again when the store is reopened.
+func (s *Store) TakeDirect(ctx context.Context, userID string) ([]domain.DirectMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs, err := s.index.TakeDirect(ctx, userID)
+	if err != nil || len(msgs) == 0 {
+		return msgs, err
+	}
+	if err := s.writeLocked(record{Kind: kindDirectTaken, ToUserID: userID}); err != nil {
+		for _, msg := range msgs {
+			_ = s.index.EnqueueDirect(ctx, msg)
+		}
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// ExpireDirect discards the messages queued for a user that were sent before the cutoff
+// and reports how many remain queued. The expiry is recorded on disk only when it discards
+// messages.
+func (s *Store) ExpireDirect(ctx context.Context, userID string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs, err := s.index.TakeDirect(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	var kept []domain.DirectMessage
+	for _, msg := range msgs {
+		if !msg.SentAt.Before(before) {
+			kept = append(kept, msg)
+		}
+	}
+	if len(kept) < len(msgs) {
+		if err = s.writeLocked(record{Kind: kindDirectExpiry, ToUserID: userID, SentAt: before}); err != nil {
+			kept = msgs
+		}
+	}
+	for _, msg := range kept {
+		_ = s.index.EnqueueDirect(ctx, msg)
+	}
+	return len(kept), err
+}
+
+// Truncated reports how many bytes of a partially written final record were cut from the
+// file when it was opened, or zero when the file was intact.
+func (s *Store) Truncated() int64 {
+	return s.truncated
+}
+
+// LastSequence reports the highest sequence stored for a room, or zero when it is empty.
+func (s *Store) LastSequence(ctx context.Context, roomID string) (uint64, error) {
+	return s.index.LastSequence(ctx, roomID)
+}
+
+// RangeByTime returns messages sent within [from, to).
+func (s *Store) RangeByTime(ctx context.Context, roomID string, from, to time.Time, limit int) ([]domain.Message, error) {
+	return s.index.RangeByTime(ctx, roomID, from, to, limit)
+}
+
+// RangeBySequence returns messages whose sequence lies within [from, to).
+func (s *Store) RangeBySequence(ctx context.Context, roomID string, from, to uint64, limit int) ([]domain.Message, error) {
+	return s.index.RangeBySequence(ctx, roomID, from, to, limit)
+}
+
+// Replies returns the replies to a thread root whose sequence lies within [from, to).
+func (s *Store) Replies(ctx context.Context, roomID, rootID string, from, to uint64, limit int) ([]domain.Message, error) {
+	return s.index.Replies(ctx, roomID, rootID, from, to, limit)
+}
+
+// Latest returns up to limit of the most recent messages of a room.
+func (s *Store) Latest(ctx context.Context, roomID string, limit int) ([]domain.Message, error) {
+	return s.index.Latest(ctx, roomID, limit)
+}
+
+// Close flushes pending writes and releases the underlying file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		_ = s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+func (s *Store) writeLocked(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf(errFmtEncodeRecord, err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf(errFmtWriteRecord, err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf(errFmtSyncFile, err)
+	}
+	return nil
+}
+
+// load replays the file into the index. Records are written whole, so only the last one can
+// be torn by a crash: a final line that does not decode is cut from the file, and a final
+// record that lost its newline gets it back before anything is appended after it.
+func (s *Store) load() error {
+	reader := bufio.NewReader(s.file)
+
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf(errFmtReadFile, err)
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		_, peekErr := reader.Peek(1)
+		last := errors.Is(peekErr, io.EOF)
+
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			var rec record
+			if err := json.Unmarshal(trimmed, &rec); err != nil {
+				if last {
+					return s.truncate(offset, int64(len(data)))
+				}
+				return fmt.Errorf(errFmtDecodeRecord, line, err)
+			}
+			if err := s.replay(rec); err != nil {
+				return fmt.Errorf(errFmtDecodeRecord, line, err)
+			}
+		}
+		offset += int64(len(data))
+
+		if data[len(data)-1] != '\n' {
+			if _, err := s.file.Write([]byte{'\n'}); err != nil {
+				return fmt.Errorf(errFmtRepairFile, err)
+			}
+			return nil
+		}
+	}
+}
+
+// truncate cuts a torn final record of size bytes starting at offset from the file.
+func (s *Store) truncate(offset, size int64) error {
+	if err := s.file.Truncate(offset); err != nil {
+		return fmt.Errorf(errFmtRepairFile, err)
+	}
+	s.truncated = size
+	return nil
+}
+
+// replay applies a record read from disk to the in-memory index.
+func (s *Store) replay(rec record) error {
+	ctx := context.Background()
+	switch rec.Kind {
+	case kindReplace:
+		// The index no longer holds messages that fell out of the room's retention.
+		err := s.index.Replace(ctx, rec.toDomain())
+		if errors.Is(err, memstore.ErrMessageNotFound) {
+			return nil
+		}
+		return err
+	case kindDirect:
+		return s.index.EnqueueDirect(ctx, rec.toDirect())
+	case kindDirectTaken:
+		_, err := s.index.TakeDirect(ctx, rec.ToUserID)
+		return err
+	case kindDirectExpiry:
+		_, err := s.index.ExpireDirect(ctx, rec.ToUserID, rec.SentAt)
+		return err
+	default:
+		return s.index.Append(ctx, rec.toDomain())
+	}
+}
+
+func toRecord(msg domain.Message) record {
+	rec := record{
+		ID:          msg.ID,
+		RoomID:      msg.RoomID,
+		Sequence:    msg.Sequence,
+		UserID:      msg.UserID,
+		DisplayName: msg.DisplayName,
+		Content:     msg.Content,
+		SentAt:      msg.SentAt,
+		Deleted:     msg.Deleted,
+		ReplyTo:     msg.ReplyTo,
+		ReplyCount:  msg.ReplyCount,
+	}
+	if !msg.EditedAt.IsZero() {
+		editedAt := msg.EditedAt
+		rec.EditedAt = &editedAt
+	}
+	for _, r := range msg.Reactions {
+		rec.Reactions = append(rec.Reactions, reactionRecord{Emoji: r.Emoji, UserIDs: r.UserIDs})
+	}
+	return rec
+}
+
+func (r record) toDomain() domain.Message {
+	msg := domain.Message{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		DisplayName: r.DisplayName,
+		RoomID:      r.RoomID,
+		Content:     r.Content,
+		SentAt:      r.SentAt,
+		Sequence:    r.Sequence,
+		Deleted:     r.Deleted,
+		ReplyTo:     r.ReplyTo,
+		ReplyCount:  r.ReplyCount,
+	}
+	if r.EditedAt != nil {
+		msg.EditedAt = *r.EditedAt
+	}
+	for _, rr := range r.Reactions {
+		msg.Reactions = append(msg.Reactions, domain.Reaction{Emoji: rr.Emoji, UserIDs: rr.UserIDs})
+	}
+	return msg
+}
+
+func directToRecord(msg domain.DirectMessage) record {
+	return record{
+		Kind:        kindDirect,
+		ID:          msg.ID,
+		UserID:      msg.FromUserID,
+		DisplayName: msg.FromDisplayName,
+		ToUserID:    msg.ToUserID,
+		Content:     msg.Content,
+		SentAt:      msg.SentAt,
+	}
+}
+
+func (r record) toDirect() domain.DirectMessage {
+	return domain.DirectMessage{
+		ID:              r.ID,
+		FromUserID:      r.UserID,
+		FromDisplayName: r.DisplayName,
+		ToUserID:        r.ToUserID,
+		Content:         r.Content,
+		SentAt:          r.SentAt,
+	}
+}
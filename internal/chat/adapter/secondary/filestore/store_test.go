@@ -0,0 +1,218 @@
+package filestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/adapter/secondary/memstore"
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "messages.jsonl")
+	ctx := context.Background()
+	sentAt := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	first := domain.Message{
+		ID:          "msg-1",
+		UserID:      "alice",
+		DisplayName: "Alice",
+		RoomID:      "room-1",
+		Content:     "hello",
+		SentAt:      sentAt,
+		Sequence:    1,
+	}
+	require.NoError(t, store.Append(ctx, first))
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-2", RoomID: "room-1", UserID: "bob", Content: "hi", SentAt: sentAt.Add(time.Second), Sequence: 3}))
+	require.NoError(t, store.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+
+	msgs, err := reopened.RangeBySequence(ctx, "room-1", 0, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, first, msgs[0])
+
+	last, err := reopened.LastSequence(ctx, "room-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), last)
+
+	err = reopened.Append(ctx, domain.Message{ID: "msg-3", RoomID: "room-1", Sequence: 2})
+	require.ErrorIs(t, err, memstore.ErrSequenceOutOfOrder)
+}
+
+func TestEditsDeletionsAndReactionsSurviveReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	ctx := context.Background()
+	sentAt := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	edited := domain.Message{ID: "msg-1", RoomID: "room-1", UserID: "alice", Content: "helo", SentAt: sentAt, Sequence: 1}
+	deleted := domain.Message{ID: "msg-2", RoomID: "room-1", UserID: "alice", Content: "oops", SentAt: sentAt, Sequence: 2, ReplyTo: "msg-1"}
+	require.NoError(t, store.Append(ctx, edited))
+	require.NoError(t, store.Append(ctx, deleted))
+
+	edited.Content = "hello"
+	edited.EditedAt = sentAt.Add(time.Minute)
+	edited.ReplyCount = 1
+	edited.Reactions = []domain.Reaction{{Emoji: "👍", UserIDs: []string{"bob", "carol"}}}
+	deleted.Content = ""
+	deleted.Deleted = true
+	require.NoError(t, store.Replace(ctx, edited))
+	require.NoError(t, store.Replace(ctx, deleted))
+	require.ErrorIs(t, store.Replace(ctx, domain.Message{ID: "msg-3", RoomID: "room-1"}), memstore.ErrMessageNotFound)
+	require.NoError(t, store.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+
+	msgs, err := reopened.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Message{edited, deleted}, msgs)
+}
+
+func TestRoomRetentionKeepsTheFileWhole(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	ctx := context.Background()
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	first := domain.Message{ID: "msg-1", RoomID: "room-1", Content: "helo", Sequence: 1}
+	require.NoError(t, store.Append(ctx, first))
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-2", RoomID: "room-1", Sequence: 2}))
+	first.Content = "hello"
+	require.NoError(t, store.Replace(ctx, first))
+	require.NoError(t, store.Close())
+
+	// The edit of msg-1 is replayed after msg-2 evicted it from the index.
+	trimmed, err := Open(path, memstore.WithRoomRetention(1))
+	require.NoError(t, err)
+	msgs, err := trimmed.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.Equal(t, "msg-2", msgs[0].ID)
+	require.NoError(t, trimmed.Close())
+
+	whole, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = whole.Close() })
+	msgs, err = whole.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, "hello", msgs[0].Content)
+}
+
+func TestDirectQueueSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	ctx := context.Background()
+	sentAt := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	taken := domain.DirectMessage{ID: "dm-1", FromUserID: "bob", ToUserID: "alice", Content: "seen", SentAt: sentAt}
+	pending := domain.DirectMessage{ID: "dm-2", FromUserID: "bob", FromDisplayName: "Bob", ToUserID: "alice", Content: "unseen", SentAt: sentAt}
+	require.NoError(t, store.EnqueueDirect(ctx, taken))
+	msgs, err := store.TakeDirect(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, []domain.DirectMessage{taken}, msgs)
+	require.NoError(t, store.EnqueueDirect(ctx, pending))
+	expired := domain.DirectMessage{ID: "dm-0", FromUserID: "carol", ToUserID: "dave", Content: "stale", SentAt: sentAt}
+	require.NoError(t, store.EnqueueDirect(ctx, expired))
+	left, err := store.ExpireDirect(ctx, "dave", sentAt.Add(time.Second))
+	require.NoError(t, err)
+	require.Zero(t, left)
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-1", RoomID: "room-1", UserID: "bob", Content: "hi", SentAt: sentAt, Sequence: 1}))
+	require.NoError(t, store.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+
+	msgs, err = reopened.TakeDirect(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, []domain.DirectMessage{pending}, msgs)
+	msgs, err = reopened.TakeDirect(ctx, "dave")
+	require.NoError(t, err)
+	require.Empty(t, msgs, "expired messages stay discarded")
+
+	last, err := reopened.LastSequence(ctx, "room-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), last)
+}
+
+func TestOpenCutsTornFinalRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	ctx := context.Background()
+	sentAt := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	kept := domain.Message{ID: "msg-1", RoomID: "room-1", UserID: "alice", Content: "hello", SentAt: sentAt, Sequence: 1}
+	require.NoError(t, store.Append(ctx, kept))
+	require.NoError(t, store.Close())
+	intact, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// A crash leaves the next record half written.
+	torn := []byte(`{"id":"msg-2","room_id":"room-1","se`)
+	require.NoError(t, os.WriteFile(path, append(intact, torn...), 0o600))
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(torn)), reopened.Truncated())
+	msgs, err := reopened.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Message{kept}, msgs)
+
+	next := domain.Message{ID: "msg-2", RoomID: "room-1", UserID: "bob", Content: "hi", SentAt: sentAt, Sequence: 2}
+	require.NoError(t, reopened.Append(ctx, next))
+	require.NoError(t, reopened.Close())
+
+	reopened, err = Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+	require.Zero(t, reopened.Truncated())
+	msgs, err = reopened.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Message{kept, next}, msgs)
+}
+
+func TestOpenRejectsCorruptRecordBeforeTheEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{oops\n{\"id\":\"msg-1\",\"room_id\":\"room-1\",\"seq\":1}\n"), 0o600))
+
+	_, err := Open(path)
+	require.ErrorContains(t, err, "line 1")
+}
+
+func TestOpenRestoresMissingFinalNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	ctx := context.Background()
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"msg-1","room_id":"room-1","seq":1}`), 0o600))
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	require.Zero(t, store.Truncated())
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-2", RoomID: "room-1", Sequence: 2}))
+	require.NoError(t, store.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reopened.Close() })
+	last, err := reopened.LastSequence(ctx, "room-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), last)
+}
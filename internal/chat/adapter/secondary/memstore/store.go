@@ -0,0 +1,252 @@
+// Package memstore provides an in-memory implementation of the MessageStore and
+// DirectMessageStore ports.
+package memstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/lechitz/chat-grpc/internal/chat/core/ports/output"
+)
+
+// Store keeps the messages in process memory, indexed by room, and the queued direct
+// messages indexed by recipient.
+type Store struct {
+	mu      sync.RWMutex
+	rooms   map[string][]domain.Message
+	directs map[string][]domain.DirectMessage
+	// retention caps the messages kept per room, dropping the oldest; zero keeps them all.
+	retention int
+}
+
+// Option customises the store.
+type Option func(*Store)
+
+// WithRoomRetention keeps only the latest limit messages of each room; zero keeps them all.
+func WithRoomRetention(limit int) Option {
+	return func(s *Store) {
+		if limit >= 0 {
+			s.retention = limit
+		}
+	}
+}
+
+var (
+	_ output.MessageStore       = (*Store)(nil)
+	_ output.DirectMessageStore = (*Store)(nil)
+)
+
+// New returns an empty in-memory store.
+func New(opts ...Option) *Store {
+	s := &Store{
+		rooms:   make(map[string][]domain.Message),
+		directs: make(map[string][]domain.DirectMessage),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var (
+	// ErrSequenceOutOfOrder is returned when a message does not extend its room's sequence.
+	ErrSequenceOutOfOrder = errors.New("memstore: message sequence must increase within a room")
+	// ErrMessageNotFound is returned when replacing a message that was never appended.
+	ErrMessageNotFound = errors.New("memstore: message not found")
+)
+
+// Append stores the message at the end of its room, dropping the oldest message when the
+// room exceeds the retention limit.
+func (s *Store) Append(_ context.Context, msg domain.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg.Sequence <= s.lastSequenceLocked(msg.RoomID) {
+		return ErrSequenceOutOfOrder
+	}
+	msgs := append(s.rooms[msg.RoomID], msg)
+	if s.retention > 0 && len(msgs) > s.retention {
+		drop := len(msgs) - s.retention
+		clear(msgs[:drop])
+		msgs = msgs[drop:]
+	}
+	s.rooms[msg.RoomID] = msgs
+	return nil
+}
+
+// LastSequence reports the highest sequence stored for a room, or zero when it is empty.
+func (s *Store) LastSequence(_ context.Context, roomID string) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastSequenceLocked(roomID), nil
+}
+
+// RangeByTime returns messages sent within [from, to).
+func (s *Store) RangeByTime(_ context.Context, roomID string, from, to time.Time, limit int) ([]domain.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []domain.Message
+	for _, msg := range s.rooms[roomID] {
+		if msg.SentAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !msg.SentAt.Before(to) {
+			continue
+		}
+		out = append(out, msg)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// RangeBySequence returns messages whose sequence lies within [from, to).
+func (s *Store) RangeBySequence(_ context.Context, roomID string, from, to uint64, limit int) ([]domain.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := s.rooms[roomID]
+	start := sort.Search(len(msgs), func(i int) bool { return msgs[i].Sequence >= from })
+
+	var out []domain.Message
+	for _, msg := range msgs[start:] {
+		if to != 0 && msg.Sequence >= to {
+			break
+		}
+		out = append(out, msg)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Replies returns the replies to a thread root whose sequence lies within [from, to).
+// Replies always follow their root, so the scan starts after both from and the root.
+func (s *Store) Replies(_ context.Context, roomID, rootID string, from, to uint64, limit int) ([]domain.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := s.rooms[roomID]
+	start := sort.Search(len(msgs), func(i int) bool { return msgs[i].Sequence >= from })
+	if root := s.indexLocked(roomID, rootID); root >= start {
+		start = root + 1
+	}
+
+	var out []domain.Message
+	for _, msg := range msgs[start:] {
+		if to != 0 && msg.Sequence >= to {
+			break
+		}
+		if msg.ReplyTo != rootID {
+			continue
+		}
+		out = append(out, msg)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Latest returns up to limit of the most recent messages of a room.
+func (s *Store) Latest(_ context.Context, roomID string, limit int) ([]domain.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := s.rooms[roomID]
+	if limit > 0 && len(msgs) > limit {
+		msgs = msgs[len(msgs)-limit:]
+	}
+	return append([]domain.Message(nil), msgs...), nil
+}
+
+// Get returns the message of a room with the given ID. Recent messages are the likeliest to
+// be looked up, so the room is searched from its end.
+func (s *Store) Get(_ context.Context, roomID, messageID string) (domain.Message, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if i := s.indexLocked(roomID, messageID); i >= 0 {
+		return s.rooms[roomID][i], true, nil
+	}
+	return domain.Message{}, false, nil
+}
+
+// Replace overwrites a stored message, keeping its position in the room.
+func (s *Store) Replace(_ context.Context, msg domain.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexLocked(msg.RoomID, msg.ID)
+	if i < 0 {
+		return ErrMessageNotFound
+	}
+	s.rooms[msg.RoomID][i] = msg
+	return nil
+}
+
+// EnqueueDirect keeps the message until its recipient takes it.
+func (s *Store) EnqueueDirect(_ context.Context, msg domain.DirectMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.directs[msg.ToUserID] = append(s.directs[msg.ToUserID], msg)
+	return nil
+}
+
+// TakeDirect removes and returns the messages queued for a user, oldest first.
+func (s *Store) TakeDirect(_ context.Context, userID string) ([]domain.DirectMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := s.directs[userID]
+	delete(s.directs, userID)
+	return msgs, nil
+}
+
+// ExpireDirect discards the messages queued for a user that were sent before the cutoff
+// and reports how many remain queued.
+func (s *Store) ExpireDirect(_ context.Context, userID string, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := s.directs[userID]
+	kept := msgs[:0]
+	for _, msg := range msgs {
+		if !msg.SentAt.Before(before) {
+			kept = append(kept, msg)
+		}
+	}
+	clear(msgs[len(kept):])
+	if len(kept) == 0 {
+		delete(s.directs, userID)
+	} else {
+		s.directs[userID] = kept
+	}
+	return len(kept), nil
+}
+
+func (s *Store) indexLocked(roomID, messageID string) int {
+	msgs := s.rooms[roomID]
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].ID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) lastSequenceLocked(roomID string) uint64 {
+	msgs := s.rooms[roomID]
+	if len(msgs) == 0 {
+		return 0
+	}
+	return msgs[len(msgs)-1].Sequence
+}
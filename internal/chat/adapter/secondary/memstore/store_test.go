@@ -0,0 +1,182 @@
+package memstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendRequiresIncreasingSequencePerRoom(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+
+	require.NoError(t, store.Append(ctx, domain.Message{RoomID: "room-1", Sequence: 1}))
+	require.NoError(t, store.Append(ctx, domain.Message{RoomID: "room-1", Sequence: 4}))
+	require.NoError(t, store.Append(ctx, domain.Message{RoomID: "room-2", Sequence: 1}))
+	require.ErrorIs(t, store.Append(ctx, domain.Message{RoomID: "room-1", Sequence: 4}), ErrSequenceOutOfOrder)
+
+	last, err := store.LastSequence(ctx, "room-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), last)
+
+	last, err = store.LastSequence(ctx, "unknown")
+	require.NoError(t, err)
+	require.Zero(t, last)
+}
+
+func TestRoomRetention(t *testing.T) {
+	store := New(WithRoomRetention(2))
+	ctx := context.Background()
+
+	for seq := range uint64(4) {
+		require.NoError(t, store.Append(ctx, domain.Message{ID: "msg", RoomID: "room-1", Sequence: seq + 1}))
+	}
+	require.NoError(t, store.Append(ctx, domain.Message{RoomID: "room-2", Sequence: 1}))
+
+	msgs, err := store.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4}, sequences(msgs))
+	require.ErrorIs(t, store.Append(ctx, domain.Message{RoomID: "room-1", Sequence: 4}), ErrSequenceOutOfOrder)
+
+	msgs, err = store.Latest(ctx, "room-2", 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, sequences(msgs))
+}
+
+func TestRanges(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+	base := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		require.NoError(t, store.Append(ctx, domain.Message{
+			RoomID:   "room-1",
+			UserID:   "alice",
+			Content:  "msg",
+			SentAt:   base.Add(time.Duration(i) * time.Minute),
+			Sequence: uint64(i + 1),
+		}))
+	}
+
+	bySeq, err := store.RangeBySequence(ctx, "room-1", 2, 4, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, sequences(bySeq))
+
+	open, err := store.RangeBySequence(ctx, "room-1", 3, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4, 5}, sequences(open))
+
+	byTime, err := store.RangeByTime(ctx, "room-1", base.Add(time.Minute), base.Add(4*time.Minute), 2)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 3}, sequences(byTime))
+
+	latest, err := store.Latest(ctx, "room-1", 2)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4, 5}, sequences(latest))
+
+	missing, err := store.RangeByTime(ctx, "unknown", time.Time{}, time.Time{}, 0)
+	require.NoError(t, err)
+	require.Empty(t, missing)
+}
+
+func TestReplies(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+
+	root := domain.Message{ID: "root", RoomID: "room-1", Sequence: 1}
+	require.NoError(t, store.Append(ctx, root))
+	for i, replyTo := range []string{"root", "", "root", "other", "root"} {
+		require.NoError(t, store.Append(ctx, domain.Message{RoomID: "room-1", Sequence: uint64(i + 2), ReplyTo: replyTo}))
+	}
+
+	all, err := store.Replies(ctx, "room-1", "root", 0, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 4, 6}, sequences(all))
+
+	page, err := store.Replies(ctx, "room-1", "root", 3, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4}, sequences(page))
+
+	bounded, err := store.Replies(ctx, "room-1", "root", 0, 6, 0)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 4}, sequences(bounded))
+
+	missing, err := store.Replies(ctx, "room-1", "unknown", 0, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, missing)
+}
+
+func TestDirectQueuePerRecipient(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+
+	require.NoError(t, store.EnqueueDirect(ctx, domain.DirectMessage{ID: "dm-1", ToUserID: "alice"}))
+	require.NoError(t, store.EnqueueDirect(ctx, domain.DirectMessage{ID: "dm-2", ToUserID: "bob"}))
+	require.NoError(t, store.EnqueueDirect(ctx, domain.DirectMessage{ID: "dm-3", ToUserID: "alice"}))
+
+	msgs, err := store.TakeDirect(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, []string{"dm-1", "dm-3"}, []string{msgs[0].ID, msgs[1].ID})
+
+	msgs, err = store.TakeDirect(ctx, "alice")
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+}
+
+func TestExpireDirect(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+	base := time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"dm-1", "dm-2", "dm-3"} {
+		require.NoError(t, store.EnqueueDirect(ctx, domain.DirectMessage{ID: id, ToUserID: "alice", SentAt: base.Add(time.Duration(i) * time.Hour)}))
+	}
+
+	left, err := store.ExpireDirect(ctx, "alice", base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, left)
+	left, err = store.ExpireDirect(ctx, "bob", base)
+	require.NoError(t, err)
+	require.Zero(t, left)
+
+	msgs, err := store.TakeDirect(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, []string{"dm-2", "dm-3"}, []string{msgs[0].ID, msgs[1].ID})
+}
+
+func TestGetAndReplace(t *testing.T) {
+	store := New()
+	ctx := context.Background()
+
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-1", RoomID: "room-1", Content: "helo", Sequence: 1}))
+	require.NoError(t, store.Append(ctx, domain.Message{ID: "msg-2", RoomID: "room-1", Content: "bye", Sequence: 2}))
+
+	msg, found, err := store.Get(ctx, "room-1", "msg-1")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "helo", msg.Content)
+
+	msg.Content = "hello"
+	require.NoError(t, store.Replace(ctx, msg))
+	latest, err := store.Latest(ctx, "room-1", 0)
+	require.NoError(t, err)
+	require.Equal(t, "hello", latest[0].Content)
+	require.Equal(t, []uint64{1, 2}, sequences(latest))
+
+	_, found, err = store.Get(ctx, "room-2", "msg-1")
+	require.NoError(t, err)
+	require.False(t, found)
+	require.ErrorIs(t, store.Replace(ctx, domain.Message{ID: "msg-3", RoomID: "room-1"}), ErrMessageNotFound)
+}
+
+func sequences(msgs []domain.Message) []uint64 {
+	out := make([]uint64, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, msg.Sequence)
+	}
+	return out
+}
@@ -8,25 +8,333 @@ type JoinRequest struct {
 	UserID      string
 	DisplayName string
 	RoomID      string
+	// HistoryLimit requests a replay of up to this many of the room's latest messages.
+	HistoryLimit int
+	// HistorySince requests a replay of messages sent at or after this instant.
+	HistorySince time.Time
+	// Password and InviteToken grant access to rooms that are not public.
+	Password    string
+	InviteToken string
+	// Wait asks to queue for a seat when the room is full instead of being turned away.
+	Wait bool
 }
 
 // Session describes an active connection inside a room.
 type Session struct {
+	// ConnectionID identifies this connection among the user's connections to the room.
+	ConnectionID string
+	UserID       string
+	DisplayName  string
+	RoomID       string
+	JoinedAt     time.Time
+	// ResumeToken lets a dropped connection reattach to the session within the grace period.
+	ResumeToken string
+	// Role is the user's role in the room.
+	Role Role
+	// QueuePosition is set when the join is waiting for a seat in a full room; the session
+	// then only holds the connection ID until an EventAdmitted carries the admitted session.
+	QueuePosition int
+}
+
+// Role ranks the users of a room. Higher roles hold every permission of the lower ones.
+type Role int
+
+const (
+	// RoleReadOnly users follow the room but may not send messages to it.
+	RoleReadOnly Role = iota + 1
+	// RoleMember is the role of users who were not given another one.
+	RoleMember
+	// RoleModerator users may also sanction lower ranked users, change the room's topic,
+	// change or delete the messages of others and assign lower roles.
+	RoleModerator
+	// RoleOwner is held by a single user of the room: its creator, until ownership passes on.
+	// The owner may also change the room's settings and archive it.
+	RoleOwner
+)
+
+// Permission is an operation gated by the user's role in a room.
+type Permission int
+
+const (
+	// PermissionSend allows sending and editing messages.
+	PermissionSend Permission = iota + 1
+	// PermissionModerate allows kicking, banning and muting lower ranked users.
+	PermissionModerate
+	// PermissionSetTopic allows changing the room's topic.
+	PermissionSetTopic
+	// PermissionChangeAny allows editing and deleting the messages of other users.
+	PermissionChangeAny
+	// PermissionAssignRoles allows assigning roles below one's own to lower ranked users.
+	PermissionAssignRoles
+	// PermissionManageRoom allows changing the room's title, description, visibility and
+	// access policy, issuing invites and archiving it.
+	PermissionManageRoom
+)
+
+// Valid reports whether r is one of the defined roles.
+func (r Role) Valid() bool {
+	return r >= RoleReadOnly && r <= RoleOwner
+}
+
+// Allows reports whether the role grants the permission.
+func (r Role) Allows(p Permission) bool {
+	switch p {
+	case PermissionSend:
+		return r >= RoleMember
+	case PermissionModerate, PermissionSetTopic, PermissionChangeAny, PermissionAssignRoles:
+		return r >= RoleModerator
+	case PermissionManageRoom:
+		return r >= RoleOwner
+	default:
+		return false
+	}
+}
+
+// ResumeRequest represents a reconnecting client reattaching to its session.
+type ResumeRequest struct {
+	Token string
+	// LastSequence is the sequence of the last event the client processed.
+	LastSequence uint64
+	// UserID, when set, must own the session; it binds the token to an authenticated user.
+	UserID string
+}
+
+// ListRoomsRequest selects a page of the active rooms, ordered by room ID.
+type ListRoomsRequest struct {
+	// Prefix keeps only the rooms whose ID starts with it.
+	Prefix string
+	// PageSize caps the rooms returned; the service applies a default and a maximum.
+	PageSize int
+	// PageToken resumes the listing after the page that returned it.
+	PageToken string
+}
+
+// Visibility controls whether a room appears in room listings.
+type Visibility int
+
+const (
+	// VisibilityPublic rooms are listed by ListRooms.
+	VisibilityPublic Visibility = iota + 1
+	// VisibilityUnlisted rooms are left out of listings but may still be joined by ID.
+	VisibilityUnlisted
+)
+
+// Valid reports whether v is one of the defined visibilities.
+func (v Visibility) Valid() bool {
+	return v == VisibilityPublic || v == VisibilityUnlisted
+}
+
+// AccessPolicy controls who may join a room.
+type AccessPolicy int
+
+const (
+	// AccessPublic rooms may be joined by anyone who is not banned.
+	AccessPublic AccessPolicy = iota + 1
+	// AccessPassword rooms may be joined with the room's password or an invite.
+	AccessPassword
+	// AccessInviteOnly rooms may only be joined with an invite.
+	AccessInviteOnly
+)
+
+// Valid reports whether a is one of the defined access policies.
+func (a AccessPolicy) Valid() bool {
+	return a >= AccessPublic && a <= AccessInviteOnly
+}
+
+// Restricted reports whether joining takes a password or an invite.
+func (a AccessPolicy) Restricted() bool {
+	return a == AccessPassword || a == AccessInviteOnly
+}
+
+// Invite grants access to a room that is not public.
+type Invite struct {
+	Token     string
+	RoomID    string
+	CreatedBy string
+	CreatedAt time.Time
+	// ExpiresAt is when the invite stops being accepted; zero means it never expires.
+	ExpiresAt time.Time
+	// MaxUses caps how many joins the invite admits; zero means it is unlimited.
+	MaxUses int
+	// Uses counts the joins the invite admitted so far.
+	Uses int
+}
+
+// RoomInfo is the metadata of a room.
+type RoomInfo struct {
+	Title       string
+	Topic       string
+	Description string
+	// CreatedBy is the user who created the room, explicitly or by joining it first.
+	CreatedBy  string
+	CreatedAt  time.Time
+	Visibility Visibility
+	// Access is who may join the room. The password of AccessPassword rooms is never exposed.
+	Access AccessPolicy
+	// MaxParticipants caps how many users may be in the room at once; zero leaves it to the
+	// per-room limit the server applies to every room.
+	MaxParticipants int
+	// Persistent rooms were created explicitly and outlive their participants; the others
+	// are removed once the last participant leaves.
+	Persistent bool
+	// Archived rooms keep their history and settings but can no longer be joined.
+	Archived bool
+}
+
+// CreateRoomRequest represents a user explicitly creating a room, which they will own.
+type CreateRoomRequest struct {
+	RoomID      string
 	UserID      string
-	DisplayName string
+	Title       string
+	Topic       string
+	Description string
+	// Visibility defaults to VisibilityPublic and Access to AccessPublic; AccessPassword
+	// requires a Password.
+	Visibility Visibility
+	Access     AccessPolicy
+	Password   string
+	// MaxParticipants caps the users in the room at once; zero leaves it unlimited.
+	MaxParticipants int
+}
+
+// UpdateRoomRequest changes the metadata of a room on behalf of UserID. Nil fields and a
+// zero Visibility or Access are left unchanged.
+type UpdateRoomRequest struct {
 	RoomID      string
+	UserID      string
+	Title       *string
+	Topic       *string
+	Description *string
+	Visibility  Visibility
+	Access      AccessPolicy
+	// Password replaces the password of an AccessPassword room.
+	Password *string
+	// MaxParticipants replaces the room's participant limit; zero lifts it.
+	MaxParticipants *int
+}
+
+// CreateInviteRequest asks for an invite to a room on behalf of UserID, who must own it.
+type CreateInviteRequest struct {
+	RoomID string
+	UserID string
+	// TTL is how long the invite is accepted; zero issues an invite that never expires.
+	TTL time.Duration
+	// MaxUses caps how many joins the invite admits; zero leaves it unlimited.
+	MaxUses int
+}
+
+// RoomSummary describes a room without its participants.
+type RoomSummary struct {
+	RoomID           string
+	ParticipantCount int
+	// LastSequence is the sequence of the room's latest event.
+	LastSequence uint64
+	RoomInfo
+}
+
+// RoomPage is a page of rooms; NextPageToken is empty on the last page.
+type RoomPage struct {
+	Rooms         []RoomSummary
+	NextPageToken string
+}
+
+// Participant is a member of a room as seen by room queries.
+type Participant struct {
+	UserID      string
+	DisplayName string
 	JoinedAt    time.Time
+	Role        Role
+}
+
+// Room describes an active room and its participants, ordered by user ID.
+type Room struct {
+	RoomSummary
+	Participants []Participant
+}
+
+// ThreadRequest selects a page of the replies to a thread root, oldest first.
+type ThreadRequest struct {
+	RoomID string
+	// UserID is the caller; banned users, and outsiders of restricted rooms, are refused.
+	UserID string
+	// RootID is the ID of the message that started the thread.
+	RootID string
+	// PageSize caps the replies returned; the service applies a default and a maximum.
+	PageSize int
+	// PageToken resumes the listing after the page that returned it.
+	PageToken string
+}
+
+// ThreadPage is the root of a thread and a page of its replies; NextPageToken is empty on
+// the last page.
+type ThreadPage struct {
+	Root          Message
+	Replies       []Message
+	NextPageToken string
 }
 
 // Message is the canonical event broadcast to room participants.
 type Message struct {
+	// ID uniquely identifies the message; it is assigned by the chat service.
+	ID          string
 	UserID      string
 	DisplayName string
 	RoomID      string
 	Content     string
 	SentAt      time.Time
+	// Sequence is the room-scoped position assigned by the chat service.
+	Sequence uint64
+	// EditedAt is when the content was last edited; it is zero for unedited messages.
+	EditedAt time.Time
+	// Deleted marks a tombstone: the message was retracted and its content cleared, but it
+	// keeps its ID and sequence so history stays gap-free.
+	Deleted bool
+	// Reactions lists the emoji users reacted with, in the order they were first used.
+	Reactions []Reaction
+	// ReplyTo is the ID of the thread root a reply belongs to; it is empty for messages
+	// posted to the room itself. Threads are flat: replying to a reply joins its thread.
+	ReplyTo string
+	// ReplyCount is the number of replies posted to a thread root, tombstones included.
+	ReplyCount int
+	// RequestID is the client's identifier of the send; retries reusing it are de-duplicated.
+	// It is not stored.
+	RequestID string
 }
 
+// Reaction is an emoji users reacted to a message with.
+type Reaction struct {
+	Emoji string
+	// UserIDs lists the users who reacted with the emoji, in the order they reacted.
+	UserIDs []string
+}
+
+// DirectMessage is a message addressed to a single user instead of a room.
+type DirectMessage struct {
+	// ID uniquely identifies the message; it is assigned by the chat service.
+	ID              string
+	FromUserID      string
+	FromDisplayName string
+	ToUserID        string
+	Content         string
+	SentAt          time.Time
+}
+
+// ModerationAction is a sanction a moderator applied to a user of a room.
+type ModerationAction int
+
+const (
+	// ModerationKick removed the user from the room; they may join again.
+	ModerationKick ModerationAction = iota + 1
+	// ModerationBan removed the user from the room and keeps them out until the ban expires.
+	ModerationBan
+	// ModerationUnban lifted a ban before it expired.
+	ModerationUnban
+	// ModerationMute keeps the user from sending messages to the room until it expires.
+	ModerationMute
+	// ModerationUnmute lifted a mute before it expired.
+	ModerationUnmute
+)
+
 // EventType categorizes outbound events delivered to participants.
 type EventType int
 
@@ -35,18 +343,93 @@ const (
 	EventMessage EventType = iota
 	// EventUserJoined indicates someone joined the room.
 	EventUserJoined
-	// EventUserLeft indicates someone left the room.
+	// EventUserLeft indicates someone left the room. When the server removed the user,
+	// Content holds the reason.
 	EventUserLeft
 	// EventSystem carries generic notices, typically errors.
 	EventSystem
+	// EventMissed tells a subscriber how many events it lost because it fell behind.
+	EventMissed
+	// EventSessionClosed is the last event of a session the server removed; Content holds the reason.
+	EventSessionClosed
+	// EventDirectMessage carries a direct message; UserID and DisplayName name the sender.
+	EventDirectMessage
+	// EventMessageEdited announces new content for an earlier message: MessageID names it,
+	// Content holds the new text and UserID the user who edited it.
+	EventMessageEdited
+	// EventMessageDeleted announces that an earlier message, named by MessageID, was replaced
+	// by a tombstone; UserID is the user who deleted it.
+	EventMessageDeleted
+	// EventReaction is a change to the reactions of an earlier message: MessageID names it,
+	// Content holds the emoji, UserID the user who reacted, Added whether the reaction was
+	// added or removed and Count how many users now react with the emoji.
+	EventReaction
+	// EventThreadUpdated announces that a thread root, named by MessageID, received a reply:
+	// Count holds its new reply count and UserID the user who replied.
+	EventThreadUpdated
+	// EventModeration announces that a moderator, named by ActorID, applied Action to the
+	// user named by UserID. Content holds the moderator's reason and Until when a ban or mute
+	// expires.
+	EventModeration
+	// EventRoleChanged announces that the user named by UserID now holds Role. ActorID names
+	// the user who assigned it; it is empty when ownership passed on because the owner left.
+	EventRoleChanged
+	// EventTopicChanged announces that the user named by UserID set the room's topic to
+	// Content.
+	EventTopicChanged
+	// EventQueuePosition tells a connection waiting for a seat its place in line, held by
+	// Count. Like EventTyping it has no ID or sequence.
+	EventQueuePosition
+	// EventAdmitted tells a connection that waited for a seat that it joined the room;
+	// Session holds its session.
+	EventAdmitted
+	// EventTyping is an ephemeral signal that a user started or stopped typing. It has no ID
+	// or sequence and is never stored.
+	EventTyping
 )
 
 // Event represents a server-side notification pushed to clients.
+//
+// ID and Sequence are assigned by the chat service to every event tied to a room. Within a
+// room sequences strictly increase and are delivered in order.
 type Event struct {
 	Type        EventType
+	ID          string
+	Sequence    uint64
 	UserID      string
 	DisplayName string
 	RoomID      string
 	Content     string
 	Timestamp   time.Time
+	// Replayed marks messages delivered from history rather than live, and direct messages
+	// that were queued while their recipient was offline.
+	Replayed bool
+	// MessageID is the earlier message an edit, deletion, reaction or thread update refers to.
+	MessageID string
+	// EditedAt, Deleted, Reactions, ReplyTo and ReplyCount mirror the message of an
+	// EventMessage.
+	EditedAt   time.Time
+	Deleted    bool
+	Reactions  []Reaction
+	ReplyTo    string
+	ReplyCount int
+	// Added describes the change of an EventReaction.
+	Added bool
+	// Count is the new count of an EventReaction or EventThreadUpdated, or the position of
+	// an EventQueuePosition.
+	Count int
+	// ActorID, Action and Until describe an EventModeration.
+	ActorID string
+	Action  ModerationAction
+	Until   time.Time
+	// Role is the new role of an EventRoleChanged.
+	Role Role
+	// Recipient is the user an EventDirectMessage is addressed to.
+	Recipient string
+	// Typing reports whether the user started or stopped typing, set on EventTyping.
+	Typing bool
+	// Session is the session granted by an EventAdmitted.
+	Session *Session
+	// Missed is the number of events lost, set on EventMissed.
+	Missed uint64
 }
@@ -0,0 +1,17 @@
+package input
+
+import (
+	"context"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// DirectMessageService delivers messages addressed to a user rather than a room.
+//
+// A user is online while at least one inbox is open for them; every open inbox receives
+// the user's direct messages, whatever rooms its connection follows.
+type DirectMessageService interface {
+	SendDirect(ctx context.Context, msg domain.DirectMessage) error
+	OpenInbox(ctx context.Context, userID string) (string, <-chan domain.Event, error)
+	CloseInbox(ctx context.Context, userID, inboxID string) error
+}
@@ -0,0 +1,39 @@
+package input
+
+import (
+	"context"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// RoomQueryService exposes read-only views of the rooms and their threads.
+type RoomQueryService interface {
+	ListRooms(ctx context.Context, req domain.ListRoomsRequest) (domain.RoomPage, error)
+	// GetRoom returns a room; the participants of restricted rooms are only listed to
+	// their members and owner.
+	GetRoom(ctx context.Context, roomID, userID string) (domain.Room, error)
+	// GetThread returns a thread root and a page of its replies, oldest first.
+	GetThread(ctx context.Context, req domain.ThreadRequest) (domain.ThreadPage, error)
+}
+
+// RoomAdminService manages the lifecycle and metadata of rooms.
+type RoomAdminService interface {
+	// CreateRoom creates a persistent room owned by the requesting user.
+	CreateRoom(ctx context.Context, req domain.CreateRoomRequest) (domain.RoomSummary, error)
+	// UpdateRoom changes the metadata of a room; nil fields are left unchanged.
+	UpdateRoom(ctx context.Context, req domain.UpdateRoomRequest) (domain.RoomSummary, error)
+	// ArchiveRoom closes every session of a room and keeps it from being joined again.
+	ArchiveRoom(ctx context.Context, roomID, userID string) (domain.RoomSummary, error)
+	// CreateInvite issues an invite that admits its holder into a room that is not public.
+	CreateInvite(ctx context.Context, req domain.CreateInviteRequest) (domain.Invite, error)
+	// RevokeInvite withdraws an invite before it expires or runs out of uses.
+	RevokeInvite(ctx context.Context, roomID, userID, token string) error
+}
+
+// ChatService is the full set of chat operations served by the gRPC adapter.
+type ChatService interface {
+	StreamService
+	RoomQueryService
+	RoomAdminService
+	DirectMessageService
+}
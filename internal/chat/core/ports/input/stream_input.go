@@ -3,6 +3,7 @@ package input
 
 import (
 	"context"
+	"time"
 
 	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
 )
@@ -10,6 +11,27 @@ import (
 // StreamService exposes the operations consumed by the gRPC adapter.
 type StreamService interface {
 	Join(ctx context.Context, req domain.JoinRequest) (domain.Session, <-chan domain.Event, error)
-	Leave(ctx context.Context, roomID, userID string) error
-	Broadcast(ctx context.Context, msg domain.Message) error
+	// Leave and Detach act on a single connection, identified by its session's ConnectionID.
+	Leave(ctx context.Context, roomID, connectionID string) error
+	Detach(ctx context.Context, roomID, connectionID string) error
+	Resume(ctx context.Context, req domain.ResumeRequest) (domain.Session, <-chan domain.Event, error)
+	Broadcast(ctx context.Context, msg domain.Message) (domain.Message, error)
+	// EditMessage and DeleteMessage change an earlier message of the room on behalf of
+	// userID, who must be its author or a moderator.
+	EditMessage(ctx context.Context, roomID, userID, messageID, content string) (domain.Message, error)
+	DeleteMessage(ctx context.Context, roomID, userID, messageID string) (domain.Message, error)
+	// Kick removes userID from the room on behalf of the moderator actorID.
+	Kick(ctx context.Context, roomID, actorID, userID, reason string) error
+	// Ban removes userID from the room and keeps them out for duration; zero lifts the ban.
+	Ban(ctx context.Context, roomID, actorID, userID, reason string, duration time.Duration) error
+	// Mute keeps userID from sending messages to the room for duration; zero lifts the mute.
+	Mute(ctx context.Context, roomID, actorID, userID, reason string, duration time.Duration) error
+	// SetRole assigns role to userID in the room on behalf of actorID.
+	SetRole(ctx context.Context, roomID, actorID, userID string, role domain.Role) error
+	// React adds or, when add is false, removes userID's reaction with emoji to a message.
+	React(ctx context.Context, roomID, userID, messageID, emoji string, add bool) (domain.Message, error)
+	Typing(ctx context.Context, roomID, userID string, typing bool) error
+	// Heartbeat records activity on connections, typically all those of a stream, so they
+	// are not evicted as idle.
+	Heartbeat(ctx context.Context, connectionIDs ...string) error
 }
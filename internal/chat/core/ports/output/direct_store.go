@@ -0,0 +1,19 @@
+package output
+
+import (
+	"context"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// DirectMessageStore queues the direct messages of offline users until they connect.
+type DirectMessageStore interface {
+	// EnqueueDirect keeps the message until its recipient takes it.
+	EnqueueDirect(ctx context.Context, msg domain.DirectMessage) error
+	// TakeDirect removes and returns the messages queued for a user, oldest first.
+	TakeDirect(ctx context.Context, userID string) ([]domain.DirectMessage, error)
+	// ExpireDirect discards the messages queued for a user that were sent before the cutoff
+	// and reports how many remain queued.
+	ExpireDirect(ctx context.Context, userID string, before time.Time) (int, error)
+}
@@ -0,0 +1,36 @@
+// Package output defines the secondary (driven) ports required by the chat domain.
+package output
+
+import (
+	"context"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// MessageStore persists room messages so they outlive subscriber channels.
+//
+// Ranges are half-open: a message matches when from <= value < to. A zero upper
+// bound means "no upper bound" and a non-positive limit returns every match.
+// Results are always ordered by sequence, oldest first.
+type MessageStore interface {
+	// Append persists the message. Its ID and sequence are assigned by the caller, which
+	// appends the messages of a room in increasing sequence order.
+	Append(ctx context.Context, msg domain.Message) error
+	// LastSequence reports the highest sequence stored for a room, or zero when it is empty.
+	LastSequence(ctx context.Context, roomID string) (uint64, error)
+	// RangeByTime returns messages of a room sent within [from, to).
+	RangeByTime(ctx context.Context, roomID string, from, to time.Time, limit int) ([]domain.Message, error)
+	// RangeBySequence returns messages of a room whose sequence lies within [from, to).
+	RangeBySequence(ctx context.Context, roomID string, from, to uint64, limit int) ([]domain.Message, error)
+	// Replies returns the replies to a thread root of a room whose sequence lies within
+	// [from, to).
+	Replies(ctx context.Context, roomID, rootID string, from, to uint64, limit int) ([]domain.Message, error)
+	// Latest returns up to limit of the most recent messages of a room.
+	Latest(ctx context.Context, roomID string, limit int) ([]domain.Message, error)
+	// Get returns the message of a room with the given ID; found is false when it is unknown.
+	Get(ctx context.Context, roomID, messageID string) (msg domain.Message, found bool, err error)
+	// Replace overwrites a stored message, matched by room and ID, with an edited or deleted
+	// version of it.
+	Replace(ctx context.Context, msg domain.Message) error
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const passwordSaltBytes = 16
+
+// roomPassword is the salted digest of the password of an AccessPassword room.
+type roomPassword struct {
+	salt   []byte
+	digest []byte
+}
+
+func newRoomPassword(password string) *roomPassword {
+	salt := make([]byte, passwordSaltBytes)
+	_, _ = rand.Read(salt)
+	return &roomPassword{salt: salt, digest: passwordDigest(salt, password)}
+}
+
+func (p *roomPassword) matches(password string) bool {
+	return subtle.ConstantTimeCompare(p.digest, passwordDigest(p.salt, password)) == 1
+}
+
+func passwordDigest(salt []byte, password string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+	return h.Sum(nil)
+}
+
+// CreateInvite issues an invite to a room on behalf of its owner. The token is only
+// returned here; whoever holds it may join the room until it expires or runs out of uses.
+func (s *Service) CreateInvite(_ context.Context, req domain.CreateInviteRequest) (domain.Invite, error) {
+	if req.RoomID == "" || req.UserID == "" {
+		return domain.Invite{}, ErrEmptyFields
+	}
+	if req.TTL < 0 || req.MaxUses < 0 {
+		return domain.Invite{}, ErrInvalidInvite
+	}
+
+	rm, ok := s.lockRoom(req.RoomID, false)
+	if !ok {
+		return domain.Invite{}, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	if rm.info.Archived {
+		return domain.Invite{}, ErrRoomArchived
+	}
+	if err := s.authorizeLocked(rm, req.UserID, domain.PermissionManageRoom); err != nil {
+		return domain.Invite{}, err
+	}
+
+	now := s.clock.Now()
+	invite := &domain.Invite{
+		Token:     s.secrets.NewID(),
+		RoomID:    rm.id,
+		CreatedBy: req.UserID,
+		CreatedAt: now,
+		MaxUses:   req.MaxUses,
+	}
+	if req.TTL > 0 {
+		invite.ExpiresAt = now.Add(req.TTL)
+	}
+	rm.invites[invite.Token] = invite
+	return *invite, nil
+}
+
+// RevokeInvite withdraws an invite on behalf of the room's owner. Users it already admitted
+// keep their access.
+func (s *Service) RevokeInvite(_ context.Context, roomID, userID, token string) error {
+	if roomID == "" || userID == "" || token == "" {
+		return ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	if err := s.authorizeLocked(rm, userID, domain.PermissionManageRoom); err != nil {
+		return err
+	}
+	if _, ok := s.inviteLocked(rm, token); !ok {
+		return ErrInviteNotFound
+	}
+	delete(rm.invites, token)
+	return nil
+}
+
+// admitLocked checks that a user may join the room and returns the invite the join uses,
+// if any. Users already present, moderators and users the room admitted since its access
+// settings last changed need neither a password nor an invite.
+func (s *Service) admitLocked(rm *room, req domain.JoinRequest) (*domain.Invite, error) {
+	if !rm.info.Access.Restricted() || rm.admitted[req.UserID] {
+		return nil, nil
+	}
+	if _, ok := rm.members[req.UserID]; ok || s.roleLocked(rm, req.UserID) >= domain.RoleModerator {
+		return nil, nil
+	}
+	if invite, ok := s.inviteLocked(rm, req.InviteToken); ok {
+		return invite, nil
+	}
+	if rm.info.Access == domain.AccessPassword && req.Password != "" && rm.password.matches(req.Password) {
+		return nil, nil
+	}
+	return nil, ErrRoomAccessDenied
+}
+
+// inviteLocked returns an invite that may still be used, forgetting it once it expired.
+func (s *Service) inviteLocked(rm *room, token string) (*domain.Invite, bool) {
+	invite, ok := rm.invites[token]
+	if !ok {
+		return nil, false
+	}
+	if !invite.ExpiresAt.IsZero() && !s.clock.Now().Before(invite.ExpiresAt) {
+		delete(rm.invites, token)
+		return nil, false
+	}
+	return invite, true
+}
+
+// admitUserLocked records a successful join into a restricted room, spending one use of
+// the invite that admitted it.
+func admitUserLocked(rm *room, userID string, invite *domain.Invite) {
+	if invite != nil {
+		invite.Uses++
+		if invite.MaxUses > 0 && invite.Uses >= invite.MaxUses {
+			delete(rm.invites, invite.Token)
+		}
+	}
+	if rm.info.Access.Restricted() {
+		rm.admitted[userID] = true
+	}
+}
+
+// checkAccess validates access settings: a password is required by, and only accepted for,
+// password-protected rooms. hasPassword reports whether the room already has one.
+func checkAccess(access domain.AccessPolicy, hasPassword bool, password *string) error {
+	if !access.Valid() {
+		return ErrInvalidAccess
+	}
+	if password != nil && (*password == "" || access != domain.AccessPassword) {
+		return ErrInvalidPassword
+	}
+	if access == domain.AccessPassword && password == nil && !hasPassword {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// applyAccessLocked stores the password of a password-protected room and drops it from
+// rooms under another policy. Changing the policy or the password revokes the admissions
+// granted under the previous settings, and a restricted room is made persistent so its
+// policy does not lapse once it empties.
+func applyAccessLocked(rm *room, before domain.AccessPolicy, password *string) {
+	if rm.info.Access != before || password != nil {
+		clear(rm.admitted)
+	}
+	switch {
+	case rm.info.Access != domain.AccessPassword:
+		rm.password = nil
+	case password != nil:
+		rm.password = newRoomPassword(*password)
+	}
+	if rm.info.Access.Restricted() {
+		rm.info.Persistent = true
+	}
+}
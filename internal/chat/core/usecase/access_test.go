@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordProtectedRoom(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithModerators("mod"))
+
+	_, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "vault", UserID: "alice", Access: domain.AccessPassword})
+	require.ErrorIs(t, err, ErrInvalidPassword)
+	_, err = svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "vault", UserID: "alice", Password: "s3cret"})
+	require.ErrorIs(t, err, ErrInvalidPassword)
+	created, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{
+		RoomID: "vault", UserID: "alice", Access: domain.AccessPassword, Password: "s3cret",
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.AccessPassword, created.Access)
+
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault", Password: "guess"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	bob, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault", Password: "s3cret"})
+	require.NoError(t, err)
+
+	// The owner and moderators need no password, and admitted users may come back.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "vault"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "vault"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Leave(ctx, "vault", bob.ConnectionID))
+	bob, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Leave(ctx, "vault", bob.ConnectionID))
+
+	// A new password revokes the earlier admissions.
+	password := "rotated"
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "vault", UserID: "bob", Password: &password})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "vault", UserID: "alice", Password: &password})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault", Password: "s3cret"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "vault", Password: password})
+	require.NoError(t, err)
+
+	// Making the room public drops its password.
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "vault", UserID: "alice", Access: domain.AccessPublic})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "vault"})
+	require.NoError(t, err)
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "vault", UserID: "alice", Access: domain.AccessPassword})
+	require.ErrorIs(t, err, ErrInvalidPassword)
+}
+
+func TestInviteOnlyRoom(t *testing.T) {
+	ctx := context.Background()
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk))
+
+	// A room made private while in use is kept once it empties, so its policy cannot lapse.
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "secret"})
+	require.NoError(t, err)
+	updated, err := svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "secret", UserID: "alice", Access: domain.AccessInviteOnly})
+	require.NoError(t, err)
+	require.True(t, updated.Persistent)
+
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "secret"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "secret", InviteToken: "forged"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+
+	_, err = svc.CreateInvite(ctx, domain.CreateInviteRequest{RoomID: "secret", UserID: "alice", MaxUses: -1})
+	require.ErrorIs(t, err, ErrInvalidInvite)
+	_, err = svc.CreateInvite(ctx, domain.CreateInviteRequest{RoomID: "secret", UserID: "bob"})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	single, err := svc.CreateInvite(ctx, domain.CreateInviteRequest{RoomID: "secret", UserID: "alice", MaxUses: 1})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "secret", InviteToken: single.Token})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "secret", InviteToken: single.Token})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+
+	timed, err := svc.CreateInvite(ctx, domain.CreateInviteRequest{RoomID: "secret", UserID: "alice", TTL: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, clk.t.Add(time.Hour), timed.ExpiresAt)
+	clk.t = clk.t.Add(time.Hour)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "secret", InviteToken: timed.Token})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+
+	open, err := svc.CreateInvite(ctx, domain.CreateInviteRequest{RoomID: "secret", UserID: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, svc.RevokeInvite(ctx, "secret", "alice", open.Token))
+	require.ErrorIs(t, svc.RevokeInvite(ctx, "secret", "alice", open.Token), ErrInviteNotFound)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "secret", InviteToken: open.Token})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+
+	// Only members and the owner see who is inside.
+	room, err := svc.GetRoom(ctx, "secret", "carol")
+	require.NoError(t, err)
+	require.Equal(t, 2, room.ParticipantCount)
+	require.Empty(t, room.Participants)
+	room, err = svc.GetRoom(ctx, "secret", "")
+	require.NoError(t, err)
+	require.Empty(t, room.Participants)
+	room, err = svc.GetRoom(ctx, "secret", "bob")
+	require.NoError(t, err)
+	require.Len(t, room.Participants, 2)
+}
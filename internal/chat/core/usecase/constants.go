@@ -0,0 +1,18 @@
+package usecase
+
+const (
+	errFmtAppendMessage = "append message to store: %w"
+	errFmtLoadHistory   = "load history from store: %w"
+	errFmtLoadSequence  = "load room sequence from store: %w"
+	errFmtLoadMessage   = "load message from store: %w"
+	errFmtReplaceMsg    = "replace message in store: %w"
+	errFmtLoadThread    = "load thread from store: %w"
+	errFmtQueueDirect   = "queue direct message: %w"
+	errFmtTakeDirect    = "load queued direct messages: %w"
+	errFmtExpireDirect  = "expire queued direct messages: %w"
+
+	logMsgCountReplyFailure = "failed to update the reply count of a thread root"
+	logFieldRoom            = "room"
+	logFieldMessage         = "message"
+	logFieldError           = "error"
+)
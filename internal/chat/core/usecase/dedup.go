@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const defaultDedupWindow = time.Minute
+
+// sentRequest remembers the message a client request produced, so a retry within the
+// de-duplication window returns it instead of broadcasting a copy.
+type sentRequest struct {
+	key    string
+	stored domain.Message
+	at     time.Time
+}
+
+func requestKey(userID, requestID string) string {
+	return userID + "\x00" + requestID
+}
+
+// sentLocked returns the message a user's request already produced within the window.
+func (s *Service) sentLocked(rm *room, userID, requestID string) (domain.Message, bool) {
+	if requestID == "" || s.dedupWindow == 0 {
+		return domain.Message{}, false
+	}
+	s.pruneSentLocked(rm)
+	req, ok := rm.sent[requestKey(userID, requestID)]
+	if !ok {
+		return domain.Message{}, false
+	}
+	return req.stored, true
+}
+
+// rememberSentLocked records the message produced by a request carrying a request ID.
+func (s *Service) rememberSentLocked(rm *room, requestID string, stored domain.Message) {
+	if requestID == "" || s.dedupWindow == 0 {
+		return
+	}
+	req := &sentRequest{key: requestKey(stored.UserID, requestID), stored: stored, at: s.clock.Now()}
+	rm.sent[req.key] = req
+	rm.sentOrder = append(rm.sentOrder, req)
+}
+
+// pruneSentLocked forgets the requests older than the window. Requests are recorded in
+// order, so the expired ones are always at the front.
+func (s *Service) pruneSentLocked(rm *room) {
+	cutoff := s.clock.Now().Add(-s.dedupWindow)
+	expired := 0
+	for _, req := range rm.sentOrder {
+		if req.at.After(cutoff) {
+			break
+		}
+		delete(rm.sent, req.key)
+		expired++
+	}
+	if expired > 0 {
+		rm.sentOrder = append(rm.sentOrder[:0], rm.sentOrder[expired:]...)
+	}
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastDeduplicatesRetriedRequests(t *testing.T) {
+	ctx := context.Background()
+	const window = time.Minute
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk), WithDedupWindow(window))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	first := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	retry := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	require.Equal(t, first, retry)
+	require.Equal(t, first.ID, expectEvent(t, chAlice, domain.EventMessage).ID)
+	require.Empty(t, chAlice, "a retried request is not broadcast again")
+
+	// Request IDs are scoped to their user.
+	other := broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	require.NotEqual(t, first.ID, other.ID)
+	expectEvent(t, chAlice, domain.EventMessage)
+
+	clk.t = clk.t.Add(window - time.Second)
+	retry = broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	require.Equal(t, first, retry, "the request is remembered for the whole window")
+
+	clk.t = clk.t.Add(time.Second)
+	late := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	require.NotEqual(t, first.ID, late.ID)
+	require.Equal(t, first.Sequence+2, late.Sequence)
+	expectEvent(t, chAlice, domain.EventMessage)
+}
+
+func TestBroadcastWithoutDedupWindowSendsEveryRequest(t *testing.T) {
+	svc := NewService(WithDedupWindow(0))
+	_, _, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	first := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	second := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi", RequestID: "r-1"})
+	require.NotEqual(t, first.ID, second.ID)
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// SlowConsumerPolicy decides what happens to an event when a subscriber's buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// DropNewest discards the incoming event. The subscriber later receives an
+	// EventMissed notice with the number of events it lost.
+	DropNewest SlowConsumerPolicy = iota
+	// DropOldest evicts the oldest buffered events to make room, followed by an
+	// EventMissed notice counting the evicted events.
+	DropOldest
+	// Block waits up to the block timeout for buffer space, stalling the room's fan-out,
+	// and falls back to DropNewest when the timeout elapses.
+	Block
+	// Disconnect removes the subscriber from the room, closing its stream with an
+	// EventSessionClosed notice.
+	Disconnect
+)
+
+const (
+	defaultBlockTimeout = 100 * time.Millisecond
+
+	reasonSlowConsumer = "too slow to keep up with the room"
+)
+
+// subscriber is the delivery state of a session's event stream.
+type subscriber struct {
+	ch chan domain.Event
+	// missed counts events dropped since the last EventMissed notice.
+	missed uint64
+}
+
+func newSubscriber(size int) *subscriber {
+	return &subscriber{ch: make(chan domain.Event, size)}
+}
+
+// deliverLocked hands an event to a subscriber according to the slow-consumer policy. It
+// reports false when the subscriber must be disconnected. Detached subscribers have no
+// consumer, so they always fall back to DropNewest and recover the gap on resume.
+//
+// Only producers holding the room lock send on subscriber channels, so the free space
+// observed here can only grow until the sends below happen.
+func (s *Service) deliverLocked(rm *room, connectionID string, sub *subscriber, event domain.Event) bool {
+	policy := s.policy
+	if _, detached := rm.detached[connectionID]; detached {
+		policy = DropNewest
+	}
+
+	if offer(sub, event) {
+		return true
+	}
+
+	switch policy {
+	case DropOldest:
+		for free(sub) < 2 && len(sub.ch) > 0 {
+			select {
+			case old := <-sub.ch:
+				sub.missed += missedCount(old)
+			default:
+			}
+		}
+		if !offer(sub, event) {
+			sub.missed++
+		}
+	case Block:
+		if !s.offerWithin(sub, event) {
+			sub.missed++
+		}
+	case Disconnect:
+		return false
+	default:
+		sub.missed++
+	}
+	return true
+}
+
+// offer enqueues the event, preceded by a pending EventMissed notice, only if both fit.
+func offer(sub *subscriber, event domain.Event) bool {
+	need := 1
+	if sub.missed > 0 {
+		need = 2
+	}
+	if free(sub) < need {
+		return false
+	}
+	if sub.missed > 0 {
+		sub.ch <- missedEvent(event.RoomID, sub.missed, event.Timestamp)
+		sub.missed = 0
+	}
+	sub.ch <- event
+	return true
+}
+
+// offerWithin blocks up to the block timeout for the pending notice and the event to be sent.
+func (s *Service) offerWithin(sub *subscriber, event domain.Event) bool {
+	timer := time.NewTimer(s.blockTimeout)
+	defer timer.Stop()
+
+	if sub.missed > 0 {
+		select {
+		case sub.ch <- missedEvent(event.RoomID, sub.missed, event.Timestamp):
+			sub.missed = 0
+		case <-timer.C:
+			return false
+		}
+	}
+	select {
+	case sub.ch <- event:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// closeSubscriber delivers a final event, evicting buffered ones when needed, and closes the
+// stream.
+func closeSubscriber(sub *subscriber, final domain.Event) {
+	for free(sub) < 1 {
+		select {
+		case <-sub.ch:
+		default:
+		}
+	}
+	sub.ch <- final
+	close(sub.ch)
+}
+
+// spareRoom reports whether the subscriber can take an ephemeral signal and still fit the
+// next room event, preceded by its pending EventMissed notice if any.
+func spareRoom(sub *subscriber) bool {
+	reserve := 1
+	if sub.missed > 0 {
+		reserve = 2
+	}
+	return free(sub) > reserve
+}
+
+func free(sub *subscriber) int {
+	return cap(sub.ch) - len(sub.ch)
+}
+
+// missedCount is how many room events a subscriber loses when an evicted event is dropped.
+// Ephemeral signals such as typing indicators and queue positions carry no sequence and are
+// not room events, so losing them goes unreported.
+func missedCount(ev domain.Event) uint64 {
+	switch {
+	case ev.Type == domain.EventMissed:
+		return ev.Missed
+	case ev.Sequence != 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func missedEvent(roomID string, missed uint64, at time.Time) domain.Event {
+	return domain.Event{
+		Type:      domain.EventMissed,
+		RoomID:    roomID,
+		Missed:    missed,
+		Timestamp: at,
+	}
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// joinPair joins alice and bob to room-1 and returns their channels with alice's
+// notice about bob still buffered.
+func joinPair(t *testing.T, svc *Service) (<-chan domain.Event, <-chan domain.Event) {
+	t.Helper()
+	_, chAlice, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	return chAlice, chBob
+}
+
+func broadcastN(t *testing.T, svc *Service, from string, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		broadcast(t, svc, domain.Message{
+			UserID:  from,
+			RoomID:  "room-1",
+			Content: fmt.Sprintf("msg-%d", i),
+		})
+	}
+}
+
+func TestDropNewestReportsMissedEvents(t *testing.T) {
+	svc := NewService(WithBufferSize(2))
+	chAlice, _ := joinPair(t, svc)
+
+	// Alice's buffer holds the join notice and msg-1; msg-2 and msg-3 are dropped.
+	broadcastN(t, svc, "bob", 3)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+	require.Equal(t, "msg-1", expectEvent(t, chAlice, domain.EventMessage).Content)
+
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "msg-4"})
+	require.Equal(t, uint64(2), expectEvent(t, chAlice, domain.EventMissed).Missed)
+	require.Equal(t, "msg-4", expectEvent(t, chAlice, domain.EventMessage).Content)
+}
+
+func TestDropOldestEvictsBufferedEvents(t *testing.T) {
+	svc := NewService(WithBufferSize(2), WithSlowConsumerPolicy(DropOldest))
+	chAlice, _ := joinPair(t, svc)
+
+	broadcastN(t, svc, "bob", 3)
+
+	// The join notice and msg-1 and msg-2 were evicted to keep the notice and the newest event.
+	require.Equal(t, uint64(3), expectEvent(t, chAlice, domain.EventMissed).Missed)
+	require.Equal(t, "msg-3", expectEvent(t, chAlice, domain.EventMessage).Content)
+}
+
+func TestDropOldestDoesNotCountEvictedSignals(t *testing.T) {
+	svc := NewService(WithBufferSize(3), WithSlowConsumerPolicy(DropOldest))
+	chAlice, _ := joinPair(t, svc)
+
+	// Alice's buffer holds the join notice, bob's typing signal and msg-1, so msg-2 evicts
+	// the notice and the signal.
+	require.NoError(t, svc.Typing(context.Background(), "room-1", "bob", true))
+	broadcastN(t, svc, "bob", 2)
+
+	require.Equal(t, "msg-1", expectEvent(t, chAlice, domain.EventMessage).Content)
+	require.Equal(t, uint64(1), expectEvent(t, chAlice, domain.EventMissed).Missed)
+	require.Equal(t, "msg-2", expectEvent(t, chAlice, domain.EventMessage).Content)
+}
+
+func TestDropOldestDeliversNewestEventWithSmallestBuffer(t *testing.T) {
+	// A buffer of one is raised to fit the notice next to the event.
+	svc := NewService(WithBufferSize(1), WithSlowConsumerPolicy(DropOldest))
+	chAlice, _ := joinPair(t, svc)
+
+	broadcastN(t, svc, "bob", 3)
+	require.Equal(t, uint64(3), expectEvent(t, chAlice, domain.EventMissed).Missed)
+	require.Equal(t, "msg-3", expectEvent(t, chAlice, domain.EventMessage).Content)
+	require.Empty(t, chAlice)
+}
+
+func TestBlockWaitsForConsumer(t *testing.T) {
+	svc := NewService(WithBufferSize(2), WithSlowConsumerPolicy(Block), WithBlockTimeout(time.Second))
+	chAlice, chBob := joinPair(t, svc)
+
+	received := make(chan string, 8)
+	go func() {
+		for ev := range chBob {
+			if ev.Type == domain.EventMessage {
+				received <- ev.Content
+			}
+		}
+	}()
+	go func() {
+		for ev := range chAlice {
+			if ev.Type == domain.EventMessage {
+				received <- ev.Content
+			}
+		}
+	}()
+
+	// The buffers hold two events, so the later broadcasts wait for the readers.
+	broadcastN(t, svc, "bob", 4)
+	for i := range 8 {
+		select {
+		case content := <-received:
+			require.Contains(t, []string{"msg-1", "msg-2", "msg-3", "msg-4"}, content, "event %d", i)
+		case <-time.After(time.Second):
+			t.Fatal("expected blocked delivery to complete")
+		}
+	}
+}
+
+func TestBlockFallsBackToDropAfterTimeout(t *testing.T) {
+	svc := NewService(WithBufferSize(2), WithSlowConsumerPolicy(Block), WithBlockTimeout(10*time.Millisecond))
+	chAlice, _ := joinPair(t, svc)
+
+	// Alice's buffer holds the join notice and the first message, so the second one waits.
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi"})
+	start := time.Now()
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "dropped"})
+	require.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	expectEvent(t, chAlice, domain.EventUserJoined)
+	require.Equal(t, "hi", expectEvent(t, chAlice, domain.EventMessage).Content)
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "again"})
+	require.Equal(t, uint64(1), expectEvent(t, chAlice, domain.EventMissed).Missed)
+}
+
+func TestDisconnectRemovesSlowSubscriber(t *testing.T) {
+	svc := NewService(WithBufferSize(2), WithSlowConsumerPolicy(Disconnect))
+	// Bob creates the room, so evicting Alice does not hand its ownership over.
+	_, chBob, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chAlice, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chBob, domain.EventUserJoined)
+
+	// Alice never reads, so her buffer fills with msg-1 and msg-2.
+	broadcastN(t, svc, "bob", 2)
+	require.Equal(t, "msg-1", expectEvent(t, chBob, domain.EventMessage).Content)
+	require.Equal(t, "msg-2", expectEvent(t, chBob, domain.EventMessage).Content)
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+
+	// The closing notice evicts the oldest buffered event so it is always delivered.
+	require.Equal(t, "msg-2", expectEvent(t, chAlice, domain.EventMessage).Content)
+	closed := expectEvent(t, chAlice, domain.EventSessionClosed)
+	require.Equal(t, reasonSlowConsumer, closed.Content)
+	_, ok := <-chAlice
+	require.False(t, ok)
+
+	require.Equal(t, "hi", expectEvent(t, chBob, domain.EventMessage).Content)
+	left := expectEvent(t, chBob, domain.EventUserLeft)
+	require.Equal(t, "alice", left.UserID)
+
+	_, err = svc.Broadcast(context.Background(), domain.Message{UserID: "alice", RoomID: "room-1", Content: "still here?"})
+	require.ErrorIs(t, err, ErrUserNotInRoom)
+}
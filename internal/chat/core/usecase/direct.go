@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// SendDirect delivers a direct message to every open inbox of its recipient. When the
+// recipient is offline the message is queued if a direct message store is configured, and
+// rejected with ErrRecipientOffline otherwise. A queue that reached its limit, once expired
+// messages are discarded, rejects the message with ErrDirectQueueFull.
+//
+// Inboxes that fall behind lose direct messages like DropNewest subscribers do and later
+// receive an EventMissed notice.
+func (s *Service) SendDirect(ctx context.Context, msg domain.DirectMessage) error {
+	if msg.FromUserID == "" || msg.ToUserID == "" {
+		return ErrEmptyFields
+	}
+	if msg.Content == "" {
+		return ErrEmptyMessage
+	}
+	if msg.FromDisplayName == "" {
+		msg.FromDisplayName = msg.FromUserID
+	}
+	msg.ID = s.ids.NewID()
+	msg.SentAt = s.clock.Now()
+
+	unlock := s.lockRecipient(msg.ToUserID)
+	defer unlock()
+
+	if s.deliverDirect(msg) {
+		return nil
+	}
+	if s.directs == nil {
+		return ErrRecipientOffline
+	}
+	if s.directLimit > 0 || s.directTTL > 0 {
+		queued, err := s.directs.ExpireDirect(ctx, msg.ToUserID, s.directCutoff())
+		if err != nil {
+			return fmt.Errorf(errFmtExpireDirect, err)
+		}
+		if s.directLimit > 0 && queued >= s.directLimit {
+			return ErrDirectQueueFull
+		}
+	}
+	if err := s.directs.EnqueueDirect(ctx, msg); err != nil {
+		return fmt.Errorf(errFmtQueueDirect, err)
+	}
+	return nil
+}
+
+// deliverDirect hands the message to every open inbox of its recipient. It reports false
+// when the recipient has none.
+func (s *Service) deliverDirect(msg domain.DirectMessage) bool {
+	s.inboxMu.Lock()
+	defer s.inboxMu.Unlock()
+
+	inboxes := s.inboxes[msg.ToUserID]
+	if len(inboxes) == 0 {
+		return false
+	}
+	event := directEvent(msg)
+	for _, sub := range inboxes {
+		if !offer(sub, event) {
+			sub.missed++
+		}
+	}
+	return true
+}
+
+// OpenInbox starts receiving the user's direct messages and returns the inbox ID with its
+// event stream. The first inbox of an offline user starts with the messages queued for them
+// that have not expired.
+func (s *Service) OpenInbox(ctx context.Context, userID string) (string, <-chan domain.Event, error) {
+	if userID == "" {
+		return "", nil, ErrEmptyFields
+	}
+
+	unlock := s.lockRecipient(userID)
+	defer unlock()
+
+	var queued []domain.DirectMessage
+	if s.directs != nil && !s.online(userID) {
+		var err error
+		if queued, err = s.directs.TakeDirect(ctx, userID); err != nil {
+			return "", nil, fmt.Errorf(errFmtTakeDirect, err)
+		}
+		cutoff := s.directCutoff()
+		queued = slices.DeleteFunc(queued, func(msg domain.DirectMessage) bool {
+			return msg.SentAt.Before(cutoff)
+		})
+	}
+
+	sub := newSubscriber(s.bufSize + len(queued))
+	for _, msg := range queued {
+		ev := directEvent(msg)
+		ev.Replayed = true
+		sub.ch <- ev
+	}
+
+	inboxID := s.ids.NewID()
+	s.inboxMu.Lock()
+	defer s.inboxMu.Unlock()
+	if s.inboxes[userID] == nil {
+		s.inboxes[userID] = make(map[string]*subscriber)
+	}
+	s.inboxes[userID][inboxID] = sub
+	return inboxID, sub.ch, nil
+}
+
+// CloseInbox stops an inbox and closes its stream. Closing an unknown inbox is a no-op.
+func (s *Service) CloseInbox(_ context.Context, userID, inboxID string) error {
+	if userID == "" || inboxID == "" {
+		return ErrEmptyFields
+	}
+
+	s.inboxMu.Lock()
+	defer s.inboxMu.Unlock()
+
+	sub, ok := s.inboxes[userID][inboxID]
+	if !ok {
+		return nil
+	}
+	delete(s.inboxes[userID], inboxID)
+	if len(s.inboxes[userID]) == 0 {
+		delete(s.inboxes, userID)
+	}
+	close(sub.ch)
+	return nil
+}
+
+// online reports whether the user has an open inbox.
+func (s *Service) online(userID string) bool {
+	s.inboxMu.Lock()
+	defer s.inboxMu.Unlock()
+	return len(s.inboxes[userID]) > 0
+}
+
+// recipientLock serialises the direct messages of one user. refs counts the callers holding
+// or waiting for it, so it is dropped once nobody needs it.
+type recipientLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockRecipient locks the direct messages of a user and returns the function releasing them.
+func (s *Service) lockRecipient(userID string) func() {
+	s.recipientsMu.Lock()
+	l, ok := s.recipients[userID]
+	if !ok {
+		l = &recipientLock{}
+		s.recipients[userID] = l
+	}
+	l.refs++
+	s.recipientsMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.recipientsMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(s.recipients, userID)
+		}
+		s.recipientsMu.Unlock()
+	}
+}
+
+// directCutoff returns when the oldest queued direct message still deliverable was sent, or
+// the zero time when queued messages never expire.
+func (s *Service) directCutoff() time.Time {
+	if s.directTTL <= 0 {
+		return time.Time{}
+	}
+	return s.clock.Now().Add(-s.directTTL)
+}
+
+func directEvent(msg domain.DirectMessage) domain.Event {
+	return domain.Event{
+		Type:        domain.EventDirectMessage,
+		ID:          msg.ID,
+		UserID:      msg.FromUserID,
+		DisplayName: msg.FromDisplayName,
+		Recipient:   msg.ToUserID,
+		Content:     msg.Content,
+		Timestamp:   msg.SentAt,
+	}
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendDirectReachesEveryInbox(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	laptopID, laptop, err := svc.OpenInbox(ctx, "alice")
+	require.NoError(t, err)
+	_, phone, err := svc.OpenInbox(ctx, "alice")
+	require.NoError(t, err)
+	_, bob, err := svc.OpenInbox(ctx, "bob")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", FromDisplayName: "Bob", ToUserID: "alice", Content: "psst"}))
+
+	for _, ch := range []<-chan domain.Event{laptop, phone} {
+		ev := expectEvent(t, ch, domain.EventDirectMessage)
+		require.Equal(t, "bob", ev.UserID)
+		require.Equal(t, "Bob", ev.DisplayName)
+		require.Equal(t, "alice", ev.Recipient)
+		require.Equal(t, "psst", ev.Content)
+		require.NotEmpty(t, ev.ID)
+		require.False(t, ev.Replayed)
+	}
+	require.Empty(t, bob)
+
+	require.NoError(t, svc.CloseInbox(ctx, "alice", laptopID))
+	_, ok := <-laptop
+	require.False(t, ok, "closed inbox should end its stream")
+}
+
+func TestSendDirectRejectsOfflineRecipient(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	err := svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "anyone?"})
+	require.ErrorIs(t, err, ErrRecipientOffline)
+
+	err = svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice"})
+	require.ErrorIs(t, err, ErrEmptyMessage)
+	err = svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", Content: "to nobody"})
+	require.ErrorIs(t, err, ErrEmptyFields)
+}
+
+func TestSendDirectQueuesForOfflineRecipient(t *testing.T) {
+	ctx := context.Background()
+	queue := &directQueue{}
+	svc := NewService(WithDirectMessageStore(queue))
+
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "first"}))
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "second"}))
+	require.Len(t, queue.pending["alice"], 2)
+
+	_, inbox, err := svc.OpenInbox(ctx, "alice")
+	require.NoError(t, err)
+	for _, want := range []string{"first", "second"} {
+		ev := expectEvent(t, inbox, domain.EventDirectMessage)
+		require.Equal(t, want, ev.Content)
+		require.True(t, ev.Replayed)
+	}
+	require.Empty(t, queue.pending["alice"])
+
+	// Online recipients get their messages live, without going through the queue.
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "live"}))
+	ev := expectEvent(t, inbox, domain.EventDirectMessage)
+	require.False(t, ev.Replayed)
+	require.Empty(t, queue.pending["alice"])
+}
+
+func TestDirectQueueLimits(t *testing.T) {
+	ctx := context.Background()
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	queue := &directQueue{}
+	svc := NewService(WithClock(clk), WithDirectMessageStore(queue), WithDirectQueueLimits(2, time.Hour))
+
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "first"}))
+	clk.t = clk.t.Add(30 * time.Minute)
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "second"}))
+	err := svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "third"})
+	require.ErrorIs(t, err, ErrDirectQueueFull)
+
+	// Once the first message expires, the queue has room again.
+	clk.t = clk.t.Add(45 * time.Minute)
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "bob", ToUserID: "alice", Content: "third"}))
+	require.Len(t, queue.pending["alice"], 2)
+
+	// Messages that expire while queued are not delivered.
+	clk.t = clk.t.Add(45 * time.Minute)
+	_, inbox, err := svc.OpenInbox(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, "third", expectEvent(t, inbox, domain.EventDirectMessage).Content)
+	require.Empty(t, inbox)
+}
+
+func TestQueueingForOneRecipientDoesNotHoldUpOthers(t *testing.T) {
+	ctx := context.Background()
+	queue := &slowQueue{entered: make(chan struct{}), release: make(chan struct{})}
+	svc := NewService(WithDirectMessageStore(queue))
+	_, bob, err := svc.OpenInbox(ctx, "bob")
+	require.NoError(t, err)
+
+	queued := make(chan error, 1)
+	go func() {
+		queued <- svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "carol", ToUserID: "alice", Content: "later"})
+	}()
+	<-queue.entered
+
+	require.NoError(t, svc.SendDirect(ctx, domain.DirectMessage{FromUserID: "carol", ToUserID: "bob", Content: "now"}))
+	require.Equal(t, "now", expectEvent(t, bob, domain.EventDirectMessage).Content)
+
+	close(queue.release)
+	require.NoError(t, <-queued)
+	require.Len(t, queue.pending["alice"], 1)
+}
+
+// slowQueue is a directQueue whose writes wait until released.
+type slowQueue struct {
+	directQueue
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (q *slowQueue) EnqueueDirect(ctx context.Context, msg domain.DirectMessage) error {
+	close(q.entered)
+	<-q.release
+	return q.directQueue.EnqueueDirect(ctx, msg)
+}
+
+type directQueue struct {
+	pending map[string][]domain.DirectMessage
+}
+
+func (q *directQueue) EnqueueDirect(_ context.Context, msg domain.DirectMessage) error {
+	if q.pending == nil {
+		q.pending = make(map[string][]domain.DirectMessage)
+	}
+	q.pending[msg.ToUserID] = append(q.pending[msg.ToUserID], msg)
+	return nil
+}
+
+func (q *directQueue) TakeDirect(_ context.Context, userID string) ([]domain.DirectMessage, error) {
+	msgs := q.pending[userID]
+	delete(q.pending, userID)
+	return msgs, nil
+}
+
+func (q *directQueue) ExpireDirect(_ context.Context, userID string, before time.Time) (int, error) {
+	var kept []domain.DirectMessage
+	for _, msg := range q.pending[userID] {
+		if !msg.SentAt.Before(before) {
+			kept = append(kept, msg)
+		}
+	}
+	if len(kept) == 0 {
+		delete(q.pending, userID)
+	} else {
+		q.pending[userID] = kept
+	}
+	return len(kept), nil
+}
@@ -5,12 +5,71 @@ import "errors"
 var (
 	// ErrEmptyFields indicates the join request lacks mandatory information.
 	ErrEmptyFields = errors.New("join request missing required fields")
-	// ErrAlreadyJoined indicates the user is already present in the room.
-	ErrAlreadyJoined = errors.New("user already joined room")
 	// ErrRoomNotFound indicates the room is unknown.
 	ErrRoomNotFound = errors.New("room not found")
-	// ErrUserNotInRoom indicates an operation was attempted for a missing user.
+	// ErrUserNotInRoom indicates an operation was attempted for a user or connection that is
+	// not in the room.
 	ErrUserNotInRoom = errors.New("user not part of room")
 	// ErrEmptyMessage indicates the message body is empty.
 	ErrEmptyMessage = errors.New("message content is empty")
+	// ErrInvalidResumeToken indicates the resume token is unknown or its grace period expired.
+	ErrInvalidResumeToken = errors.New("resume token is invalid or expired")
+	// ErrSessionActive indicates a resume was attempted while the session is still connected.
+	ErrSessionActive = errors.New("session is still attached to a connection")
+	// ErrRecipientOffline indicates a direct message was sent to a user without an open inbox
+	// while no direct message store is configured to queue it.
+	ErrRecipientOffline = errors.New("recipient is offline")
+	// ErrDirectQueueFull indicates a direct message to an offline user who already has as
+	// many messages queued as the queue allows.
+	ErrDirectQueueFull = errors.New("recipient's offline queue is full")
+	// ErrMessageNotFound indicates an edit or deletion names a message the room does not have,
+	// or one that was already deleted.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrNotMessageAuthor indicates a user other than the author or a moderator tried to
+	// change a message.
+	ErrNotMessageAuthor = errors.New("only the author or a moderator may change the message")
+	// ErrInvalidReaction indicates a reaction without an emoji or with an oversized one.
+	ErrInvalidReaction = errors.New("reaction must be a non-empty emoji of at most 32 bytes")
+	// ErrNotModerator indicates a user without moderation rights tried to sanction another.
+	ErrNotModerator = errors.New("only moderators may sanction users")
+	// ErrSelfModeration indicates a moderator tried to sanction themselves.
+	ErrSelfModeration = errors.New("moderators cannot sanction themselves")
+	// ErrInvalidDuration indicates a ban or mute with a negative duration.
+	ErrInvalidDuration = errors.New("sanction duration must not be negative")
+	// ErrUserBanned indicates a banned user tried to join the room.
+	ErrUserBanned = errors.New("user is banned from the room")
+	// ErrUserMuted indicates a muted user tried to send a message to the room.
+	ErrUserMuted = errors.New("user is muted in the room")
+	// ErrPermissionDenied indicates the user's role in the room does not allow the operation.
+	ErrPermissionDenied = errors.New("role does not allow the operation")
+	// ErrInvalidRole indicates a role assignment names an unknown role.
+	ErrInvalidRole = errors.New("unknown role")
+	// ErrRoomExists indicates an explicit creation names a room that already exists.
+	ErrRoomExists = errors.New("room already exists")
+	// ErrRoomArchived indicates an operation on a room that was archived.
+	ErrRoomArchived = errors.New("room is archived")
+	// ErrInvalidVisibility indicates a room creation or update names an unknown visibility.
+	ErrInvalidVisibility = errors.New("unknown room visibility")
+	// ErrRoomInfoTooLong indicates a room title, topic or description exceeds its limit.
+	ErrRoomInfoTooLong = errors.New("room title, topic or description is too long")
+	// ErrInvalidAccess indicates a room creation or update names an unknown access policy.
+	ErrInvalidAccess = errors.New("unknown room access policy")
+	// ErrInvalidPassword indicates a password-protected room without a password, or a
+	// password given to a room under another policy.
+	ErrInvalidPassword = errors.New("password must be set for, and only for, password-protected rooms")
+	// ErrRoomAccessDenied indicates a join to a room that is not public without its password
+	// or a valid invite.
+	ErrRoomAccessDenied = errors.New("room requires a password or an invite")
+	// ErrInvalidInvite indicates an invite with a negative lifetime or use count.
+	ErrInvalidInvite = errors.New("invite lifetime and use count must not be negative")
+	// ErrInviteNotFound indicates a revocation names an invite the room does not have.
+	ErrInviteNotFound = errors.New("invite not found")
+	// ErrRoomFull indicates a join to a room that reached its participant limit, or whose
+	// waiting queue is full or disabled when the join asked to wait.
+	ErrRoomFull = errors.New("room is full")
+	// ErrInvalidParticipantLimit indicates a room creation or update with a negative
+	// participant limit.
+	ErrInvalidParticipantLimit = errors.New("participant limit must not be negative")
+	// ErrInvalidPageToken indicates a room listing page token was not issued by the service.
+	ErrInvalidPageToken = errors.New("page token is invalid")
 )
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+const reasonIdle = "idle for too long"
+
+// idleWatch evicts a connection that shows no activity for the idle timeout. Activity only
+// moves lastSeen forward; the timer re-arms itself for the remaining time, as told by the
+// service clock, when it fires early, so heartbeats never touch the timer.
+type idleWatch struct {
+	timer    *time.Timer
+	lastSeen time.Time
+}
+
+// Heartbeat records activity on connections, postponing their idle eviction. It takes no
+// room lock, so a stream following several rooms reports all its connections at once;
+// connections that already left are ignored.
+func (s *Service) Heartbeat(_ context.Context, connectionIDs ...string) error {
+	if len(connectionIDs) == 0 {
+		return ErrEmptyFields
+	}
+
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	now := s.clock.Now()
+	for _, connectionID := range connectionIDs {
+		if watch, ok := s.idle[connectionID]; ok {
+			watch.lastSeen = now
+		}
+	}
+	return nil
+}
+
+// watchIdleLocked starts watching a new connection when an idle timeout is configured.
+func (s *Service) watchIdleLocked(rm *room, connectionID string) {
+	if s.idleTimeout == 0 {
+		return
+	}
+	roomID := rm.id
+	watch := &idleWatch{lastSeen: s.clock.Now()}
+
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+	watch.timer = time.AfterFunc(s.idleTimeout, func() {
+		s.expireIdle(roomID, connectionID, watch)
+	})
+	s.idle[connectionID] = watch
+}
+
+func (s *Service) touch(connectionID string) {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	if watch, ok := s.idle[connectionID]; ok {
+		watch.lastSeen = s.clock.Now()
+	}
+}
+
+func (s *Service) unwatchIdle(connectionID string) {
+	s.idleMu.Lock()
+	defer s.idleMu.Unlock()
+
+	if watch, ok := s.idle[connectionID]; ok {
+		watch.timer.Stop()
+		delete(s.idle, connectionID)
+	}
+}
+
+// expireIdle evicts the connection if it stayed silent for the whole idle timeout. The
+// room is told why the user left and the connection receives a final EventSessionClosed.
+func (s *Service) expireIdle(roomID, connectionID string, watch *idleWatch) {
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return
+	}
+	defer rm.mu.Unlock()
+
+	s.idleMu.Lock()
+	if s.idle[connectionID] != watch {
+		s.idleMu.Unlock()
+		return
+	}
+	// Detached connections are governed by the resume grace period instead.
+	now := s.clock.Now()
+	if _, detached := rm.detached[connectionID]; detached {
+		watch.lastSeen = now
+	}
+	idle := now.Sub(watch.lastSeen)
+	if idle < s.idleTimeout {
+		watch.timer.Reset(s.idleTimeout - idle)
+	}
+	s.idleMu.Unlock()
+
+	if idle >= s.idleTimeout {
+		s.leaveLocked(rm, connectionID, reasonIdle)
+	}
+}
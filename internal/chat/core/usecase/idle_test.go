@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdleConnectionIsEvictedWithReason(t *testing.T) {
+	ctx := context.Background()
+	const timeout = 20 * time.Millisecond
+	clk := &steppingClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk), WithIdleTimeout(timeout))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1", DisplayName: "Alice"})
+	require.NoError(t, err)
+	bob, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	// Until the clock moves, the idle timers only re-arm. Then Bob heartbeats while Alice
+	// stays silent.
+	clk.Advance(timeout)
+	require.NoError(t, svc.Heartbeat(ctx, bob.ConnectionID))
+
+	closed := expectEvent(t, chAlice, domain.EventSessionClosed)
+	require.Equal(t, reasonIdle, closed.Content)
+	_, open := <-chAlice
+	require.False(t, open)
+
+	left := expectEvent(t, chBob, domain.EventUserLeft)
+	require.Equal(t, "alice", left.UserID)
+	require.Equal(t, reasonIdle, left.Content)
+
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	require.Len(t, room.Participants, 1)
+	require.Equal(t, "bob", room.Participants[0].UserID)
+}
+
+func TestHeartbeatCoversEveryConnectionOfAStream(t *testing.T) {
+	ctx := context.Background()
+	const timeout = 20 * time.Millisecond
+	clk := &steppingClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk), WithIdleTimeout(timeout))
+
+	require.ErrorIs(t, svc.Heartbeat(ctx), ErrEmptyFields)
+
+	lobby, chLobby, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "lobby"})
+	require.NoError(t, err)
+	random, chRandom, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "random"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "random"})
+	require.NoError(t, err)
+	expectEvent(t, chRandom, domain.EventUserJoined)
+
+	// One heartbeat keeps both of Alice's connections alive; unknown connections are ignored.
+	clk.Advance(timeout)
+	require.NoError(t, svc.Heartbeat(ctx, lobby.ConnectionID, random.ConnectionID, "unknown"))
+
+	require.Equal(t, reasonIdle, expectEvent(t, chBob, domain.EventSessionClosed).Content)
+	left := expectEvent(t, chRandom, domain.EventUserLeft)
+	require.Equal(t, "bob", left.UserID)
+	require.Empty(t, chLobby)
+	require.Empty(t, chRandom)
+}
+
+func TestVoluntaryLeaveCarriesNoReason(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithIdleTimeout(time.Minute))
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Leave(ctx, "room-1", alice.ConnectionID))
+	require.Empty(t, expectEvent(t, chBob, domain.EventUserLeft).Content)
+}
+
+// steppingClock is a clock the test moves forward while the service reads it from timers.
+type steppingClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *steppingClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// maxEmojiBytes bounds a reaction's emoji; it is generous enough for sequences joined with
+// zero-width joiners and skin tone modifiers.
+const maxEmojiBytes = 32
+
+// EditMessage replaces the content of an earlier message of the room, persists the new
+// version and announces it with an EventMessageEdited. Deleted messages cannot be edited,
+// and muted users cannot edit at all, since an edit sends new text to the room.
+func (s *Service) EditMessage(ctx context.Context, roomID, userID, messageID, content string) (domain.Message, error) {
+	if content == "" {
+		return domain.Message{}, ErrEmptyMessage
+	}
+	return s.changeMessage(ctx, roomID, userID, messageID, true, func(msg *domain.Message, ev *domain.Event) {
+		msg.Content = content
+		msg.EditedAt = ev.Timestamp
+		ev.Type = domain.EventMessageEdited
+		ev.Content = content
+	})
+}
+
+// DeleteMessage replaces an earlier message of the room with a tombstone that keeps its ID
+// and sequence, persists it and announces it with an EventMessageDeleted.
+func (s *Service) DeleteMessage(ctx context.Context, roomID, userID, messageID string) (domain.Message, error) {
+	return s.changeMessage(ctx, roomID, userID, messageID, false, func(msg *domain.Message, ev *domain.Event) {
+		msg.Content = ""
+		msg.Deleted = true
+		msg.Reactions = nil
+		ev.Type = domain.EventMessageDeleted
+	})
+}
+
+// React adds or removes a user's reaction to an earlier message of the room and announces
+// the change with an EventReaction. Any member may react; reacting twice with the same
+// emoji, or removing a reaction that does not exist, changes nothing and announces nothing.
+func (s *Service) React(ctx context.Context, roomID, userID, messageID, emoji string, add bool) (domain.Message, error) {
+	if emoji == "" || len(emoji) > maxEmojiBytes {
+		return domain.Message{}, ErrInvalidReaction
+	}
+	return s.withMessage(ctx, roomID, userID, messageID, func(rm *room, m *member, msg domain.Message) (domain.Message, error) {
+		reactions, count, changed := react(msg.Reactions, emoji, userID, add)
+		if !changed {
+			return msg, nil
+		}
+		msg.Reactions = reactions
+		if err := s.store.Replace(ctx, msg); err != nil {
+			return domain.Message{}, fmt.Errorf(errFmtReplaceMsg, err)
+		}
+
+		s.enqueueLocked(rm, domain.Event{
+			Type:        domain.EventReaction,
+			UserID:      userID,
+			DisplayName: m.profile.DisplayName,
+			RoomID:      roomID,
+			MessageID:   messageID,
+			Content:     emoji,
+			Added:       add,
+			Count:       count,
+			Timestamp:   s.clock.Now(),
+		}, "")
+		return msg, nil
+	})
+}
+
+// changeMessage applies an edit or deletion on behalf of the message's author or a
+// moderator, stores the result and fans out the event describing it. Edits are refused to
+// muted users, and authors need PermissionSend to edit their own messages.
+func (s *Service) changeMessage(ctx context.Context, roomID, userID, messageID string, edit bool, change func(*domain.Message, *domain.Event)) (domain.Message, error) {
+	return s.withMessage(ctx, roomID, userID, messageID, func(rm *room, m *member, msg domain.Message) (domain.Message, error) {
+		if msg.UserID != userID && !s.roleLocked(rm, userID).Allows(domain.PermissionChangeAny) {
+			return domain.Message{}, ErrNotMessageAuthor
+		}
+		if edit && s.restricted(s.mutes, roomID, userID) {
+			return domain.Message{}, ErrUserMuted
+		}
+		if edit && msg.UserID == userID {
+			if err := s.authorizeLocked(rm, userID, domain.PermissionSend); err != nil {
+				return domain.Message{}, err
+			}
+		}
+
+		ev := domain.Event{
+			UserID:      userID,
+			DisplayName: m.profile.DisplayName,
+			RoomID:      roomID,
+			MessageID:   messageID,
+			Timestamp:   s.clock.Now(),
+		}
+		change(&msg, &ev)
+		if err := s.store.Replace(ctx, msg); err != nil {
+			return domain.Message{}, fmt.Errorf(errFmtReplaceMsg, err)
+		}
+
+		s.enqueueLocked(rm, ev, "")
+		return msg, nil
+	})
+}
+
+// withMessage loads a message that is not deleted on behalf of a room member and hands it
+// to fn. The room lock is held throughout so the change is ordered with the room's other
+// events.
+func (s *Service) withMessage(ctx context.Context, roomID, userID, messageID string, fn func(*room, *member, domain.Message) (domain.Message, error)) (domain.Message, error) {
+	if roomID == "" || userID == "" || messageID == "" {
+		return domain.Message{}, ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return domain.Message{}, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	m, ok := rm.members[userID]
+	if !ok {
+		return domain.Message{}, ErrUserNotInRoom
+	}
+	if s.store == nil {
+		return domain.Message{}, ErrMessageNotFound
+	}
+
+	msg, found, err := s.store.Get(ctx, roomID, messageID)
+	if err != nil {
+		return domain.Message{}, fmt.Errorf(errFmtLoadMessage, err)
+	}
+	if !found || msg.Deleted {
+		return domain.Message{}, ErrMessageNotFound
+	}
+	return fn(rm, m, msg)
+}
+
+// react returns a copy of the reactions with the user's reaction added or removed, the
+// number of users reacting with the emoji afterwards and whether anything changed. The
+// input is never modified, since it may be shared with the store.
+func react(reactions []domain.Reaction, emoji, userID string, add bool) ([]domain.Reaction, int, bool) {
+	i := slices.IndexFunc(reactions, func(r domain.Reaction) bool { return r.Emoji == emoji })
+	var users []string
+	if i >= 0 {
+		users = reactions[i].UserIDs
+	}
+	has := slices.Contains(users, userID)
+	if has == add {
+		return reactions, len(users), false
+	}
+
+	if add {
+		users = append(slices.Clone(users), userID)
+	} else {
+		users = slices.DeleteFunc(slices.Clone(users), func(id string) bool { return id == userID })
+	}
+
+	out := slices.Clone(reactions)
+	switch {
+	case i < 0:
+		out = append(out, domain.Reaction{Emoji: emoji, UserIDs: users})
+	case len(users) == 0:
+		out = slices.Delete(out, i, i+1)
+	default:
+		out[i] = domain.Reaction{Emoji: emoji, UserIDs: users}
+	}
+	return out, len(users), true
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditAndDeleteMessage(t *testing.T) {
+	ctx := context.Background()
+	store := &recordingStore{}
+	svc := NewService(WithMessageStore(store), WithModerators("mod"))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1", DisplayName: "Alice"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	sent := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "helo"})
+	expectEvent(t, chAlice, domain.EventMessage)
+
+	edited, err := svc.EditMessage(ctx, "room-1", "alice", sent.ID, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "hello", edited.Content)
+	require.Equal(t, sent.Sequence, edited.Sequence)
+	require.False(t, edited.EditedAt.IsZero())
+
+	ev := expectEvent(t, chAlice, domain.EventMessageEdited)
+	require.Equal(t, sent.ID, ev.MessageID)
+	require.Equal(t, "hello", ev.Content)
+	require.Equal(t, "alice", ev.UserID)
+	require.Equal(t, sent.Sequence+1, ev.Sequence)
+
+	_, err = svc.EditMessage(ctx, "room-1", "bob", sent.ID, "hijacked")
+	require.ErrorIs(t, err, ErrNotMessageAuthor)
+	_, err = svc.DeleteMessage(ctx, "room-1", "bob", sent.ID)
+	require.ErrorIs(t, err, ErrNotMessageAuthor)
+
+	// Moderators may delete anyone's message, leaving a tombstone in the history.
+	deleted, err := svc.DeleteMessage(ctx, "room-1", "mod", sent.ID)
+	require.NoError(t, err)
+	require.True(t, deleted.Deleted)
+	require.Empty(t, deleted.Content)
+	ev = expectEvent(t, chAlice, domain.EventMessageDeleted)
+	require.Equal(t, sent.ID, ev.MessageID)
+	require.Equal(t, "mod", ev.UserID)
+
+	require.Len(t, store.appended, 1)
+	require.Equal(t, deleted, store.appended[0])
+
+	_, err = svc.EditMessage(ctx, "room-1", "alice", sent.ID, "back")
+	require.ErrorIs(t, err, ErrMessageNotFound)
+	_, err = svc.DeleteMessage(ctx, "room-1", "alice", "unknown")
+	require.ErrorIs(t, err, ErrMessageNotFound)
+	_, err = svc.EditMessage(ctx, "room-1", "alice", sent.ID, "")
+	require.ErrorIs(t, err, ErrEmptyMessage)
+	_, err = svc.DeleteMessage(ctx, "room-1", "carol", sent.ID)
+	require.ErrorIs(t, err, ErrUserNotInRoom)
+}
+
+func TestReactions(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMessageStore(&recordingStore{}))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	sent := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "ship it?"})
+	expectEvent(t, chAlice, domain.EventMessage)
+
+	msg, err := svc.React(ctx, "room-1", "bob", sent.ID, "👍", true)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Reaction{{Emoji: "👍", UserIDs: []string{"bob"}}}, msg.Reactions)
+	ev := expectEvent(t, chAlice, domain.EventReaction)
+	require.Equal(t, sent.ID, ev.MessageID)
+	require.Equal(t, "👍", ev.Content)
+	require.Equal(t, "bob", ev.UserID)
+	require.True(t, ev.Added)
+	require.Equal(t, 1, ev.Count)
+
+	// Repeating a reaction changes nothing, so the next event is alice's.
+	_, err = svc.React(ctx, "room-1", "bob", sent.ID, "👍", true)
+	require.NoError(t, err)
+	_, err = svc.React(ctx, "room-1", "alice", sent.ID, "👍", true)
+	require.NoError(t, err)
+	next := expectEvent(t, chAlice, domain.EventReaction)
+	require.Equal(t, "alice", next.UserID)
+	require.Equal(t, 2, next.Count)
+	require.Equal(t, ev.Sequence+1, next.Sequence)
+
+	_, err = svc.React(ctx, "room-1", "alice", sent.ID, "🎉", true)
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventReaction)
+	msg, err = svc.React(ctx, "room-1", "bob", sent.ID, "👍", false)
+	require.NoError(t, err)
+	ev = expectEvent(t, chAlice, domain.EventReaction)
+	require.False(t, ev.Added)
+	require.Equal(t, 1, ev.Count)
+	require.Equal(t, []domain.Reaction{
+		{Emoji: "👍", UserIDs: []string{"alice"}},
+		{Emoji: "🎉", UserIDs: []string{"alice"}},
+	}, msg.Reactions)
+
+	// Late joiners replay the message with its current reactions.
+	_, chCarol, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "room-1", HistoryLimit: 10})
+	require.NoError(t, err)
+	replayed := expectEvent(t, chCarol, domain.EventMessage)
+	require.True(t, replayed.Replayed)
+	require.Equal(t, msg.Reactions, replayed.Reactions)
+
+	_, err = svc.React(ctx, "room-1", "bob", sent.ID, "", true)
+	require.ErrorIs(t, err, ErrInvalidReaction)
+	_, err = svc.React(ctx, "room-1", "bob", "unknown", "👍", true)
+	require.ErrorIs(t, err, ErrMessageNotFound)
+
+	// Deleting a message drops its reactions along with its content.
+	deleted, err := svc.DeleteMessage(ctx, "room-1", "alice", sent.ID)
+	require.NoError(t, err)
+	require.Empty(t, deleted.Reactions)
+	_, err = svc.React(ctx, "room-1", "bob", sent.ID, "👍", true)
+	require.ErrorIs(t, err, ErrMessageNotFound)
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const (
+	reasonKicked = "removed by a moderator"
+	reasonBanned = "banned by a moderator"
+)
+
+// restrictionKey names a user of a room under a ban or mute.
+type restrictionKey struct {
+	roomID string
+	userID string
+}
+
+// Kick removes every connection of a user from the room on behalf of a moderator. The room
+// is told about the kick with an EventModeration; each of the user's connections then
+// receives a final EventSessionClosed carrying the reason, and the user may join again.
+func (s *Service) Kick(_ context.Context, roomID, actorID, userID, reason string) error {
+	rm, err := s.lockModeration(roomID, actorID, userID)
+	if err != nil {
+		return err
+	}
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.members[userID]; !ok {
+		return ErrUserNotInRoom
+	}
+	s.announceModerationLocked(rm, actorID, userID, domain.ModerationKick, reason, time.Time{})
+	s.removeUserLocked(rm, userID, reason, reasonKicked)
+	return nil
+}
+
+// Ban keeps a user out of the room for the given duration on behalf of a moderator, removing
+// them first when they are present. Users need not be in the room to be banned. A zero
+// duration lifts an existing ban.
+func (s *Service) Ban(_ context.Context, roomID, actorID, userID, reason string, duration time.Duration) error {
+	if duration < 0 {
+		return ErrInvalidDuration
+	}
+	rm, err := s.lockModeration(roomID, actorID, userID)
+	if err != nil {
+		return err
+	}
+	defer rm.mu.Unlock()
+
+	key := restrictionKey{roomID: roomID, userID: userID}
+	if duration == 0 {
+		if s.lift(s.bans, key) {
+			s.announceModerationLocked(rm, actorID, userID, domain.ModerationUnban, reason, time.Time{})
+		}
+		return nil
+	}
+
+	until := s.restrict(s.bans, key, duration)
+	s.announceModerationLocked(rm, actorID, userID, domain.ModerationBan, reason, until)
+	s.removeUserLocked(rm, userID, reason, reasonBanned)
+	return nil
+}
+
+// Mute keeps a user from sending messages to the room for the given duration on behalf of a
+// moderator; they still receive the room's events. A zero duration lifts an existing mute.
+func (s *Service) Mute(_ context.Context, roomID, actorID, userID, reason string, duration time.Duration) error {
+	if duration < 0 {
+		return ErrInvalidDuration
+	}
+	rm, err := s.lockModeration(roomID, actorID, userID)
+	if err != nil {
+		return err
+	}
+	defer rm.mu.Unlock()
+
+	key := restrictionKey{roomID: roomID, userID: userID}
+	if duration == 0 {
+		if s.lift(s.mutes, key) {
+			s.announceModerationLocked(rm, actorID, userID, domain.ModerationUnmute, reason, time.Time{})
+		}
+		return nil
+	}
+
+	until := s.restrict(s.mutes, key, duration)
+	s.announceModerationLocked(rm, actorID, userID, domain.ModerationMute, reason, until)
+	return nil
+}
+
+// lockModeration checks that actorID may sanction userID in the room and returns the room
+// locked. Moderators may only sanction users they outrank.
+func (s *Service) lockModeration(roomID, actorID, userID string) (*room, error) {
+	if roomID == "" || actorID == "" || userID == "" {
+		return nil, ErrEmptyFields
+	}
+	if actorID == userID {
+		return nil, ErrSelfModeration
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	if _, ok := rm.members[actorID]; !ok {
+		rm.mu.Unlock()
+		return nil, ErrUserNotInRoom
+	}
+	role := s.roleLocked(rm, actorID)
+	if !role.Allows(domain.PermissionModerate) {
+		rm.mu.Unlock()
+		return nil, ErrNotModerator
+	}
+	if s.roleLocked(rm, userID) >= role {
+		rm.mu.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	return rm, nil
+}
+
+func (s *Service) announceModerationLocked(rm *room, actorID, userID string, action domain.ModerationAction, reason string, until time.Time) {
+	ev := domain.Event{
+		Type:      domain.EventModeration,
+		UserID:    userID,
+		RoomID:    rm.id,
+		ActorID:   actorID,
+		Action:    action,
+		Content:   reason,
+		Until:     until,
+		Timestamp: s.clock.Now(),
+	}
+	if m, ok := rm.members[userID]; ok {
+		ev.DisplayName = m.profile.DisplayName
+	}
+	s.enqueueLocked(rm, ev, "")
+}
+
+// removeUserLocked closes every connection of a user, detached ones and those waiting for a
+// seat included, so none of them can be resumed or admitted.
+func (s *Service) removeUserLocked(rm *room, userID, reason, fallback string) {
+	if reason == "" {
+		reason = fallback
+	}
+	s.dropWaitingLocked(rm, userID, reason)
+	for connID, session := range rm.sessions {
+		if session.UserID == userID {
+			s.leaveLocked(rm, connID, reason)
+		}
+	}
+}
+
+// restrict records a ban or mute lasting duration from now and returns when it expires.
+func (s *Service) restrict(restrictions map[restrictionKey]time.Time, key restrictionKey, duration time.Duration) time.Time {
+	until := s.clock.Now().Add(duration)
+	s.restrictMu.Lock()
+	restrictions[key] = until
+	s.restrictMu.Unlock()
+	return until
+}
+
+// lift removes a ban or mute, reporting whether one was in force.
+func (s *Service) lift(restrictions map[restrictionKey]time.Time, key restrictionKey) bool {
+	s.restrictMu.Lock()
+	defer s.restrictMu.Unlock()
+
+	until, ok := restrictions[key]
+	delete(restrictions, key)
+	return ok && s.clock.Now().Before(until)
+}
+
+// restricted reports whether a ban or mute is in force for the user of the room, forgetting
+// it once it has expired.
+func (s *Service) restricted(restrictions map[restrictionKey]time.Time, roomID, userID string) bool {
+	key := restrictionKey{roomID: roomID, userID: userID}
+	s.restrictMu.Lock()
+	defer s.restrictMu.Unlock()
+
+	until, ok := restrictions[key]
+	if !ok {
+		return false
+	}
+	if !s.clock.Now().Before(until) {
+		delete(restrictions, key)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKickRemovesEveryConnection(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithModerators("mod"))
+
+	_, chMod, err := svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chPhone, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1", DisplayName: "Bob"})
+	require.NoError(t, err)
+	_, chLaptop, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chMod, domain.EventUserJoined)
+
+	require.ErrorIs(t, svc.Kick(ctx, "room-1", "bob", "mod", ""), ErrNotModerator)
+	require.ErrorIs(t, svc.Kick(ctx, "room-1", "mod", "mod", ""), ErrSelfModeration)
+	require.ErrorIs(t, svc.Kick(ctx, "room-1", "mod", "carol", ""), ErrUserNotInRoom)
+
+	require.NoError(t, svc.Kick(ctx, "room-1", "mod", "bob", "spam"))
+	notice := expectEvent(t, chMod, domain.EventModeration)
+	require.Equal(t, domain.ModerationKick, notice.Action)
+	require.Equal(t, "bob", notice.UserID)
+	require.Equal(t, "Bob", notice.DisplayName)
+	require.Equal(t, "mod", notice.ActorID)
+	require.Equal(t, "spam", notice.Content)
+	left := expectEvent(t, chMod, domain.EventUserLeft)
+	require.Equal(t, "spam", left.Content)
+
+	for _, ch := range []<-chan domain.Event{chPhone, chLaptop} {
+		require.Equal(t, domain.ModerationKick, expectEvent(t, ch, domain.EventModeration).Action)
+		require.Equal(t, "spam", expectEvent(t, ch, domain.EventSessionClosed).Content)
+		_, open := <-ch
+		require.False(t, open)
+	}
+
+	// Kicked users may come back.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+}
+
+func TestBanExpires(t *testing.T) {
+	ctx := context.Background()
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithModerators("mod"), WithClock(clk))
+
+	_, chMod, err := svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chMod, domain.EventUserJoined)
+
+	require.ErrorIs(t, svc.Ban(ctx, "room-1", "mod", "bob", "", -time.Minute), ErrInvalidDuration)
+	require.NoError(t, svc.Ban(ctx, "room-1", "mod", "bob", "", time.Hour))
+	notice := expectEvent(t, chMod, domain.EventModeration)
+	require.Equal(t, domain.ModerationBan, notice.Action)
+	require.Equal(t, clk.t.Add(time.Hour), notice.Until)
+	expectEvent(t, chMod, domain.EventUserLeft)
+	expectEvent(t, chBob, domain.EventModeration)
+	require.Equal(t, reasonBanned, expectEvent(t, chBob, domain.EventSessionClosed).Content)
+
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.ErrorIs(t, err, ErrUserBanned)
+
+	clk.t = clk.t.Add(time.Hour)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chMod, domain.EventUserJoined)
+
+	// Users may be banned while away, and bans can be lifted early.
+	require.NoError(t, svc.Ban(ctx, "room-1", "mod", "carol", "", time.Hour))
+	expectEvent(t, chMod, domain.EventModeration)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "room-1"})
+	require.ErrorIs(t, err, ErrUserBanned)
+	require.NoError(t, svc.Ban(ctx, "room-1", "mod", "carol", "", 0))
+	require.Equal(t, domain.ModerationUnban, expectEvent(t, chMod, domain.EventModeration).Action)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "room-1"})
+	require.NoError(t, err)
+}
+
+func TestMuteBlocksBroadcast(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithModerators("mod"), WithMessageStore(&recordingStore{}))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	sent := broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hello"})
+	expectEvent(t, chBob, domain.EventMessage)
+
+	require.NoError(t, svc.Mute(ctx, "room-1", "mod", "bob", "cool down", time.Hour))
+	notice := expectEvent(t, chBob, domain.EventModeration)
+	require.Equal(t, domain.ModerationMute, notice.Action)
+	require.Equal(t, "cool down", notice.Content)
+
+	_, err = svc.Broadcast(ctx, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+	require.ErrorIs(t, err, ErrUserMuted)
+	// Editing an earlier message would send new text to the room just the same.
+	_, err = svc.EditMessage(ctx, "room-1", "bob", sent.ID, "sneaky")
+	require.ErrorIs(t, err, ErrUserMuted)
+	_, err = svc.DeleteMessage(ctx, "room-1", "bob", sent.ID)
+	require.NoError(t, err)
+	expectEvent(t, chBob, domain.EventMessageDeleted)
+
+	require.NoError(t, svc.Mute(ctx, "room-1", "mod", "bob", "", 0))
+	require.Equal(t, domain.ModerationUnmute, expectEvent(t, chBob, domain.EventModeration).Action)
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+	expectEvent(t, chBob, domain.EventMessage)
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// Detach keeps a session whose connection dropped in its room for the resume grace period.
+// The room is not notified; if the session is not resumed in time the connection leaves as
+// usual. Without a grace period Detach behaves exactly like Leave.
+func (s *Service) Detach(ctx context.Context, roomID, connectionID string) error {
+	if s.resumeGrace == 0 {
+		return s.Leave(ctx, roomID, connectionID)
+	}
+	if roomID == "" || connectionID == "" {
+		return ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	// A connection waiting for a seat has nothing to resume.
+	if s.dequeueLocked(rm, connectionID) {
+		return nil
+	}
+	session, ok := rm.sessions[connectionID]
+	if !ok {
+		return ErrUserNotInRoom
+	}
+	if _, detached := rm.detached[connectionID]; detached {
+		return nil
+	}
+
+	token := session.ResumeToken
+	rm.detached[connectionID] = time.AfterFunc(s.resumeGrace, func() {
+		s.expireDetached(roomID, connectionID, token)
+	})
+	return nil
+}
+
+// Resume reattaches a detached session identified by its resume token. The returned stream
+// starts with the stored messages the client missed after req.LastSequence, followed by the
+// events buffered while the session was detached. The session receives a fresh resume token.
+func (s *Service) Resume(ctx context.Context, req domain.ResumeRequest) (domain.Session, <-chan domain.Event, error) {
+	if req.Token == "" {
+		return domain.Session{}, nil, ErrEmptyFields
+	}
+
+	s.tokensMu.Lock()
+	ref, ok := s.tokens[req.Token]
+	s.tokensMu.Unlock()
+	if !ok {
+		return domain.Session{}, nil, ErrInvalidResumeToken
+	}
+
+	rm, ok := s.lockRoom(ref.roomID, false)
+	if !ok {
+		return domain.Session{}, nil, ErrInvalidResumeToken
+	}
+	defer rm.mu.Unlock()
+
+	// The token may have been revoked while the room lock was being acquired.
+	session, ok := rm.sessions[ref.connectionID]
+	if !ok || session.ResumeToken != req.Token {
+		return domain.Session{}, nil, ErrInvalidResumeToken
+	}
+	if req.UserID != "" && req.UserID != session.UserID {
+		return domain.Session{}, nil, ErrInvalidResumeToken
+	}
+	if _, detached := rm.detached[ref.connectionID]; !detached {
+		return domain.Session{}, nil, ErrSessionActive
+	}
+
+	previous := rm.subscribers[ref.connectionID]
+	pending := drain(previous.ch)
+	gap, err := s.gap(ctx, rm, session.RoomID, req.LastSequence, pending)
+	if err != nil {
+		return domain.Session{}, nil, err
+	}
+
+	s.forgetResumeLocked(rm, session)
+	session.ResumeToken = s.issueTokenLocked(session)
+	rm.sessions[ref.connectionID] = session
+
+	sub := newSubscriber(s.bufSize + len(gap) + len(pending))
+	sub.missed = previous.missed
+	for _, msg := range gap {
+		ev := messageEvent(msg)
+		ev.Replayed = true
+		sub.ch <- ev
+	}
+	for _, ev := range pending {
+		if ev.Sequence != 0 && ev.Sequence <= req.LastSequence {
+			continue
+		}
+		sub.ch <- ev
+	}
+	rm.subscribers[ref.connectionID] = sub
+	s.touch(ref.connectionID)
+
+	return session, sub.ch, nil
+}
+
+// gap loads the stored messages a resuming client missed: those after its last sequence and
+// before the first event still buffered for it. Presence notices lost in flight are not
+// recoverable. The replay is capped like the join history.
+func (s *Service) gap(ctx context.Context, rm *room, roomID string, lastSeq uint64, pending []domain.Event) ([]domain.Message, error) {
+	if s.store == nil || s.maxHistory == 0 {
+		return nil, nil
+	}
+
+	until := rm.seq + 1
+	for _, ev := range pending {
+		if ev.Sequence != 0 {
+			until = ev.Sequence
+			break
+		}
+	}
+
+	msgs, err := s.store.RangeBySequence(ctx, roomID, lastSeq+1, until, 0)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtLoadHistory, err)
+	}
+	if len(msgs) > s.maxHistory {
+		msgs = msgs[len(msgs)-s.maxHistory:]
+	}
+	return msgs, nil
+}
+
+// expireDetached removes a session that was not resumed within the grace period.
+func (s *Service) expireDetached(roomID, connectionID, token string) {
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return
+	}
+	defer rm.mu.Unlock()
+
+	session, ok := rm.sessions[connectionID]
+	if !ok || session.ResumeToken != token {
+		return
+	}
+	if _, detached := rm.detached[connectionID]; !detached {
+		return
+	}
+	s.leaveLocked(rm, connectionID, "")
+}
+
+// issueTokenLocked registers a new resume token for the session.
+func (s *Service) issueTokenLocked(session domain.Session) string {
+	token := s.secrets.NewID()
+	s.tokensMu.Lock()
+	defer s.tokensMu.Unlock()
+	s.tokens[token] = sessionRef{roomID: session.RoomID, connectionID: session.ConnectionID}
+	return token
+}
+
+// forgetResumeLocked revokes the session's resume token and cancels a pending expiry.
+func (s *Service) forgetResumeLocked(rm *room, session domain.Session) {
+	s.tokensMu.Lock()
+	delete(s.tokens, session.ResumeToken)
+	s.tokensMu.Unlock()
+	if timer, ok := rm.detached[session.ConnectionID]; ok {
+		timer.Stop()
+		delete(rm.detached, session.ConnectionID)
+	}
+}
+
+// drain empties a subscriber channel without blocking.
+func drain(ch chan domain.Event) []domain.Event {
+	var out []domain.Event
+	for {
+		select {
+		case ev := <-ch:
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeReplaysGapWithoutPresenceNotices(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithResumeGrace(time.Minute), WithMessageStore(&recordingStore{}))
+
+	alice, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	seen := expectEvent(t, chAlice, domain.EventUserJoined)
+
+	// The forwarder pulled m1 from the channel but the connection dropped before delivery.
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "m1"})
+	expectEvent(t, chAlice, domain.EventMessage)
+	expectEvent(t, chBob, domain.EventMessage)
+
+	require.NoError(t, svc.Detach(ctx, "room-1", alice.ConnectionID))
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "m2"})
+	expectEvent(t, chBob, domain.EventMessage)
+
+	resumed, chResumed, err := svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken, LastSequence: seen.Sequence})
+	require.NoError(t, err)
+	require.NotEqual(t, alice.ResumeToken, resumed.ResumeToken)
+	require.Equal(t, alice.JoinedAt, resumed.JoinedAt)
+
+	m1 := expectEvent(t, chResumed, domain.EventMessage)
+	require.Equal(t, "m1", m1.Content)
+	require.True(t, m1.Replayed)
+	m2 := expectEvent(t, chResumed, domain.EventMessage)
+	require.Equal(t, "m2", m2.Content)
+	require.False(t, m2.Replayed)
+
+	require.Empty(t, chBob, "bob must not see leave/join notices for a resumed session")
+
+	_, _, err = svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken})
+	require.ErrorIs(t, err, ErrInvalidResumeToken)
+}
+
+func TestResumeRejectsAttachedSession(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	_, _, err = svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken})
+	require.ErrorIs(t, err, ErrSessionActive)
+}
+
+func TestResumeRejectsTokenOfAnotherUser(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithResumeGrace(time.Minute))
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Detach(ctx, "room-1", alice.ConnectionID))
+
+	_, _, err = svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken, UserID: "mallory"})
+	require.ErrorIs(t, err, ErrInvalidResumeToken)
+
+	resumed, _, err := svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken, UserID: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, "alice", resumed.UserID)
+}
+
+func TestDetachedSessionLeavesAfterGrace(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithResumeGrace(10 * time.Millisecond))
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Detach(ctx, "room-1", alice.ConnectionID))
+
+	left := expectEvent(t, chBob, domain.EventUserLeft)
+	require.Equal(t, "alice", left.UserID)
+
+	_, _, err = svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken})
+	require.ErrorIs(t, err, ErrInvalidResumeToken)
+}
+
+func TestJoinBesideDetachedSessionKeepsPresence(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithResumeGrace(10 * time.Millisecond))
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Detach(ctx, "room-1", alice.ConnectionID))
+
+	// The new connection keeps alice present while the detached one expires.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.Never(t, func() bool { return len(chBob) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+
+	_, _, err = svc.Resume(ctx, domain.ResumeRequest{Token: alice.ResumeToken})
+	require.ErrorIs(t, err, ErrInvalidResumeToken)
+}
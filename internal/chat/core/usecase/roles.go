@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+// SetRole assigns a role to a user of the room on behalf of actorID. Users may only assign
+// roles below their own to users they outrank; the owner hands ownership over by assigning
+// RoleOwner to a user present in the room, and becomes a moderator. Every change is
+// announced with an EventRoleChanged.
+func (s *Service) SetRole(_ context.Context, roomID, actorID, userID string, role domain.Role) error {
+	if roomID == "" || actorID == "" || userID == "" {
+		return ErrEmptyFields
+	}
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	if actorID == userID {
+		return ErrPermissionDenied
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	if _, ok := rm.members[actorID]; !ok {
+		return ErrUserNotInRoom
+	}
+	actorRole := s.roleLocked(rm, actorID)
+	if !actorRole.Allows(domain.PermissionAssignRoles) || s.roleLocked(rm, userID) >= actorRole {
+		return ErrPermissionDenied
+	}
+
+	if role == domain.RoleOwner {
+		if actorRole != domain.RoleOwner {
+			return ErrPermissionDenied
+		}
+		if _, ok := rm.members[userID]; !ok {
+			return ErrUserNotInRoom
+		}
+		s.assignRoleLocked(rm, actorID, userID, domain.RoleOwner)
+		s.assignRoleLocked(rm, actorID, actorID, domain.RoleModerator)
+		return nil
+	}
+	if role >= actorRole {
+		return ErrPermissionDenied
+	}
+	s.assignRoleLocked(rm, actorID, userID, role)
+	return nil
+}
+
+// roleLocked returns the user's role in the room. Users named by WithModerators hold at
+// least RoleModerator in every room.
+func (s *Service) roleLocked(rm *room, userID string) domain.Role {
+	if userID == rm.owner {
+		return domain.RoleOwner
+	}
+	role, ok := rm.roles[userID]
+	if !ok {
+		role = domain.RoleMember
+	}
+	if s.moderators[userID] && role < domain.RoleModerator {
+		role = domain.RoleModerator
+	}
+	return role
+}
+
+// authorizeLocked returns ErrPermissionDenied unless the user's role grants the permission.
+func (s *Service) authorizeLocked(rm *room, userID string, permission domain.Permission) error {
+	if !s.roleLocked(rm, userID).Allows(permission) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// assignRoleLocked records a role, refreshes the user's sessions and announces the change
+// when it altered the user's role. An empty actorID marks changes made by the server.
+func (s *Service) assignRoleLocked(rm *room, actorID, userID string, role domain.Role) {
+	before := s.roleLocked(rm, userID)
+	switch {
+	case role == domain.RoleOwner:
+		rm.owner = userID
+		delete(rm.roles, userID)
+	case userID == rm.owner:
+		rm.owner = ""
+		rm.roles[userID] = role
+	case role == domain.RoleMember:
+		delete(rm.roles, userID)
+	default:
+		rm.roles[userID] = role
+	}
+
+	role = s.roleLocked(rm, userID)
+	if role == before {
+		return
+	}
+	for connID, session := range rm.sessions {
+		if session.UserID == userID {
+			session.Role = role
+			rm.sessions[connID] = session
+		}
+	}
+
+	ev := domain.Event{
+		Type:      domain.EventRoleChanged,
+		UserID:    userID,
+		RoomID:    rm.id,
+		ActorID:   actorID,
+		Role:      role,
+		Timestamp: s.clock.Now(),
+	}
+	if m, ok := rm.members[userID]; ok {
+		m.profile.Role = role
+		ev.DisplayName = m.profile.DisplayName
+	}
+	s.enqueueLocked(rm, ev, "")
+}
+
+// passOwnershipLocked hands the room over after its owner left: the highest ranked user
+// still present becomes the owner, the longest present winning ties. A room nobody is left
+// in keeps its owner, and so does a persistent room, whose owner may come back to it.
+func (s *Service) passOwnershipLocked(rm *room) {
+	if len(rm.members) == 0 || rm.info.Persistent {
+		return
+	}
+
+	candidates := make([]*member, 0, len(rm.members))
+	for _, m := range rm.members {
+		candidates = append(candidates, m)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].profile, candidates[j].profile
+		if ra, rb := s.roleLocked(rm, a.UserID), s.roleLocked(rm, b.UserID); ra != rb {
+			return ra > rb
+		}
+		if !a.JoinedAt.Equal(b.JoinedAt) {
+			return a.JoinedAt.Before(b.JoinedAt)
+		}
+		return a.UserID < b.UserID
+	})
+	s.assignRoleLocked(rm, "", candidates[0].profile.UserID, domain.RoleOwner)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipPassesToHighestRankedUser(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.Equal(t, domain.RoleOwner, alice.Role)
+	bob, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.Equal(t, domain.RoleMember, bob.Role)
+	_, chCarol, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.SetRole(ctx, "room-1", "alice", "carol", domain.RoleModerator))
+	changed := expectEvent(t, chCarol, domain.EventRoleChanged)
+	require.Equal(t, "carol", changed.UserID)
+	require.Equal(t, "alice", changed.ActorID)
+	require.Equal(t, domain.RoleModerator, changed.Role)
+
+	// Carol outranks Bob, who joined earlier.
+	require.NoError(t, svc.Leave(ctx, "room-1", alice.ConnectionID))
+	expectEvent(t, chCarol, domain.EventUserLeft)
+	changed = expectEvent(t, chCarol, domain.EventRoleChanged)
+	require.Equal(t, "carol", changed.UserID)
+	require.Empty(t, changed.ActorID)
+	require.Equal(t, domain.RoleOwner, changed.Role)
+
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	require.Equal(t, []domain.Participant{
+		{UserID: "bob", DisplayName: "bob", JoinedAt: room.Participants[0].JoinedAt, Role: domain.RoleMember},
+		{UserID: "carol", DisplayName: "carol", JoinedAt: room.Participants[1].JoinedAt, Role: domain.RoleOwner},
+	}, room.Participants)
+}
+
+func TestRolesGateOperations(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMessageStore(&recordingStore{}))
+
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: userID, RoomID: "room-1"})
+		require.NoError(t, err)
+	}
+	sent := broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+
+	// Members hold no moderation rights.
+	require.ErrorIs(t, svc.SetRole(ctx, "room-1", "bob", "carol", domain.RoleReadOnly), ErrPermissionDenied)
+	require.ErrorIs(t, svc.Kick(ctx, "room-1", "carol", "bob", ""), ErrNotModerator)
+	_, err := svc.DeleteMessage(ctx, "room-1", "carol", sent.ID)
+	require.ErrorIs(t, err, ErrNotMessageAuthor)
+
+	require.NoError(t, svc.SetRole(ctx, "room-1", "alice", "carol", domain.RoleModerator))
+	require.ErrorIs(t, svc.SetRole(ctx, "room-1", "alice", "carol", domain.Role(0)), ErrInvalidRole)
+
+	// Moderators only act on users they outrank, and only grant lower roles.
+	require.ErrorIs(t, svc.Kick(ctx, "room-1", "carol", "alice", ""), ErrPermissionDenied)
+	require.ErrorIs(t, svc.SetRole(ctx, "room-1", "carol", "bob", domain.RoleModerator), ErrPermissionDenied)
+	require.NoError(t, svc.SetRole(ctx, "room-1", "carol", "bob", domain.RoleReadOnly))
+	_, err = svc.Broadcast(ctx, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hello?"})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = svc.EditMessage(ctx, "room-1", "bob", sent.ID, "rewritten")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = svc.DeleteMessage(ctx, "room-1", "carol", sent.ID)
+	require.NoError(t, err)
+
+	// Only the owner hands ownership over, and then steps down to moderator.
+	require.ErrorIs(t, svc.SetRole(ctx, "room-1", "carol", "bob", domain.RoleOwner), ErrPermissionDenied)
+	require.NoError(t, svc.SetRole(ctx, "room-1", "alice", "carol", domain.RoleOwner))
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	roles := map[string]domain.Role{}
+	for _, p := range room.Participants {
+		roles[p.UserID] = p.Role
+	}
+	require.Equal(t, map[string]domain.Role{
+		"alice": domain.RoleModerator,
+		"bob":   domain.RoleReadOnly,
+		"carol": domain.RoleOwner,
+	}, roles)
+}
@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const (
+	defaultRoomPageSize = 50
+	maxRoomPageSize     = 500
+
+	maxRoomTitleBytes       = 100
+	maxRoomTopicBytes       = 250
+	maxRoomDescriptionBytes = 1000
+
+	reasonArchived = "room archived by its owner"
+)
+
+// CreateRoom explicitly creates a room owned by req.UserID, who does not join it. Unlike the
+// rooms created by joining them, it is persistent: it stays in the registry once its last
+// participant leaves, keeping its history, sequence, roles and settings.
+func (s *Service) CreateRoom(ctx context.Context, req domain.CreateRoomRequest) (domain.RoomSummary, error) {
+	if req.RoomID == "" || req.UserID == "" {
+		return domain.RoomSummary{}, ErrEmptyFields
+	}
+	visibility := req.Visibility
+	if visibility == 0 {
+		visibility = domain.VisibilityPublic
+	}
+	if !visibility.Valid() {
+		return domain.RoomSummary{}, ErrInvalidVisibility
+	}
+	access := req.Access
+	if access == 0 {
+		access = domain.AccessPublic
+	}
+	var password *string
+	if req.Password != "" {
+		password = &req.Password
+	}
+	if err := checkAccess(access, false, password); err != nil {
+		return domain.RoomSummary{}, err
+	}
+	if err := checkRoomText(req.Title, req.Topic, req.Description); err != nil {
+		return domain.RoomSummary{}, err
+	}
+	if req.MaxParticipants < 0 {
+		return domain.RoomSummary{}, ErrInvalidParticipantLimit
+	}
+
+	rm, _ := s.lockRoom(req.RoomID, true)
+	defer rm.mu.Unlock()
+
+	if rm.info.CreatedBy != "" {
+		return domain.RoomSummary{}, ErrRoomExists
+	}
+	if err := s.loadSequenceLocked(ctx, rm); err != nil {
+		s.retireIfEmptyLocked(rm)
+		return domain.RoomSummary{}, err
+	}
+
+	rm.owner = req.UserID
+	rm.info = domain.RoomInfo{
+		Title:           req.Title,
+		Topic:           req.Topic,
+		Description:     req.Description,
+		CreatedBy:       req.UserID,
+		CreatedAt:       s.clock.Now(),
+		Visibility:      visibility,
+		Access:          access,
+		Persistent:      true,
+		MaxParticipants: req.MaxParticipants,
+	}
+	applyAccessLocked(rm, 0, password)
+	return summaryLocked(rm), nil
+}
+
+// UpdateRoom changes the metadata of a room on behalf of req.UserID, who need not be present
+// in it. Changing the topic takes PermissionSetTopic and is announced to the room with an
+// EventTopicChanged; changing the other fields, the access policy, password and participant
+// limit included, takes PermissionManageRoom. Raising the limit seats the users waiting for it.
+func (s *Service) UpdateRoom(_ context.Context, req domain.UpdateRoomRequest) (domain.RoomSummary, error) {
+	if req.RoomID == "" || req.UserID == "" {
+		return domain.RoomSummary{}, ErrEmptyFields
+	}
+	if req.Visibility != 0 && !req.Visibility.Valid() {
+		return domain.RoomSummary{}, ErrInvalidVisibility
+	}
+	if req.Access != 0 && !req.Access.Valid() {
+		return domain.RoomSummary{}, ErrInvalidAccess
+	}
+	if req.MaxParticipants != nil && *req.MaxParticipants < 0 {
+		return domain.RoomSummary{}, ErrInvalidParticipantLimit
+	}
+
+	rm, ok := s.lockRoom(req.RoomID, false)
+	if !ok {
+		return domain.RoomSummary{}, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	if rm.info.Archived {
+		return domain.RoomSummary{}, ErrRoomArchived
+	}
+
+	info := rm.info
+	if req.Title != nil {
+		info.Title = *req.Title
+	}
+	if req.Topic != nil {
+		info.Topic = *req.Topic
+	}
+	if req.Description != nil {
+		info.Description = *req.Description
+	}
+	if req.Visibility != 0 {
+		info.Visibility = req.Visibility
+	}
+	if req.Access != 0 {
+		info.Access = req.Access
+	}
+	if req.MaxParticipants != nil {
+		info.MaxParticipants = *req.MaxParticipants
+	}
+	if err := checkRoomText(info.Title, info.Topic, info.Description); err != nil {
+		return domain.RoomSummary{}, err
+	}
+	if err := checkAccess(info.Access, rm.password != nil, req.Password); err != nil {
+		return domain.RoomSummary{}, err
+	}
+
+	topicChanged := info.Topic != rm.info.Topic
+	if topicChanged {
+		if err := s.authorizeLocked(rm, req.UserID, domain.PermissionSetTopic); err != nil {
+			return domain.RoomSummary{}, err
+		}
+	}
+	if info.Title != rm.info.Title || info.Description != rm.info.Description || info.Visibility != rm.info.Visibility ||
+		info.Access != rm.info.Access || info.MaxParticipants != rm.info.MaxParticipants || req.Password != nil {
+		if err := s.authorizeLocked(rm, req.UserID, domain.PermissionManageRoom); err != nil {
+			return domain.RoomSummary{}, err
+		}
+	}
+	before := rm.info.Access
+	rm.info = info
+	applyAccessLocked(rm, before, req.Password)
+
+	if topicChanged {
+		ev := domain.Event{
+			Type:      domain.EventTopicChanged,
+			UserID:    req.UserID,
+			RoomID:    rm.id,
+			Content:   info.Topic,
+			Timestamp: s.clock.Now(),
+		}
+		if m, ok := rm.members[req.UserID]; ok {
+			ev.DisplayName = m.profile.DisplayName
+		}
+		s.enqueueLocked(rm, ev, "")
+	}
+	s.seatWaitingLocked(rm)
+	return summaryLocked(rm), nil
+}
+
+// ArchiveRoom archives a room on behalf of its owner. Every session of the room, and every
+// connection waiting for a seat, is closed with a final EventSessionClosed; the room then
+// keeps its history and settings but can no longer be joined or changed.
+func (s *Service) ArchiveRoom(_ context.Context, roomID, userID string) (domain.RoomSummary, error) {
+	if roomID == "" || userID == "" {
+		return domain.RoomSummary{}, ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return domain.RoomSummary{}, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	if rm.info.Archived {
+		return domain.RoomSummary{}, ErrRoomArchived
+	}
+	if err := s.authorizeLocked(rm, userID, domain.PermissionManageRoom); err != nil {
+		return domain.RoomSummary{}, err
+	}
+
+	rm.info.Archived = true
+	rm.info.Persistent = true
+	s.dropWaitingLocked(rm, "", reasonArchived)
+	for connID := range rm.sessions {
+		s.leaveLocked(rm, connID, reasonArchived)
+	}
+	return summaryLocked(rm), nil
+}
+
+// checkRoomText enforces the size limits of the room's metadata.
+func checkRoomText(title, topic, description string) error {
+	if len(title) > maxRoomTitleBytes || len(topic) > maxRoomTopicBytes || len(description) > maxRoomDescriptionBytes {
+		return ErrRoomInfoTooLong
+	}
+	return nil
+}
+
+// ListRooms returns a page of the public rooms ordered by ID, leaving out archived ones.
+// The registry is only held while snapshotting the candidate rooms; each room is then read
+// under its own lock, so rooms that empty in the meantime are skipped.
+func (s *Service) ListRooms(_ context.Context, req domain.ListRoomsRequest) (domain.RoomPage, error) {
+	after, err := decodePageToken(req.PageToken)
+	if err != nil {
+		return domain.RoomPage{}, err
+	}
+
+	size := req.PageSize
+	if size <= 0 {
+		size = defaultRoomPageSize
+	}
+	size = min(size, maxRoomPageSize)
+
+	candidates := s.snapshotRooms(req.Prefix, after)
+
+	// A next page is only offered once a listed room is found past the end of this one, so
+	// clients never fetch an empty final page.
+	page := domain.RoomPage{Rooms: make([]domain.RoomSummary, 0, min(size, len(candidates)))}
+	for _, rm := range candidates {
+		rm.mu.Lock()
+		listed := !rm.closed && !rm.info.Archived && rm.info.Visibility == domain.VisibilityPublic
+		var summary domain.RoomSummary
+		if listed {
+			summary = summaryLocked(rm)
+		}
+		rm.mu.Unlock()
+		if !listed {
+			continue
+		}
+		if len(page.Rooms) == size {
+			page.NextPageToken = encodePageToken(page.Rooms[size-1].RoomID)
+			break
+		}
+		page.Rooms = append(page.Rooms, summary)
+	}
+	return page, nil
+}
+
+// GetRoom returns a room with its metadata and participants, including detached ones that
+// may still resume. Unlisted and archived rooms are returned as well. Rooms that are not
+// public only list their participants to userID when that user is present or owns the
+// room; other callers get the metadata alone.
+func (s *Service) GetRoom(_ context.Context, roomID, userID string) (domain.Room, error) {
+	if roomID == "" {
+		return domain.Room{}, ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return domain.Room{}, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	room := domain.Room{RoomSummary: summaryLocked(rm)}
+	if rm.info.Access.Restricted() && !insiderLocked(rm, userID) {
+		return room, nil
+	}
+	room.Participants = make([]domain.Participant, 0, len(rm.members))
+	for _, m := range rm.members {
+		room.Participants = append(room.Participants, domain.Participant{
+			UserID:      m.profile.UserID,
+			DisplayName: m.profile.DisplayName,
+			JoinedAt:    m.profile.JoinedAt,
+			Role:        s.roleLocked(rm, m.profile.UserID),
+		})
+	}
+	sort.Slice(room.Participants, func(i, j int) bool {
+		return room.Participants[i].UserID < room.Participants[j].UserID
+	})
+	return room, nil
+}
+
+// insiderLocked reports whether a user may look inside a restricted room: only its members
+// and its owner may.
+func insiderLocked(rm *room, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	_, present := rm.members[userID]
+	return present || userID == rm.owner
+}
+
+// snapshotRooms returns the rooms matching prefix whose ID sorts after the given one.
+func (s *Service) snapshotRooms(prefix, after string) []*room {
+	s.mu.RLock()
+	rooms := make([]*room, 0, len(s.rooms))
+	for id, rm := range s.rooms {
+		if strings.HasPrefix(id, prefix) && id > after {
+			rooms = append(rooms, rm)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].id < rooms[j].id })
+	return rooms
+}
+
+func summaryLocked(rm *room) domain.RoomSummary {
+	return domain.RoomSummary{
+		RoomID:           rm.id,
+		ParticipantCount: len(rm.members),
+		LastSequence:     rm.seq,
+		RoomInfo:         rm.info,
+	}
+}
+
+// Page tokens are opaque to clients: the base64 encoding of the last room ID returned.
+func encodePageToken(roomID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(roomID))
+}
+
+func decodePageToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) == 0 {
+		return "", ErrInvalidPageToken
+	}
+	return string(raw), nil
+}
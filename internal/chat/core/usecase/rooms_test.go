@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRoomsPagesByPrefix(t *testing.T) {
+	ctx := context.Background()
+	clk := fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk))
+	for _, roomID := range []string{"team-b", "random", "team-a", "team-c"} {
+		_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: roomID})
+		require.NoError(t, err)
+	}
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "team-a"})
+	require.NoError(t, err)
+
+	first, err := svc.ListRooms(ctx, domain.ListRoomsRequest{Prefix: "team-", PageSize: 2})
+	require.NoError(t, err)
+	info := domain.RoomInfo{CreatedBy: "alice", CreatedAt: clk.t, Visibility: domain.VisibilityPublic, Access: domain.AccessPublic}
+	require.Equal(t, []domain.RoomSummary{
+		{RoomID: "team-a", ParticipantCount: 2, LastSequence: 2, RoomInfo: info},
+		{RoomID: "team-b", ParticipantCount: 1, LastSequence: 1, RoomInfo: info},
+	}, first.Rooms)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second, err := svc.ListRooms(ctx, domain.ListRoomsRequest{Prefix: "team-", PageSize: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, second.Rooms, 1)
+	require.Equal(t, "team-c", second.Rooms[0].RoomID)
+	require.Empty(t, second.NextPageToken)
+
+	all, err := svc.ListRooms(ctx, domain.ListRoomsRequest{})
+	require.NoError(t, err)
+	require.Len(t, all.Rooms, 4)
+
+	_, err = svc.ListRooms(ctx, domain.ListRoomsRequest{PageToken: "%%%"})
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+
+	// A full page followed only by rooms that are not listed is the last one.
+	_, err = svc.ArchiveRoom(ctx, "team-c", "alice")
+	require.NoError(t, err)
+	last, err := svc.ListRooms(ctx, domain.ListRoomsRequest{Prefix: "team-", PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, last.Rooms, 2)
+	require.Empty(t, last.NextPageToken)
+}
+
+func TestGetRoomListsParticipants(t *testing.T) {
+	ctx := context.Background()
+	clk := fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk))
+
+	carol, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "room-1", DisplayName: "Carol"})
+	require.NoError(t, err)
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	require.Equal(t, domain.Room{
+		RoomSummary: domain.RoomSummary{
+			RoomID:           "room-1",
+			ParticipantCount: 2,
+			LastSequence:     2,
+			RoomInfo:         domain.RoomInfo{CreatedBy: "carol", CreatedAt: clk.t, Visibility: domain.VisibilityPublic, Access: domain.AccessPublic},
+		},
+		Participants: []domain.Participant{
+			{UserID: "alice", DisplayName: "alice", JoinedAt: clk.t, Role: domain.RoleMember},
+			{UserID: "carol", DisplayName: "Carol", JoinedAt: clk.t, Role: domain.RoleOwner},
+		},
+	}, room)
+
+	require.NoError(t, svc.Leave(ctx, "room-1", alice.ConnectionID))
+	require.NoError(t, svc.Leave(ctx, "room-1", carol.ConnectionID))
+	_, err = svc.GetRoom(ctx, "room-1", "")
+	require.ErrorIs(t, err, ErrRoomNotFound)
+}
+
+func TestCreatedRoomOutlivesItsParticipants(t *testing.T) {
+	ctx := context.Background()
+	clk := fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk), WithAdHocRooms(false))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "design"})
+	require.ErrorIs(t, err, ErrRoomNotFound)
+
+	created, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{
+		RoomID: "design", UserID: "alice", Title: "Design", Topic: "mockups", Visibility: domain.VisibilityUnlisted,
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.RoomSummary{
+		RoomID: "design",
+		RoomInfo: domain.RoomInfo{
+			Title: "Design", Topic: "mockups", CreatedBy: "alice", CreatedAt: clk.t,
+			Visibility: domain.VisibilityUnlisted, Access: domain.AccessPublic, Persistent: true,
+		},
+	}, created)
+	_, err = svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "design", UserID: "bob"})
+	require.ErrorIs(t, err, ErrRoomExists)
+
+	// The creator owns the room without being in it, and keeps it after visiting.
+	bob, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "design"})
+	require.NoError(t, err)
+	require.Equal(t, domain.RoleMember, bob.Role)
+	alice, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "design"})
+	require.NoError(t, err)
+	require.Equal(t, domain.RoleOwner, alice.Role)
+	require.NoError(t, svc.Leave(ctx, "design", alice.ConnectionID))
+	require.NoError(t, svc.Leave(ctx, "design", bob.ConnectionID))
+
+	room, err := svc.GetRoom(ctx, "design", "")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), room.LastSequence)
+	require.Empty(t, room.Participants)
+
+	// Unlisted rooms are left out of listings.
+	page, err := svc.ListRooms(ctx, domain.ListRoomsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, page.Rooms)
+
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "design", UserID: "alice", Visibility: domain.VisibilityPublic, Access: domain.AccessPublic})
+	require.NoError(t, err)
+	page, err = svc.ListRooms(ctx, domain.ListRoomsRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Rooms, 1)
+}
+
+func TestUpdateRoomAnnouncesTopicChanges(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	_, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "room-1", UserID: "alice"})
+	require.NoError(t, err)
+	_, events, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1", DisplayName: "Bob"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, events, domain.EventUserJoined)
+
+	topic := "release planning"
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "bob", Topic: &topic})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	require.NoError(t, svc.SetRole(ctx, "room-1", "alice", "bob", domain.RoleModerator))
+	expectEvent(t, events, domain.EventRoleChanged)
+	updated, err := svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "bob", Topic: &topic})
+	require.NoError(t, err)
+	require.Equal(t, topic, updated.Topic)
+
+	ev := expectEvent(t, events, domain.EventTopicChanged)
+	require.Equal(t, "bob", ev.UserID)
+	require.Equal(t, "Bob", ev.DisplayName)
+	require.Equal(t, topic, ev.Content)
+
+	// Moderators may change the topic but not the other settings.
+	title := "Releases"
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "bob", Title: &title})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "alice", Title: &title})
+	require.NoError(t, err)
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "alice", Visibility: domain.Visibility(9)})
+	require.ErrorIs(t, err, ErrInvalidVisibility)
+	long := strings.Repeat("x", maxRoomTopicBytes+1)
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "room-1", UserID: "alice", Topic: &long})
+	require.ErrorIs(t, err, ErrRoomInfoTooLong)
+}
+
+func TestArchiveRoomClosesSessions(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, events, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	_, err = svc.ArchiveRoom(ctx, "room-1", "bob")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	archived, err := svc.ArchiveRoom(ctx, "room-1", "alice")
+	require.NoError(t, err)
+	require.True(t, archived.Archived)
+	require.Zero(t, archived.ParticipantCount)
+
+	var last domain.Event
+	for ev := range events {
+		last = ev
+	}
+	require.Equal(t, domain.EventSessionClosed, last.Type)
+	require.Equal(t, reasonArchived, last.Content)
+
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.ErrorIs(t, err, ErrRoomArchived)
+	_, err = svc.ArchiveRoom(ctx, "room-1", "alice")
+	require.ErrorIs(t, err, ErrRoomArchived)
+	_, err = svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "room-1", UserID: "alice"})
+	require.ErrorIs(t, err, ErrRoomExists)
+
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	require.True(t, room.Archived)
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const (
+	reasonAdmissionFailed = "could not load the room history"
+	reasonWaitReplaced    = "replaced by a newer wait for a seat"
+)
+
+// waiter is a connection queued for a seat in a full room. Its stream only receives queue
+// positions until it is admitted.
+type waiter struct {
+	req     domain.JoinRequest
+	session domain.Session
+	sub     *subscriber
+	// position is the last position announced to the waiter.
+	position int
+}
+
+// seatLimitLocked returns the participant limit of the room: the lower of its own limit and
+// the one the service applies to every room, or zero when neither is set.
+func (s *Service) seatLimitLocked(rm *room) int {
+	limit := rm.info.MaxParticipants
+	if s.roomParticipantLimit > 0 && (limit == 0 || s.roomParticipantLimit < limit) {
+		limit = s.roomParticipantLimit
+	}
+	return limit
+}
+
+// seatFreeLocked reports whether the room is below its participant limit.
+func (s *Service) seatFreeLocked(rm *room) bool {
+	limit := s.seatLimitLocked(rm)
+	return limit == 0 || len(rm.members) < limit
+}
+
+// exemptLocked reports whether a user enters the room regardless of its limit: users already
+// present only open another connection, and moderators must be able to step in.
+func (s *Service) exemptLocked(rm *room, userID string) bool {
+	if _, ok := rm.members[userID]; ok {
+		return true
+	}
+	return s.roleLocked(rm, userID) >= domain.RoleModerator
+}
+
+// hasSeatLocked reports whether a joining user may enter the room now. While users are
+// waiting for a seat, newcomers queue behind them even if a seat is free.
+func (s *Service) hasSeatLocked(rm *room, userID string) bool {
+	return s.exemptLocked(rm, userID) || (len(rm.queue) == 0 && s.seatFreeLocked(rm))
+}
+
+// waitLocked queues a join for a seat and returns the waiting session with its stream, which
+// receives an EventQueuePosition whenever the position changes and an EventAdmitted once the
+// user is seated. A user waits at most once per room: a newer wait, typically from a client
+// that reconnected, takes over the place of the earlier one, whose stream is closed.
+func (s *Service) waitLocked(rm *room, req domain.JoinRequest, displayName string) (domain.Session, <-chan domain.Event) {
+	w := &waiter{
+		req: req,
+		session: domain.Session{
+			ConnectionID: s.ids.NewID(),
+			UserID:       req.UserID,
+			DisplayName:  displayName,
+			RoomID:       req.RoomID,
+		},
+		// Leave room for the admission and its backlog behind the position updates.
+		sub: newSubscriber(s.bufSize + s.maxHistory + 1),
+	}
+	if i := waiterIndexLocked(rm, req.UserID); i >= 0 {
+		s.closeWaiter(rm.queue[i], reasonWaitReplaced)
+		rm.queue[i] = w
+	} else {
+		rm.queue = append(rm.queue, w)
+	}
+	s.announcePositionsLocked(rm)
+
+	session := w.session
+	session.QueuePosition = w.position
+	return session, w.sub.ch
+}
+
+// waiterIndexLocked returns the position in the queue of the user's wait, or -1.
+func waiterIndexLocked(rm *room, userID string) int {
+	return slices.IndexFunc(rm.queue, func(w *waiter) bool { return w.session.UserID == userID })
+}
+
+// seatWaitingLocked admits the users waiting for a seat, in the order they queued, while the
+// room has seats for them, and tells those still waiting their new position.
+func (s *Service) seatWaitingLocked(rm *room) {
+	for len(rm.queue) > 0 {
+		w := rm.queue[0]
+		if !s.exemptLocked(rm, w.session.UserID) && !s.seatFreeLocked(rm) {
+			break
+		}
+		rm.queue = rm.queue[1:]
+		s.admitWaiterLocked(rm, w)
+	}
+	s.announcePositionsLocked(rm)
+}
+
+// admitWaiterLocked seats a waiter as Join would have, except that its stream learns the
+// session from an EventAdmitted.
+func (s *Service) admitWaiterLocked(rm *room, w *waiter) {
+	// The join that queued is long gone, so the backlog is not bound to its context.
+	backlog, err := s.history(context.Background(), w.req)
+	if err != nil {
+		s.closeWaiter(w, reasonAdmissionFailed)
+		return
+	}
+
+	session := s.openSessionLocked(rm, w.session)
+	w.sub.ch <- domain.Event{
+		Type:      domain.EventAdmitted,
+		UserID:    session.UserID,
+		RoomID:    session.RoomID,
+		Timestamp: session.JoinedAt,
+		Session:   &session,
+	}
+	s.seatLocked(rm, session, w.sub, backlog)
+}
+
+// dequeueLocked removes a waiting connection from the queue and ends its stream. It reports
+// false when the connection is not waiting.
+func (s *Service) dequeueLocked(rm *room, connectionID string) bool {
+	for i, w := range rm.queue {
+		if w.session.ConnectionID == connectionID {
+			rm.queue = append(rm.queue[:i], rm.queue[i+1:]...)
+			close(w.sub.ch)
+			s.announcePositionsLocked(rm)
+			return true
+		}
+	}
+	return false
+}
+
+// dropWaitingLocked removes the waiting connections of a user, or of everyone when userID is
+// empty, closing their streams with the reason.
+func (s *Service) dropWaitingLocked(rm *room, userID, reason string) {
+	kept := rm.queue[:0]
+	for _, w := range rm.queue {
+		if userID == "" || w.session.UserID == userID {
+			s.closeWaiter(w, reason)
+			continue
+		}
+		kept = append(kept, w)
+	}
+	clear(rm.queue[len(kept):])
+	rm.queue = kept
+	s.announcePositionsLocked(rm)
+}
+
+func (s *Service) closeWaiter(w *waiter, reason string) {
+	closeSubscriber(w.sub, domain.Event{
+		Type:      domain.EventSessionClosed,
+		UserID:    w.session.UserID,
+		RoomID:    w.session.RoomID,
+		Content:   reason,
+		Timestamp: s.clock.Now(),
+	})
+}
+
+// announcePositionsLocked sends their new position to the waiters that moved up the queue.
+// Like typing signals, positions are not worth queueing: a waiter whose stream already holds
+// a full buffer misses the update.
+func (s *Service) announcePositionsLocked(rm *room) {
+	now := s.clock.Now()
+	for i, w := range rm.queue {
+		if w.position == i+1 {
+			continue
+		}
+		w.position = i + 1
+		if len(w.sub.ch) >= s.bufSize {
+			continue
+		}
+		w.sub.ch <- domain.Event{
+			Type:      domain.EventQueuePosition,
+			UserID:    w.session.UserID,
+			RoomID:    w.session.RoomID,
+			Count:     w.position,
+			Timestamp: now,
+		}
+	}
+}
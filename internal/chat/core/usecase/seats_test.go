@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipantLimits(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithRoomParticipantLimit(3), WithModerators("mod"))
+
+	_, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "small", UserID: "alice", MaxParticipants: -1})
+	require.ErrorIs(t, err, ErrInvalidParticipantLimit)
+	created, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "small", UserID: "alice", MaxParticipants: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, created.MaxParticipants)
+
+	// The room's own limit is lower than the one applied to every room.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "small"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "small"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "dave", RoomID: "small"})
+	require.ErrorIs(t, err, ErrRoomFull)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "dave", RoomID: "small", Wait: true})
+	require.ErrorIs(t, err, ErrRoomFull, "queueing is disabled")
+
+	// Present users may open more connections, and the owner and moderators always get in.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "small"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "small"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "mod", RoomID: "small"})
+	require.NoError(t, err)
+
+	// Lifting the room's limit leaves the one applied to every room.
+	unlimited := 0
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "small", UserID: "bob", MaxParticipants: &unlimited})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	updated, err := svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "small", UserID: "alice", MaxParticipants: &unlimited})
+	require.NoError(t, err)
+	require.Zero(t, updated.MaxParticipants)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "dave", RoomID: "small"})
+	require.ErrorIs(t, err, ErrRoomFull)
+
+	// Ad-hoc rooms are bound by it too, each on its own.
+	for _, user := range []string{"u1", "u2", "u3"} {
+		_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: user, RoomID: "adhoc"})
+		require.NoError(t, err)
+	}
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "u4", RoomID: "adhoc"})
+	require.ErrorIs(t, err, ErrRoomFull)
+}
+
+func TestJoinQueueAdmitsInOrder(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithJoinQueue(2))
+
+	_, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "stage", UserID: "alice", MaxParticipants: 1})
+	require.NoError(t, err)
+	bob, bobCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "stage"})
+	require.NoError(t, err)
+
+	carol, carolCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "stage", Wait: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, carol.QueuePosition)
+	require.Empty(t, carol.ResumeToken)
+	require.Equal(t, 1, expectEvent(t, carolCh, domain.EventQueuePosition).Count)
+
+	dave, daveCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "dave", RoomID: "stage", Wait: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, dave.QueuePosition)
+	require.Equal(t, 2, expectEvent(t, daveCh, domain.EventQueuePosition).Count)
+
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "erin", RoomID: "stage", Wait: true})
+	require.ErrorIs(t, err, ErrRoomFull, "the queue is full")
+	erin, erinCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "erin", RoomID: "stage"})
+	require.ErrorIs(t, err, ErrRoomFull)
+	require.Nil(t, erinCh)
+	require.Empty(t, erin.ConnectionID)
+
+	// A waiting user is not in the room yet.
+	_, err = svc.Broadcast(ctx, domain.Message{RoomID: "stage", UserID: "carol", Content: "hi"})
+	require.ErrorIs(t, err, ErrUserNotInRoom)
+
+	// The freed seat goes to the head of the queue, and the others move up.
+	require.NoError(t, svc.Leave(ctx, "stage", bob.ConnectionID))
+	for range bobCh {
+	}
+	admitted := expectEvent(t, carolCh, domain.EventAdmitted)
+	require.NotNil(t, admitted.Session)
+	require.Equal(t, carol.ConnectionID, admitted.Session.ConnectionID)
+	require.NotEmpty(t, admitted.Session.ResumeToken)
+	require.Equal(t, domain.RoleMember, admitted.Session.Role)
+	require.Equal(t, 1, expectEvent(t, daveCh, domain.EventQueuePosition).Count)
+
+	_, err = svc.Broadcast(ctx, domain.Message{RoomID: "stage", UserID: "carol", Content: "hi"})
+	require.NoError(t, err)
+	expectEvent(t, carolCh, domain.EventMessage)
+
+	// Raising the limit seats the waiting users right away.
+	limit := 2
+	_, err = svc.UpdateRoom(ctx, domain.UpdateRoomRequest{RoomID: "stage", UserID: "alice", MaxParticipants: &limit})
+	require.NoError(t, err)
+	expectEvent(t, daveCh, domain.EventAdmitted)
+	joined := expectEvent(t, carolCh, domain.EventUserJoined)
+	require.Equal(t, "dave", joined.UserID)
+}
+
+func TestJoinQueueDepartures(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithRoomParticipantLimit(1), WithJoinQueue(2))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "lobby"})
+	require.NoError(t, err)
+	bob, bobCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "lobby", Wait: true})
+	require.NoError(t, err)
+	expectEvent(t, bobCh, domain.EventQueuePosition)
+	_, carolCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "lobby", Wait: true})
+	require.NoError(t, err)
+	expectEvent(t, carolCh, domain.EventQueuePosition)
+
+	// Waiting again takes over the earlier wait, even with the queue full.
+	carolAgain, carolAgainCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "carol", RoomID: "lobby", Wait: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, carolAgain.QueuePosition)
+	closed := expectEvent(t, carolCh, domain.EventSessionClosed)
+	require.Equal(t, reasonWaitReplaced, closed.Content)
+	_, ok := <-carolCh
+	require.False(t, ok)
+	carolCh = carolAgainCh
+	require.Equal(t, 2, expectEvent(t, carolCh, domain.EventQueuePosition).Count)
+
+	// Giving up the wait ends the stream and moves the next user up.
+	require.NoError(t, svc.Leave(ctx, "lobby", bob.ConnectionID))
+	_, ok = <-bobCh
+	require.False(t, ok)
+	require.Equal(t, 1, expectEvent(t, carolCh, domain.EventQueuePosition).Count)
+
+	// Banning a waiting user drops them from the queue.
+	_, daveCh, err := svc.Join(ctx, domain.JoinRequest{UserID: "dave", RoomID: "lobby", Wait: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, expectEvent(t, daveCh, domain.EventQueuePosition).Count)
+	require.NoError(t, svc.Ban(ctx, "lobby", "alice", "carol", "", time.Hour))
+	closed = expectEvent(t, carolCh, domain.EventSessionClosed)
+	require.Equal(t, reasonBanned, closed.Content)
+	require.Equal(t, 1, expectEvent(t, daveCh, domain.EventQueuePosition).Count)
+
+	// Archiving the room closes the queue.
+	_, err = svc.ArchiveRoom(ctx, "lobby", "alice")
+	require.NoError(t, err)
+	closed = expectEvent(t, daveCh, domain.EventSessionClosed)
+	require.Equal(t, reasonArchived, closed.Content)
+	_, ok = <-daveCh
+	require.False(t, ok)
+}
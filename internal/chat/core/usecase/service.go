@@ -2,10 +2,16 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/lechitz/chat-grpc/internal/chat/core/ports/output"
+	"github.com/lechitz/chat-grpc/internal/platform/logger"
+	portslogger "github.com/lechitz/chat-grpc/internal/platform/ports/logger"
 )
 
 // Clock abstracts time generation to ease testing.
@@ -17,20 +23,181 @@ type realClock struct{}
 
 func (realClock) Now() time.Time { return time.Now().UTC() }
 
+// IDGenerator produces the unique identifiers assigned to events and connections.
+type IDGenerator interface {
+	NewID() string
+}
+
+// randomIDs draws IDs from crypto/rand. The service always uses it for its secrets, the
+// resume and invite tokens that grant access, whatever generator it is given for other IDs.
+type randomIDs struct{}
+
+func (randomIDs) NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// room holds the participants of a chat room. Its fields are guarded by mu; a room that
+// emptied is marked closed and removed from the registry, unless it is persistent, and
+// callers that raced with the removal look it up again.
+//
+// A user may be connected to a room from several devices at once. Sessions, subscribers and
+// detached timers are keyed by connection ID, while members tracks presence per user.
 type room struct {
+	mu          sync.Mutex
+	id          string
+	closed      bool
 	sessions    map[string]domain.Session
-	subscribers map[string]chan domain.Event
+	subscribers map[string]*subscriber
+	members     map[string]*member
+	// owner is the user holding RoleOwner; roles holds the other roles assigned in the room,
+	// keyed by user ID, and outlives the presence of the users.
+	owner string
+	roles map[string]domain.Role
+	// info is the room's metadata; it is set by the explicit creation or the first join.
+	info domain.RoomInfo
+	// password guards AccessPassword rooms and is nil under the other policies.
+	password *roomPassword
+	// invites holds the invites that may still be used, keyed by token; admitted holds the
+	// users a restricted room let in since its access settings last changed.
+	invites  map[string]*domain.Invite
+	admitted map[string]bool
+	// queue holds the connections waiting for a seat in the full room, first come first.
+	queue []*waiter
+	// typing holds the users currently typing, keyed by user ID, and typingAnnounced when the
+	// latest start of each present user was announced.
+	typing          map[string]*typingState
+	typingAnnounced map[string]time.Time
+	// detached holds the expiry timers of sessions whose connection dropped.
+	detached map[string]*time.Timer
+	// sent maps the recent request IDs of each user to the message they produced; sentOrder
+	// keeps them in the order they were recorded, for expiry.
+	sent      map[string]*sentRequest
+	sentOrder []*sentRequest
+	// seq is the sequence assigned to the latest event of the room.
+	seq uint64
+	// seqLoaded reports whether seq already accounts for the messages in the store.
+	seqLoaded bool
+}
+
+func newRoom(roomID string, seq uint64) *room {
+	return &room{
+		id:              roomID,
+		sessions:        make(map[string]domain.Session),
+		subscribers:     make(map[string]*subscriber),
+		members:         make(map[string]*member),
+		roles:           make(map[string]domain.Role),
+		invites:         make(map[string]*domain.Invite),
+		admitted:        make(map[string]bool),
+		typing:          make(map[string]*typingState),
+		typingAnnounced: make(map[string]time.Time),
+		detached:        make(map[string]*time.Timer),
+		sent:            make(map[string]*sentRequest),
+		seq:             seq,
+	}
+}
+
+// member is a user present in a room through one or more connections.
+type member struct {
+	// profile is the session of the user's first connection; it names the user in presence
+	// events and room queries.
+	profile     domain.Session
+	connections int
+}
+
+// retiredRoom is the sequence a room had reached when it was removed.
+type retiredRoom struct {
+	id  string
+	seq uint64
+}
+
+// sessionRef locates a session from its resume token.
+type sessionRef struct {
+	roomID       string
+	connectionID string
 }
 
 // Service orchestrates in-memory chat rooms.
+//
+// Every event produced for a room receives a unique ID and the next value of the room's
+// sequence. Sequences are assigned and fanned out under the room's own lock, so each
+// participant observes the events of a room in strictly increasing sequence order; no
+// ordering is guaranteed across rooms. The registry lock only guards the room index, so
+// busy rooms do not contend with each other.
+//
+// Locks are acquired in the order room, registry, tokens; the registry lock is never held
+// while waiting for a room. The restrictions and idle locks are only taken on their own or
+// while holding a room. Direct messages take the lock of their recipient, held across the
+// store calls of an offline user's queue, and then briefly the inbox lock; neither is held
+// together with the other locks.
 type Service struct {
-	mu      sync.RWMutex
-	rooms   map[string]*room
-	clock   Clock
-	bufSize int
+	mu         sync.RWMutex
+	rooms      map[string]*room
+	clock      Clock
+	ids        IDGenerator
+	secrets    IDGenerator
+	log        portslogger.ContextLogger
+	bufSize    int
+	store      output.MessageStore
+	maxHistory int
+	// retired remembers the last sequence of rooms removed when they emptied, so a room
+	// recreated under the same ID keeps counting upwards. retiredOrder keeps them in the
+	// order they were removed, so only the latest maxRetiredRooms are remembered.
+	retired      map[string]*retiredRoom
+	retiredOrder []*retiredRoom
+	// adHocRooms lets a join create the room it names when it does not exist yet.
+	adHocRooms bool
+	// roomParticipantLimit caps the users of each room separately; zero leaves rooms to their
+	// own limit.
+	roomParticipantLimit int
+	// queueSize is how many joins may wait for a seat in a full room; zero turns them away.
+	queueSize    int
+	tokensMu     sync.Mutex
+	tokens       map[string]sessionRef
+	resumeGrace  time.Duration
+	policy       SlowConsumerPolicy
+	blockTimeout time.Duration
+	// typingTimeout is how long a typing user may stay silent before the stop is announced.
+	typingTimeout time.Duration
+	// idleTimeout evicts connections without activity for that long; zero disables eviction.
+	idleTimeout time.Duration
+	// idle watches the activity of each connection, keyed by connection ID, when an idle
+	// timeout is configured. It is kept apart from the rooms so a stream following several
+	// rooms records its activity under a single lock.
+	idleMu sync.Mutex
+	idle   map[string]*idleWatch
+	// moderators hold at least RoleModerator in every room.
+	moderators map[string]bool
+	// bans and mutes hold when the sanctions of each user of a room expire. They outlive the
+	// room, so a ban still applies after it empties.
+	restrictMu sync.Mutex
+	bans       map[restrictionKey]time.Time
+	mutes      map[restrictionKey]time.Time
+	// dedupWindow is how long a client request ID is remembered; zero disables de-duplication.
+	dedupWindow time.Duration
+	// inboxes holds the direct message subscribers of each online user, keyed by inbox ID.
+	inboxMu sync.Mutex
+	inboxes map[string]map[string]*subscriber
+	// recipients serialises the direct messages sent to each user with the inboxes they
+	// open, so queueing for one offline user never holds up the others.
+	recipientsMu sync.Mutex
+	recipients   map[string]*recipientLock
+	directs      output.DirectMessageStore
+	// directLimit caps the messages queued for an offline user, and directTTL discards those
+	// queued for longer; zero leaves either unbounded.
+	directLimit int
+	directTTL   time.Duration
 }
 
-const defaultBufferSize = 32
+const (
+	defaultBufferSize = 32
+	// minBufferSize leaves room for an EventMissed notice next to the event it precedes.
+	minBufferSize     = 2
+	defaultMaxHistory = 100
+	// maxRetiredRooms bounds how many removed rooms have their sequence remembered.
+	maxRetiredRooms = 4096
+)
 
 // Option customises the service behaviour.
 type Option func(*Service)
@@ -44,11 +211,172 @@ func WithClock(clock Clock) Option {
 	}
 }
 
-// WithBufferSize overrides the per-subscriber buffer size.
+// WithIDGenerator overrides the generator of event, connection and inbox IDs. Resume and
+// invite tokens are not affected.
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(s *Service) {
+		if ids != nil {
+			s.ids = ids
+		}
+	}
+}
+
+// WithLogger reports the failures the service recovers from, such as a reply count that
+// could not be stored. The service logs nothing by default.
+func WithLogger(log portslogger.ContextLogger) Option {
+	return func(s *Service) {
+		if log != nil {
+			s.log = log
+		}
+	}
+}
+
+// WithBufferSize overrides the per-subscriber buffer size. Sizes below two are raised to
+// two, so that a dropped-events notice and the event after it always fit together.
 func WithBufferSize(size int) Option {
 	return func(s *Service) {
 		if size > 0 {
-			s.bufSize = size
+			s.bufSize = max(size, minBufferSize)
+		}
+	}
+}
+
+// WithMessageStore persists every broadcast message before it is fanned out.
+func WithMessageStore(store output.MessageStore) Option {
+	return func(s *Service) {
+		if store != nil {
+			s.store = store
+		}
+	}
+}
+
+// WithDirectMessageStore queues the direct messages of offline users instead of rejecting them.
+func WithDirectMessageStore(store output.DirectMessageStore) Option {
+	return func(s *Service) {
+		if store != nil {
+			s.directs = store
+		}
+	}
+}
+
+// WithDirectQueueLimits bounds the queue of an offline user: sends beyond limit queued
+// messages are rejected with ErrDirectQueueFull, and messages queued for longer than ttl are
+// discarded. Zero leaves either unbounded.
+func WithDirectQueueLimits(limit int, ttl time.Duration) Option {
+	return func(s *Service) {
+		if limit >= 0 {
+			s.directLimit = limit
+		}
+		if ttl >= 0 {
+			s.directTTL = ttl
+		}
+	}
+}
+
+// WithMaxHistoryReplay caps how many stored messages a join may replay; zero disables replay.
+func WithMaxHistoryReplay(limit int) Option {
+	return func(s *Service) {
+		if limit >= 0 {
+			s.maxHistory = limit
+		}
+	}
+}
+
+// WithResumeGrace sets how long a dropped session is kept for resumption. Until the grace
+// period ends the room is not told the user left. Zero, the default, disables resume.
+func WithResumeGrace(grace time.Duration) Option {
+	return func(s *Service) {
+		if grace >= 0 {
+			s.resumeGrace = grace
+		}
+	}
+}
+
+// WithSlowConsumerPolicy selects how events are handled when a subscriber's buffer is full.
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) Option {
+	return func(s *Service) {
+		s.policy = policy
+	}
+}
+
+// WithBlockTimeout bounds how long the Block policy waits for a subscriber.
+func WithBlockTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		if timeout > 0 {
+			s.blockTimeout = timeout
+		}
+	}
+}
+
+// WithTypingTimeout sets how long after the last typing signal a user is considered to have
+// stopped typing.
+func WithTypingTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		if timeout > 0 {
+			s.typingTimeout = timeout
+		}
+	}
+}
+
+// WithModerators makes the given users moderators of every room, whatever role the room
+// assigned them.
+func WithModerators(userIDs ...string) Option {
+	return func(s *Service) {
+		for _, userID := range userIDs {
+			if userID != "" {
+				s.moderators[userID] = true
+			}
+		}
+	}
+}
+
+// WithDedupWindow sets how long the request ID of a broadcast message is remembered, so a
+// client retrying the request gets the original message back instead of sending a copy;
+// zero disables de-duplication.
+func WithDedupWindow(window time.Duration) Option {
+	return func(s *Service) {
+		if window >= 0 {
+			s.dedupWindow = window
+		}
+	}
+}
+
+// WithIdleTimeout evicts connections that show no activity, such as heartbeats, for the
+// given duration; zero disables eviction.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		if timeout >= 0 {
+			s.idleTimeout = timeout
+		}
+	}
+}
+
+// WithAdHocRooms controls whether joining an unknown room creates it. When disabled, rooms
+// must be created with CreateRoom before they can be joined.
+func WithAdHocRooms(enabled bool) Option {
+	return func(s *Service) {
+		s.adHocRooms = enabled
+	}
+}
+
+// WithRoomParticipantLimit sets the participant limit applied to every room on its own, not
+// a bound on the users across rooms; rooms may set a lower limit of their own. Zero leaves
+// rooms to their own limit.
+func WithRoomParticipantLimit(limit int) Option {
+	return func(s *Service) {
+		if limit >= 0 {
+			s.roomParticipantLimit = limit
+		}
+	}
+}
+
+// WithJoinQueue lets up to size joins per room wait for a seat when the room is full and the
+// join asks to wait; they are admitted in the order they queued as seats free up. Zero
+// rejects them like any other join to a full room.
+func WithJoinQueue(size int) Option {
+	return func(s *Service) {
+		if size >= 0 {
+			s.queueSize = size
 		}
 	}
 }
@@ -56,9 +384,26 @@ func WithBufferSize(size int) Option {
 // NewService creates a new in-memory chat service instance.
 func NewService(opts ...Option) *Service {
 	svc := &Service{
-		rooms:   make(map[string]*room),
-		clock:   realClock{},
-		bufSize: defaultBufferSize,
+		rooms:         make(map[string]*room),
+		clock:         realClock{},
+		ids:           randomIDs{},
+		secrets:       randomIDs{},
+		log:           logger.NoopLogger{},
+		bufSize:       defaultBufferSize,
+		maxHistory:    defaultMaxHistory,
+		retired:       make(map[string]*retiredRoom),
+		adHocRooms:    true,
+		tokens:        make(map[string]sessionRef),
+		policy:        DropNewest,
+		blockTimeout:  defaultBlockTimeout,
+		typingTimeout: defaultTypingTimeout,
+		dedupWindow:   defaultDedupWindow,
+		idle:          make(map[string]*idleWatch),
+		inboxes:       make(map[string]map[string]*subscriber),
+		recipients:    make(map[string]*recipientLock),
+		moderators:    make(map[string]bool),
+		bans:          make(map[restrictionKey]time.Time),
+		mutes:         make(map[restrictionKey]time.Time),
 	}
 	for _, opt := range opts {
 		opt(svc)
@@ -66,8 +411,17 @@ func NewService(opts ...Option) *Service {
 	return svc
 }
 
-// Join registers a user in the requested room and returns a session plus the event stream.
-func (s *Service) Join(_ context.Context, req domain.JoinRequest) (domain.Session, <-chan domain.Event, error) {
+// Join opens a new connection of a user to the requested room and returns its session plus
+// the event stream. When the request asks for history, the stream starts with the replayed
+// messages, so they are always delivered before any live event. A user may join the same
+// room from several connections; the room is only notified when the first one joins. Joining
+// an unknown room creates it unless ad-hoc rooms are disabled; archived rooms cannot be
+// joined, and rooms that are not public take their password or an invite.
+//
+// A room at its participant limit rejects new users with ErrRoomFull, unless the request
+// asks to wait and the room's queue has space: the returned session then carries its
+// QueuePosition and the stream announces the position until an EventAdmitted seats the user.
+func (s *Service) Join(ctx context.Context, req domain.JoinRequest) (domain.Session, <-chan domain.Event, error) {
 	if req.RoomID == "" || req.UserID == "" {
 		return domain.Session{}, nil, ErrEmptyFields
 	}
@@ -77,147 +431,420 @@ func (s *Service) Join(_ context.Context, req domain.JoinRequest) (domain.Sessio
 		displayName = req.UserID
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	rm, ok := s.lockRoom(req.RoomID, s.adHocRooms)
+	if !ok {
+		return domain.Session{}, nil, ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
 
-	rm := s.ensureRoom(req.RoomID)
-	if _, exists := rm.sessions[req.UserID]; exists {
-		return domain.Session{}, nil, ErrAlreadyJoined
+	if rm.info.Archived {
+		return domain.Session{}, nil, ErrRoomArchived
+	}
+	if s.restricted(s.bans, req.RoomID, req.UserID) {
+		s.retireIfEmptyLocked(rm)
+		return domain.Session{}, nil, ErrUserBanned
+	}
+	invite, err := s.admitLocked(rm, req)
+	if err != nil {
+		s.retireIfEmptyLocked(rm)
+		return domain.Session{}, nil, err
+	}
+	if !s.hasSeatLocked(rm, req.UserID) {
+		if !req.Wait || (waiterIndexLocked(rm, req.UserID) < 0 && len(rm.queue) >= s.queueSize) {
+			return domain.Session{}, nil, ErrRoomFull
+		}
+		admitUserLocked(rm, req.UserID, invite)
+		session, events := s.waitLocked(rm, req, displayName)
+		return session, events, nil
 	}
 
-	session := domain.Session{
-		UserID:      req.UserID,
-		DisplayName: displayName,
-		RoomID:      req.RoomID,
-		JoinedAt:    s.clock.Now(),
+	backlog, err := s.history(ctx, req)
+	if err != nil {
+		s.retireIfEmptyLocked(rm)
+		return domain.Session{}, nil, err
+	}
+	if err := s.loadSequenceLocked(ctx, rm); err != nil {
+		s.retireIfEmptyLocked(rm)
+		return domain.Session{}, nil, err
 	}
-	eventCh := make(chan domain.Event, s.bufSize)
 
-	rm.sessions[req.UserID] = session
-	rm.subscribers[req.UserID] = eventCh
+	// The user who creates the room owns it.
+	if rm.info.CreatedBy == "" {
+		rm.owner = req.UserID
+		rm.info = domain.RoomInfo{
+			CreatedBy:  req.UserID,
+			CreatedAt:  s.clock.Now(),
+			Visibility: domain.VisibilityPublic,
+			Access:     domain.AccessPublic,
+		}
+	}
+	admitUserLocked(rm, req.UserID, invite)
+
+	session := s.openSessionLocked(rm, domain.Session{
+		ConnectionID: s.ids.NewID(),
+		UserID:       req.UserID,
+		DisplayName:  displayName,
+		RoomID:       req.RoomID,
+	})
+	sub := newSubscriber(s.bufSize + len(backlog))
+	s.seatLocked(rm, session, sub, backlog)
+	return session, sub.ch, nil
+}
+
+// openSessionLocked stamps a new session with its join time, role and resume token.
+func (s *Service) openSessionLocked(rm *room, session domain.Session) domain.Session {
+	session.JoinedAt = s.clock.Now()
+	session.Role = s.roleLocked(rm, session.UserID)
+	session.ResumeToken = s.issueTokenLocked(session)
+	return session
+}
+
+// seatLocked replays the backlog on the stream of a new connection, registers it with the
+// room and notifies the room when the user was not present yet.
+func (s *Service) seatLocked(rm *room, session domain.Session, sub *subscriber, backlog []domain.Message) {
+	for _, msg := range backlog {
+		ev := messageEvent(msg)
+		ev.Replayed = true
+		sub.ch <- ev
+	}
+
+	rm.sessions[session.ConnectionID] = session
+	rm.subscribers[session.ConnectionID] = sub
+	s.watchIdleLocked(rm, session.ConnectionID)
 
-	s.enqueueLocked(req.RoomID, domain.Event{
+	m, present := rm.members[session.UserID]
+	if !present {
+		m = &member{profile: session}
+		rm.members[session.UserID] = m
+	}
+	m.connections++
+	if present {
+		return
+	}
+
+	s.enqueueLocked(rm, domain.Event{
 		Type:        domain.EventUserJoined,
 		UserID:      session.UserID,
 		DisplayName: session.DisplayName,
 		RoomID:      session.RoomID,
 		Timestamp:   session.JoinedAt,
-	}, req.UserID)
-
-	return session, eventCh, nil
+	}, session.ConnectionID)
 }
 
-// Leave unregisters the user and notifies remaining participants.
-func (s *Service) Leave(_ context.Context, roomID, userID string) error {
-	if roomID == "" || userID == "" {
+// Leave closes one connection of a room. The remaining participants are notified once the
+// user's last connection leaves. A connection still waiting for a seat just leaves the queue.
+func (s *Service) Leave(_ context.Context, roomID, connectionID string) error {
+	if roomID == "" || connectionID == "" {
 		return ErrEmptyFields
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	rm, ok := s.rooms[roomID]
+	rm, ok := s.lockRoom(roomID, false)
 	if !ok {
 		return ErrRoomNotFound
 	}
+	defer rm.mu.Unlock()
 
-	session, ok := rm.sessions[userID]
-	if !ok {
+	if s.dequeueLocked(rm, connectionID) {
+		return nil
+	}
+	if _, ok := rm.sessions[connectionID]; !ok {
 		return ErrUserNotInRoom
 	}
 
-	ch := rm.subscribers[userID]
-	delete(rm.sessions, userID)
-	delete(rm.subscribers, userID)
-	close(ch)
+	s.leaveLocked(rm, connectionID, "")
+	return nil
+}
 
-	s.enqueueLocked(roomID, domain.Event{
-		Type:        domain.EventUserLeft,
-		UserID:      session.UserID,
-		DisplayName: session.DisplayName,
-		RoomID:      session.RoomID,
-		Timestamp:   s.clock.Now(),
-	}, userID)
+// leaveLocked removes a connection, notifies the remaining participants when it was the
+// user's last one, seating the next user waiting for the freed seat, and drops the room once
+// it is empty, unless it is persistent. A non-empty reason means the server closed the
+// session; the connection then receives it in a final EventSessionClosed before its stream
+// ends, and the EventUserLeft notice carries it as its content.
+func (s *Service) leaveLocked(rm *room, connectionID, reason string) {
+	session := rm.sessions[connectionID]
+	sub := rm.subscribers[connectionID]
+	s.forgetResumeLocked(rm, session)
+	s.unwatchIdle(connectionID)
+	delete(rm.sessions, connectionID)
+	delete(rm.subscribers, connectionID)
+
+	if reason == "" {
+		close(sub.ch)
+	} else {
+		closeSubscriber(sub, domain.Event{
+			Type:      domain.EventSessionClosed,
+			UserID:    session.UserID,
+			RoomID:    session.RoomID,
+			Content:   reason,
+			Timestamp: s.clock.Now(),
+		})
+	}
 
-	if len(rm.sessions) == 0 {
-		delete(s.rooms, roomID)
+	m := rm.members[session.UserID]
+	m.connections--
+	if m.connections == 0 {
+		s.stopTypingLocked(rm, session.UserID, false)
+		delete(rm.typingAnnounced, session.UserID)
+		delete(rm.members, session.UserID)
+		s.enqueueLocked(rm, domain.Event{
+			Type:        domain.EventUserLeft,
+			UserID:      m.profile.UserID,
+			DisplayName: m.profile.DisplayName,
+			RoomID:      m.profile.RoomID,
+			Content:     reason,
+			Timestamp:   s.clock.Now(),
+		}, "")
+		if session.UserID == rm.owner {
+			s.passOwnershipLocked(rm)
+		}
+		s.seatWaitingLocked(rm)
 	}
 
-	return nil
+	s.retireIfEmptyLocked(rm)
 }
 
-// Broadcast delivers a message to all participants in the room.
-func (s *Service) Broadcast(_ context.Context, msg domain.Message) error {
+// Broadcast stamps the message with an ID and the room's next sequence, persists it when a
+// store is configured, delivers it to all participants in the room and returns it. The store
+// write stays under the room lock: the store only accepts a room's messages in sequence
+// order, and no participant may see a message that could still fail to persist, so the lock
+// is what keeps sequence, storage and fan-out in the same order. A message
+// carrying a request ID the user already sent within the de-duplication window is not sent
+// again; the original message is returned instead. A message with ReplyTo joins that
+// message's thread, whose root then announces its new reply count with an EventThreadUpdated;
+// a count that cannot be stored is logged rather than failing the delivered reply.
+func (s *Service) Broadcast(ctx context.Context, msg domain.Message) (domain.Message, error) {
 	if msg.RoomID == "" || msg.UserID == "" {
-		return ErrEmptyFields
+		return domain.Message{}, ErrEmptyFields
 	}
 	if msg.Content == "" {
-		return ErrEmptyMessage
+		return domain.Message{}, ErrEmptyMessage
 	}
 
-	s.mu.RLock()
-	rm, ok := s.rooms[msg.RoomID]
+	rm, ok := s.lockRoom(msg.RoomID, false)
 	if !ok {
-		s.mu.RUnlock()
-		return ErrRoomNotFound
+		return domain.Message{}, ErrRoomNotFound
 	}
+	defer rm.mu.Unlock()
 
-	session, ok := rm.sessions[msg.UserID]
+	m, ok := rm.members[msg.UserID]
 	if !ok {
-		s.mu.RUnlock()
-		return ErrUserNotInRoom
+		return domain.Message{}, ErrUserNotInRoom
 	}
+	if sent, ok := s.sentLocked(rm, msg.UserID, msg.RequestID); ok {
+		return sent, nil
+	}
+	if s.restricted(s.mutes, msg.RoomID, msg.UserID) {
+		return domain.Message{}, ErrUserMuted
+	}
+	if err := s.authorizeLocked(rm, msg.UserID, domain.PermissionSend); err != nil {
+		return domain.Message{}, err
+	}
+	session := m.profile
 
-	event := domain.Event{
-		Type:        domain.EventMessage,
+	var root domain.Message
+	if msg.ReplyTo != "" {
+		var err error
+		if root, err = s.threadRoot(ctx, msg.RoomID, msg.ReplyTo); err != nil {
+			return domain.Message{}, err
+		}
+		if root.Deleted {
+			return domain.Message{}, ErrMessageNotFound
+		}
+	}
+
+	stored := domain.Message{
+		ID:          s.ids.NewID(),
 		UserID:      session.UserID,
 		DisplayName: session.DisplayName,
 		RoomID:      session.RoomID,
 		Content:     msg.Content,
-		Timestamp:   s.clock.Now(),
+		SentAt:      s.clock.Now(),
+		Sequence:    rm.seq + 1,
+		ReplyTo:     root.ID,
+	}
+	if s.store != nil {
+		if err := s.store.Append(ctx, stored); err != nil {
+			return domain.Message{}, fmt.Errorf(errFmtAppendMessage, err)
+		}
+	}
+
+	rm.seq = stored.Sequence
+	s.rememberSentLocked(rm, msg.RequestID, stored)
+	s.stopTypingLocked(rm, msg.UserID, true)
+	s.fanOutLocked(rm, messageEvent(stored), "")
+
+	// The reply is stored and delivered by now, so failing to count it must not fail the
+	// broadcast: the client would retry a message everyone already received.
+	if root.ID != "" {
+		if err := s.countReplyLocked(ctx, rm, root, stored); err != nil {
+			s.log.WarnwCtx(ctx, logMsgCountReplyFailure, logFieldRoom, rm.id, logFieldMessage, root.ID, logFieldError, err)
+		}
 	}
+	return stored, nil
+}
 
-	channels := make([]chan domain.Event, 0, len(rm.subscribers))
-	for _, ch := range rm.subscribers {
-		channels = append(channels, ch)
+// history loads the backlog requested on join, capped by the configured maximum.
+func (s *Service) history(ctx context.Context, req domain.JoinRequest) ([]domain.Message, error) {
+	if s.store == nil || s.maxHistory == 0 {
+		return nil, nil
+	}
+	if req.HistoryLimit <= 0 && req.HistorySince.IsZero() {
+		return nil, nil
 	}
-	s.mu.RUnlock()
 
-	for _, ch := range channels {
-		select {
-		case ch <- event:
-		default:
+	limit := s.maxHistory
+	if req.HistoryLimit > 0 && req.HistoryLimit < limit {
+		limit = req.HistoryLimit
+	}
+
+	if req.HistorySince.IsZero() {
+		msgs, err := s.store.Latest(ctx, req.RoomID, limit)
+		if err != nil {
+			return nil, fmt.Errorf(errFmtLoadHistory, err)
 		}
+		return msgs, nil
 	}
 
-	return nil
+	msgs, err := s.store.RangeByTime(ctx, req.RoomID, req.HistorySince, time.Time{}, 0)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtLoadHistory, err)
+	}
+	if len(msgs) > limit {
+		msgs = msgs[len(msgs)-limit:]
+	}
+	return msgs, nil
 }
 
-func (s *Service) ensureRoom(roomID string) *room {
-	rm, ok := s.rooms[roomID]
-	if !ok {
-		rm = &room{
-			sessions:    make(map[string]domain.Session),
-			subscribers: make(map[string]chan domain.Event),
+// lockRoom returns the room with its lock held, creating it when create is set. It reports
+// false when the room does not exist and create is unset.
+func (s *Service) lockRoom(roomID string, create bool) (*room, bool) {
+	for {
+		s.mu.RLock()
+		rm, ok := s.rooms[roomID]
+		s.mu.RUnlock()
+
+		if !ok {
+			if !create {
+				return nil, false
+			}
+			s.mu.Lock()
+			if rm, ok = s.rooms[roomID]; !ok {
+				var seq uint64
+				if retired, ok := s.retired[roomID]; ok {
+					seq = retired.seq
+					delete(s.retired, roomID)
+				}
+				rm = newRoom(roomID, seq)
+				s.rooms[roomID] = rm
+			}
+			s.mu.Unlock()
+		}
+
+		rm.mu.Lock()
+		if !rm.closed {
+			return rm, true
 		}
-		s.rooms[roomID] = rm
+		// The room emptied while we waited for it; look it up again.
+		rm.mu.Unlock()
+	}
+}
+
+// loadSequenceLocked seeds a new room's sequence from the store, so a room recreated
+// after a restart keeps counting upwards.
+func (s *Service) loadSequenceLocked(ctx context.Context, rm *room) error {
+	if rm.seqLoaded || s.store == nil {
+		return nil
+	}
+	last, err := s.store.LastSequence(ctx, rm.id)
+	if err != nil {
+		return fmt.Errorf(errFmtLoadSequence, err)
+	}
+	rm.seq = max(rm.seq, last)
+	rm.seqLoaded = true
+	return nil
+}
+
+// retireIfEmptyLocked removes a room without sessions from the registry, remembering its
+// sequence for the next room created under the same ID. Persistent rooms are kept, and so
+// are rooms with users waiting for a seat.
+//
+// Only the latest maxRetiredRooms removals are remembered. A room recreated after its
+// sequence was forgotten resumes from the last sequence in the store, which only misses the
+// presence events sent after its last message.
+func (s *Service) retireIfEmptyLocked(rm *room) {
+	if len(rm.sessions) > 0 || len(rm.queue) > 0 || rm.info.Persistent {
+		return
+	}
+	rm.closed = true
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rooms[rm.id] == rm {
+		delete(s.rooms, rm.id)
+		s.rememberRetiredLocked(rm)
 	}
-	return rm
 }
 
-// enqueueLocked broadcasts an event to a room while holding the global lock.
-func (s *Service) enqueueLocked(roomID string, event domain.Event, excludeUser string) {
-	rm := s.rooms[roomID]
-	if rm == nil {
+// rememberRetiredLocked records the sequence of a removed room, forgetting the oldest
+// removal beyond maxRetiredRooms. Rooms recreated since their removal leave stale entries
+// in retiredOrder, which are dropped without touching the newer ones.
+func (s *Service) rememberRetiredLocked(rm *room) {
+	retired := &retiredRoom{id: rm.id, seq: rm.seq}
+	s.retired[rm.id] = retired
+	s.retiredOrder = append(s.retiredOrder, retired)
+	if len(s.retiredOrder) <= maxRetiredRooms {
 		return
 	}
-	for uid, ch := range rm.subscribers {
-		if uid == excludeUser {
+	oldest := s.retiredOrder[0]
+	s.retiredOrder[0] = nil
+	s.retiredOrder = s.retiredOrder[1:]
+	if s.retired[oldest.id] == oldest {
+		delete(s.retired, oldest.id)
+	}
+}
+
+// enqueueLocked stamps a room event with its ID and sequence and broadcasts it while
+// holding the room lock.
+func (s *Service) enqueueLocked(rm *room, event domain.Event, excludeConn string) {
+	rm.seq++
+	event.ID = s.ids.NewID()
+	event.Sequence = rm.seq
+	s.fanOutLocked(rm, event, excludeConn)
+}
+
+// fanOutLocked delivers an already stamped event to every subscriber of the room and
+// disconnects the subscribers the slow-consumer policy gave up on.
+func (s *Service) fanOutLocked(rm *room, event domain.Event, excludeConn string) {
+	var slow []string
+	for connID, sub := range rm.subscribers {
+		if connID == excludeConn {
 			continue
 		}
-		select {
-		case ch <- event:
-		default:
+		if !s.deliverLocked(rm, connID, sub, event) {
+			slow = append(slow, connID)
+		}
+	}
+	for _, connID := range slow {
+		if _, ok := rm.sessions[connID]; ok {
+			s.leaveLocked(rm, connID, reasonSlowConsumer)
 		}
 	}
 }
+
+func messageEvent(msg domain.Message) domain.Event {
+	return domain.Event{
+		Type:        domain.EventMessage,
+		ID:          msg.ID,
+		Sequence:    msg.Sequence,
+		UserID:      msg.UserID,
+		DisplayName: msg.DisplayName,
+		RoomID:      msg.RoomID,
+		Content:     msg.Content,
+		Timestamp:   msg.SentAt,
+		EditedAt:    msg.EditedAt,
+		Deleted:     msg.Deleted,
+		Reactions:   msg.Reactions,
+		ReplyTo:     msg.ReplyTo,
+		ReplyCount:  msg.ReplyCount,
+	}
+}
@@ -2,6 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -29,28 +33,62 @@ func TestJoinCreatesSession(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, events)
 
+	require.NotEmpty(t, session.ResumeToken)
+	require.NotEmpty(t, session.ConnectionID)
+	session.ResumeToken = ""
+	session.ConnectionID = ""
 	require.Equal(t, domain.Session{
 		UserID:      "alice",
 		DisplayName: "alice",
 		RoomID:      "room-1",
 		JoinedAt:    clk.t,
+		Role:        domain.RoleOwner,
 	}, session)
 }
 
-func TestJoinDuplicateFails(t *testing.T) {
+func TestJoinFromSeveralConnections(t *testing.T) {
+	ctx := context.Background()
 	svc := NewService()
 
-	_, _, err := svc.Join(context.Background(), domain.JoinRequest{
-		UserID: "alice",
-		RoomID: "room-1",
-	})
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
 	require.NoError(t, err)
 
-	_, _, err = svc.Join(context.Background(), domain.JoinRequest{
-		UserID: "alice",
-		RoomID: "room-1",
-	})
-	require.ErrorIs(t, err, ErrAlreadyJoined)
+	laptop, chLaptop, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1", DisplayName: "Alice"})
+	require.NoError(t, err)
+	phone, chPhone, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.NotEqual(t, laptop.ConnectionID, phone.ConnectionID)
+
+	// Bob hears about alice once, and her laptop does not hear about her phone.
+	joined := expectEvent(t, chBob, domain.EventUserJoined)
+	require.Equal(t, "Alice", joined.DisplayName)
+	require.Empty(t, chBob)
+	require.Empty(t, chLaptop)
+
+	room, err := svc.GetRoom(ctx, "room-1", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, room.ParticipantCount)
+
+	// Every connection receives the room's messages.
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "hi"})
+	for _, ch := range []<-chan domain.Event{chBob, chLaptop, chPhone} {
+		msg := expectEvent(t, ch, domain.EventMessage)
+		require.Equal(t, "Alice", msg.DisplayName)
+	}
+
+	// Closing one connection keeps alice in the room; closing the last one notifies bob.
+	require.NoError(t, svc.Leave(ctx, "room-1", laptop.ConnectionID))
+	_, ok := <-chLaptop
+	require.False(t, ok, "laptop channel should be closed")
+	require.Empty(t, chBob)
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "still here"})
+	expectEvent(t, chBob, domain.EventMessage)
+	expectEvent(t, chPhone, domain.EventMessage)
+
+	require.NoError(t, svc.Leave(ctx, "room-1", phone.ConnectionID))
+	left := expectEvent(t, chBob, domain.EventUserLeft)
+	require.Equal(t, "alice", left.UserID)
+	require.ErrorIs(t, svc.Leave(ctx, "room-1", phone.ConnectionID), ErrUserNotInRoom)
 }
 
 func TestBroadcastDeliversToParticipants(t *testing.T) {
@@ -74,7 +112,7 @@ func TestBroadcastDeliversToParticipants(t *testing.T) {
 	// Drain join notification sent to Alice about Bob joining.
 	expectEvent(t, chAlice, domain.EventUserJoined)
 
-	err = svc.Broadcast(context.Background(), domain.Message{
+	_, err = svc.Broadcast(context.Background(), domain.Message{
 		UserID:  "alice",
 		RoomID:  "room-1",
 		Content: "hello world",
@@ -91,11 +129,160 @@ func TestBroadcastDeliversToParticipants(t *testing.T) {
 	require.Equal(t, "alice", evBob.UserID)
 }
 
+func TestBroadcastPersistsToStore(t *testing.T) {
+	clk := fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	store := &recordingStore{}
+	svc := NewService(WithClock(clk), WithIDGenerator(&sequentialIDs{}), WithMessageStore(store))
+
+	session, _, err := svc.Join(context.Background(), domain.JoinRequest{
+		UserID:      "alice",
+		RoomID:      "room-1",
+		DisplayName: "Alice",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "id-1", session.ConnectionID)
+	require.NotContains(t, session.ResumeToken, "id-", "resume tokens do not come from the ID generator")
+
+	_, err = svc.Broadcast(context.Background(), domain.Message{
+		UserID:  "alice",
+		RoomID:  "room-1",
+		Content: "hello world",
+	})
+	require.NoError(t, err)
+
+	// The connection and the join notice took the first IDs.
+	require.Equal(t, []domain.Message{{
+		ID:          "id-3",
+		UserID:      "alice",
+		DisplayName: "Alice",
+		RoomID:      "room-1",
+		Content:     "hello world",
+		SentAt:      clk.t,
+		Sequence:    2,
+	}}, store.appended)
+}
+
+func TestBroadcastStoreFailureSkipsFanOut(t *testing.T) {
+	store := &recordingStore{err: errors.New("disk full")}
+	svc := NewService(WithMessageStore(store))
+
+	_, ch, err := svc.Join(context.Background(), domain.JoinRequest{
+		UserID: "alice",
+		RoomID: "room-1",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.Broadcast(context.Background(), domain.Message{
+		UserID:  "alice",
+		RoomID:  "room-1",
+		Content: "hello world",
+	})
+	require.ErrorIs(t, err, store.err)
+	require.Empty(t, ch)
+}
+
+func TestJoinReplaysHistoryBeforeLiveEvents(t *testing.T) {
+	store := &recordingStore{}
+	svc := NewService(WithMessageStore(store), WithMaxHistoryReplay(2))
+
+	_, _, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	for _, content := range []string{"one", "two", "three"} {
+		broadcast(t, svc, domain.Message{
+			UserID:  "alice",
+			RoomID:  "room-1",
+			Content: content,
+		})
+	}
+
+	_, chBob, err := svc.Join(context.Background(), domain.JoinRequest{
+		UserID:       "bob",
+		RoomID:       "room-1",
+		HistoryLimit: 10,
+	})
+	require.NoError(t, err)
+
+	broadcast(t, svc, domain.Message{
+		UserID:  "alice",
+		RoomID:  "room-1",
+		Content: "live",
+	})
+
+	first := expectEvent(t, chBob, domain.EventMessage)
+	require.True(t, first.Replayed)
+	require.Equal(t, "two", first.Content)
+	second := expectEvent(t, chBob, domain.EventMessage)
+	require.True(t, second.Replayed)
+	require.Equal(t, "three", second.Content)
+	live := expectEvent(t, chBob, domain.EventMessage)
+	require.False(t, live.Replayed)
+	require.Equal(t, "live", live.Content)
+}
+
+func TestEventsCarryIDsAndIncreasingSequences(t *testing.T) {
+	svc := NewService()
+
+	_, chAlice, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	bob, _, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+	require.NoError(t, svc.Leave(context.Background(), "room-1", bob.ConnectionID))
+
+	joined := expectEvent(t, chAlice, domain.EventUserJoined)
+	msg := expectEvent(t, chAlice, domain.EventMessage)
+	left := expectEvent(t, chAlice, domain.EventUserLeft)
+
+	// Sequence 1 went to alice's own join, which she is not notified about.
+	require.Equal(t, []uint64{2, 3, 4}, []uint64{joined.Sequence, msg.Sequence, left.Sequence})
+	require.NotEmpty(t, msg.ID)
+	require.NotEqual(t, joined.ID, msg.ID)
+	require.NotEqual(t, msg.ID, left.ID)
+}
+
+func TestSequenceSurvivesRoomRecreation(t *testing.T) {
+	svc := NewService()
+
+	alice, _, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	require.NoError(t, svc.Leave(context.Background(), "room-1", alice.ConnectionID))
+
+	_, chBob, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+
+	msg := expectEvent(t, chBob, domain.EventMessage)
+	require.Equal(t, uint64(4), msg.Sequence)
+}
+
+func TestRetiredSequencesAreBounded(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService()
+
+	retire := func(roomID string) {
+		session, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: roomID})
+		require.NoError(t, err)
+		require.NoError(t, svc.Leave(ctx, roomID, session.ConnectionID))
+	}
+	retire("room-1")
+	retire("room-1")
+	require.Equal(t, uint64(4), svc.retired["room-1"].seq)
+
+	for i := range maxRetiredRooms - 1 {
+		retire(fmt.Sprintf("other-%d", i))
+	}
+	require.Contains(t, svc.retired, "room-1", "the stale entry of the first removal is dropped alone")
+	retire("last")
+	require.NotContains(t, svc.retired, "room-1")
+	require.Len(t, svc.retired, maxRetiredRooms)
+	require.Len(t, svc.retiredOrder, maxRetiredRooms)
+}
+
 func TestLeaveRemovesUserAndNotifiesOthers(t *testing.T) {
 	clk := fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
 	svc := NewService(WithClock(clk))
 
-	_, chAlice, err := svc.Join(context.Background(), domain.JoinRequest{
+	alice, chAlice, err := svc.Join(context.Background(), domain.JoinRequest{
 		UserID: "alice",
 		RoomID: "room-1",
 	})
@@ -109,7 +296,7 @@ func TestLeaveRemovesUserAndNotifiesOthers(t *testing.T) {
 
 	expectEvent(t, chAlice, domain.EventUserJoined)
 
-	err = svc.Leave(context.Background(), "room-1", "alice")
+	err = svc.Leave(context.Background(), "room-1", alice.ConnectionID)
 	require.NoError(t, err)
 
 	evBob := expectEvent(t, chBob, domain.EventUserLeft)
@@ -119,6 +306,211 @@ func TestLeaveRemovesUserAndNotifiesOthers(t *testing.T) {
 	require.False(t, ok, "alice channel should be closed")
 }
 
+func TestConcurrentRoomsKeepOrderedSequences(t *testing.T) {
+	const rooms, messages = 8, 50
+	svc := NewService(WithBufferSize(messages + 1))
+
+	readers := make([]<-chan domain.Event, rooms)
+	var wg sync.WaitGroup
+	for r := range rooms {
+		roomID := fmt.Sprintf("room-%d", r)
+		_, ch, err := svc.Join(context.Background(), domain.JoinRequest{UserID: "reader", RoomID: roomID})
+		require.NoError(t, err)
+		_, _, err = svc.Join(context.Background(), domain.JoinRequest{UserID: "writer", RoomID: roomID})
+		require.NoError(t, err)
+		readers[r] = ch
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range messages {
+				_, _ = svc.Broadcast(context.Background(), domain.Message{UserID: "writer", RoomID: roomID, Content: fmt.Sprint(i)})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, ch := range readers {
+		expectEvent(t, ch, domain.EventUserJoined)
+		last := uint64(0)
+		for range messages {
+			ev := expectEvent(t, ch, domain.EventMessage)
+			require.Greater(t, ev.Sequence, last)
+			last = ev.Sequence
+		}
+	}
+}
+
+// BenchmarkBroadcast measures broadcast throughput as the load spreads over more rooms.
+// Each room has a handful of subscribers drained by their own goroutines; with per-room
+// locking the throughput grows with the number of rooms instead of flattening out.
+func BenchmarkBroadcast(b *testing.B) {
+	for _, rooms := range []int{1, 4, 16, 64, 256} {
+		b.Run(fmt.Sprintf("rooms=%d", rooms), func(b *testing.B) {
+			benchmarkBroadcast(b, rooms, 4)
+		})
+	}
+}
+
+func benchmarkBroadcast(b *testing.B, rooms, subscribers int) {
+	svc := NewService(WithBufferSize(256))
+	ids := make([]string, rooms)
+	var sessions []domain.Session
+
+	var drained sync.WaitGroup
+	for r := range ids {
+		ids[r] = fmt.Sprintf("room-%d", r)
+		for u := range subscribers {
+			session, ch, err := svc.Join(context.Background(), domain.JoinRequest{
+				UserID: fmt.Sprintf("user-%d", u),
+				RoomID: ids[r],
+			})
+			require.NoError(b, err)
+			sessions = append(sessions, session)
+			drained.Add(1)
+			go func() {
+				defer drained.Done()
+				for range ch {
+				}
+			}()
+		}
+	}
+
+	var next atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		roomID := ids[int(next.Add(1))%rooms]
+		for pb.Next() {
+			_, _ = svc.Broadcast(context.Background(), domain.Message{UserID: "user-0", RoomID: roomID, Content: "payload"})
+		}
+	})
+	b.StopTimer()
+
+	for _, session := range sessions {
+		_ = svc.Leave(context.Background(), session.RoomID, session.ConnectionID)
+	}
+	drained.Wait()
+}
+
+// BenchmarkJoinLeave measures session churn spread over a varying number of rooms.
+func BenchmarkJoinLeave(b *testing.B) {
+	for _, rooms := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("rooms=%d", rooms), func(b *testing.B) {
+			svc := NewService()
+			var worker atomic.Uint64
+			b.RunParallel(func(pb *testing.PB) {
+				id := worker.Add(1)
+				roomID := fmt.Sprintf("room-%d", int(id)%rooms)
+				userID := fmt.Sprintf("user-%d", id)
+				for pb.Next() {
+					session, ch, err := svc.Join(context.Background(), domain.JoinRequest{UserID: userID, RoomID: roomID})
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					_ = svc.Leave(context.Background(), roomID, session.ConnectionID)
+					for range ch {
+					}
+				}
+			})
+		})
+	}
+}
+
+type sequentialIDs struct {
+	next int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.next++
+	return fmt.Sprintf("id-%d", g.next)
+}
+
+type recordingStore struct {
+	appended   []domain.Message
+	err        error
+	replaceErr error
+}
+
+func (r *recordingStore) Append(_ context.Context, msg domain.Message) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.appended = append(r.appended, msg)
+	return nil
+}
+
+func (r *recordingStore) LastSequence(context.Context, string) (uint64, error) {
+	if len(r.appended) == 0 {
+		return 0, nil
+	}
+	return r.appended[len(r.appended)-1].Sequence, nil
+}
+
+func (r *recordingStore) RangeByTime(context.Context, string, time.Time, time.Time, int) ([]domain.Message, error) {
+	return nil, nil
+}
+
+func (r *recordingStore) RangeBySequence(_ context.Context, _ string, from, to uint64, _ int) ([]domain.Message, error) {
+	var out []domain.Message
+	for _, msg := range r.appended {
+		if msg.Sequence >= from && (to == 0 || msg.Sequence < to) {
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+func (r *recordingStore) Replies(_ context.Context, _, rootID string, from, to uint64, limit int) ([]domain.Message, error) {
+	var out []domain.Message
+	for _, msg := range r.appended {
+		if msg.ReplyTo == rootID && msg.Sequence >= from && (to == 0 || msg.Sequence < to) {
+			out = append(out, msg)
+			if limit > 0 && len(out) == limit {
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *recordingStore) Latest(_ context.Context, _ string, limit int) ([]domain.Message, error) {
+	if limit > 0 && len(r.appended) > limit {
+		return r.appended[len(r.appended)-limit:], nil
+	}
+	return r.appended, nil
+}
+
+func (r *recordingStore) Get(_ context.Context, roomID, messageID string) (domain.Message, bool, error) {
+	for _, msg := range r.appended {
+		if msg.RoomID == roomID && msg.ID == messageID {
+			return msg, true, nil
+		}
+	}
+	return domain.Message{}, false, nil
+}
+
+func (r *recordingStore) Replace(_ context.Context, msg domain.Message) error {
+	if r.replaceErr != nil {
+		return r.replaceErr
+	}
+	for i := range r.appended {
+		if r.appended[i].RoomID == msg.RoomID && r.appended[i].ID == msg.ID {
+			r.appended[i] = msg
+			return nil
+		}
+	}
+	return errors.New("message not found")
+}
+
+// broadcast sends a message and fails the test on error.
+func broadcast(t *testing.T, svc *Service, msg domain.Message) domain.Message {
+	t.Helper()
+	stored, err := svc.Broadcast(context.Background(), msg)
+	require.NoError(t, err)
+	return stored
+}
+
 func expectEvent(t *testing.T, ch <-chan domain.Event, eventType domain.EventType) domain.Event {
 	t.Helper()
 	select {
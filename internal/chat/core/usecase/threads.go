@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const (
+	defaultThreadPageSize = 50
+	maxThreadPageSize     = 500
+)
+
+// GetThread returns the root of a thread and a page of its replies, oldest first. Naming a
+// reply returns the thread it belongs to. Threads are read from the message store, so they
+// remain available after their room empties, and deleted messages show up as tombstones.
+// Users banned from the room, and callers who are not inside a restricted room, are refused.
+func (s *Service) GetThread(ctx context.Context, req domain.ThreadRequest) (domain.ThreadPage, error) {
+	if req.RoomID == "" || req.RootID == "" {
+		return domain.ThreadPage{}, ErrEmptyFields
+	}
+	if err := s.checkReader(req.RoomID, req.UserID); err != nil {
+		return domain.ThreadPage{}, err
+	}
+	from, err := decodeSequenceToken(req.PageToken)
+	if err != nil {
+		return domain.ThreadPage{}, err
+	}
+
+	size := req.PageSize
+	if size <= 0 {
+		size = defaultThreadPageSize
+	}
+	size = min(size, maxThreadPageSize)
+
+	root, err := s.threadRoot(ctx, req.RoomID, req.RootID)
+	if err != nil {
+		return domain.ThreadPage{}, err
+	}
+
+	// One extra reply tells whether another page follows.
+	replies, err := s.store.Replies(ctx, req.RoomID, root.ID, from, 0, size+1)
+	if err != nil {
+		return domain.ThreadPage{}, fmt.Errorf(errFmtLoadThread, err)
+	}
+
+	page := domain.ThreadPage{Root: root, Replies: replies}
+	if len(replies) > size {
+		page.Replies = replies[:size]
+		page.NextPageToken = encodeSequenceToken(replies[size].Sequence)
+	}
+	return page, nil
+}
+
+// checkReader refuses to show the history of a room to users banned from it and, when the
+// room is restricted, to users who are not inside it.
+func (s *Service) checkReader(roomID, userID string) error {
+	if userID != "" && s.restricted(s.bans, roomID, userID) {
+		return ErrUserBanned
+	}
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return nil
+	}
+	defer rm.mu.Unlock()
+
+	if rm.info.Access.Restricted() && !insiderLocked(rm, userID) {
+		return ErrRoomAccessDenied
+	}
+	return nil
+}
+
+// threadRoot loads the root of the thread messageID belongs to: the message itself, or its
+// root when it is a reply.
+func (s *Service) threadRoot(ctx context.Context, roomID, messageID string) (domain.Message, error) {
+	if s.store == nil {
+		return domain.Message{}, ErrMessageNotFound
+	}
+
+	msg, found, err := s.store.Get(ctx, roomID, messageID)
+	if err != nil {
+		return domain.Message{}, fmt.Errorf(errFmtLoadMessage, err)
+	}
+	if found && msg.ReplyTo != "" {
+		msg, found, err = s.store.Get(ctx, roomID, msg.ReplyTo)
+		if err != nil {
+			return domain.Message{}, fmt.Errorf(errFmtLoadMessage, err)
+		}
+	}
+	if !found {
+		return domain.Message{}, ErrMessageNotFound
+	}
+	return msg, nil
+}
+
+// countReplyLocked bumps the reply count of a thread root after reply was posted, and
+// announces the new count to the room.
+func (s *Service) countReplyLocked(ctx context.Context, rm *room, root, reply domain.Message) error {
+	root.ReplyCount++
+	if err := s.store.Replace(ctx, root); err != nil {
+		return fmt.Errorf(errFmtReplaceMsg, err)
+	}
+
+	s.enqueueLocked(rm, domain.Event{
+		Type:        domain.EventThreadUpdated,
+		UserID:      reply.UserID,
+		DisplayName: reply.DisplayName,
+		RoomID:      rm.id,
+		MessageID:   root.ID,
+		Count:       root.ReplyCount,
+		Timestamp:   reply.SentAt,
+	}, "")
+	return nil
+}
+
+// Thread page tokens are opaque to clients: the page token encoding of the sequence the
+// next page starts at.
+func encodeSequenceToken(seq uint64) string {
+	return encodePageToken(strconv.FormatUint(seq, 10))
+}
+
+func decodeSequenceToken(token string) (uint64, error) {
+	raw, err := decodePageToken(token)
+	if err != nil || raw == "" {
+		return 0, err
+	}
+	seq, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	return seq, nil
+}
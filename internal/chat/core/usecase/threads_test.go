@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/lechitz/chat-grpc/internal/platform/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepliesUpdateTheThreadRoot(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMessageStore(&recordingStore{}))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	root := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "deploy at 5?"})
+	expectEvent(t, chAlice, domain.EventMessage)
+
+	reply := broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "sure", ReplyTo: root.ID})
+	require.Equal(t, root.ID, reply.ReplyTo)
+	ev := expectEvent(t, chAlice, domain.EventMessage)
+	require.Equal(t, root.ID, ev.ReplyTo)
+	ev = expectEvent(t, chAlice, domain.EventThreadUpdated)
+	require.Equal(t, root.ID, ev.MessageID)
+	require.Equal(t, "bob", ev.UserID)
+	require.Equal(t, 1, ev.Count)
+	require.Equal(t, reply.Sequence+1, ev.Sequence)
+
+	// Replying to a reply joins the thread of its root.
+	nested := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "thanks", ReplyTo: reply.ID})
+	require.Equal(t, root.ID, nested.ReplyTo)
+	expectEvent(t, chAlice, domain.EventMessage)
+	ev = expectEvent(t, chAlice, domain.EventThreadUpdated)
+	require.Equal(t, 2, ev.Count)
+
+	_, err = svc.Broadcast(ctx, domain.Message{UserID: "alice", RoomID: "room-1", Content: "?", ReplyTo: "unknown"})
+	require.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func TestReplyCountFailureKeepsTheReply(t *testing.T) {
+	ctx := context.Background()
+	store := &recordingStore{}
+	log := &warningLog{}
+	svc := NewService(WithMessageStore(store), WithLogger(log))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	root := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "deploy at 5?"})
+	expectEvent(t, chAlice, domain.EventMessage)
+
+	store.replaceErr = errors.New("disk full")
+	reply, err := svc.Broadcast(ctx, domain.Message{UserID: "alice", RoomID: "room-1", Content: "or 6", ReplyTo: root.ID, RequestID: "req-1"})
+	require.NoError(t, err)
+	require.Equal(t, "or 6", expectEvent(t, chAlice, domain.EventMessage).Content)
+	require.Empty(t, chAlice)
+	require.Equal(t, []string{logMsgCountReplyFailure}, log.warnings)
+
+	// A retry is acknowledged with the reply everyone already received.
+	retried, err := svc.Broadcast(ctx, domain.Message{UserID: "alice", RoomID: "room-1", Content: "or 6", ReplyTo: root.ID, RequestID: "req-1"})
+	require.NoError(t, err)
+	require.Equal(t, reply.ID, retried.ID)
+}
+
+// warningLog records the messages logged as warnings.
+type warningLog struct {
+	logger.NoopLogger
+	warnings []string
+}
+
+func (l *warningLog) WarnwCtx(_ context.Context, msg string, _ ...any) {
+	l.warnings = append(l.warnings, msg)
+}
+
+func TestGetThreadPagesReplies(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMessageStore(&recordingStore{}))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	root := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "root"})
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "unrelated"})
+	var replies []domain.Message
+	for _, content := range []string{"one", "two", "three"} {
+		replies = append(replies, broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: content, ReplyTo: root.ID}))
+	}
+
+	first, err := svc.GetThread(ctx, domain.ThreadRequest{RoomID: "room-1", RootID: root.ID, PageSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, root.ID, first.Root.ID)
+	require.Equal(t, 3, first.Root.ReplyCount)
+	require.Equal(t, replies[:2], first.Replies)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second, err := svc.GetThread(ctx, domain.ThreadRequest{RoomID: "room-1", RootID: replies[0].ID, PageSize: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	require.Equal(t, root.ID, second.Root.ID)
+	require.Equal(t, replies[2:], second.Replies)
+	require.Empty(t, second.NextPageToken)
+
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "room-1", RootID: "unknown"})
+	require.ErrorIs(t, err, ErrMessageNotFound)
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "room-1", RootID: root.ID, PageToken: "%%%"})
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "room-1"})
+	require.ErrorIs(t, err, ErrEmptyFields)
+}
+
+func TestGetThreadChecksTheReader(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithMessageStore(&recordingStore{}))
+
+	_, err := svc.CreateRoom(ctx, domain.CreateRoomRequest{RoomID: "secret", UserID: "alice", Access: domain.AccessInviteOnly})
+	require.NoError(t, err)
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "secret"})
+	require.NoError(t, err)
+	root := broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "secret", Content: "plans"})
+
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "secret", RootID: root.ID, UserID: "mallory"})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "secret", RootID: root.ID})
+	require.ErrorIs(t, err, ErrRoomAccessDenied)
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "secret", RootID: root.ID, UserID: "alice"})
+	require.NoError(t, err)
+
+	// Banned users cannot read the history of public rooms either.
+	_, _, err = svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "lobby"})
+	require.NoError(t, err)
+	root = broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "lobby", Content: "hello"})
+	require.NoError(t, svc.Ban(ctx, "lobby", "alice", "bob", "", time.Hour))
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "lobby", RootID: root.ID, UserID: "bob"})
+	require.ErrorIs(t, err, ErrUserBanned)
+	_, err = svc.GetThread(ctx, domain.ThreadRequest{RoomID: "lobby", RootID: root.ID, UserID: "carol"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+)
+
+const (
+	defaultTypingTimeout = 5 * time.Second
+	// typingCooldown is the shortest interval between two announced starts of a user.
+	typingCooldown = time.Second
+)
+
+// typingState tracks a user typing in a room; its timer announces the stop when the user
+// stays silent for the typing timeout. Each refresh replaces the state, so a timer that
+// fired while a refresh waited for the room lock recognises it is stale.
+type typingState struct {
+	timer *time.Timer
+	// announced reports whether the room was told about the start, and so must hear the stop.
+	announced bool
+}
+
+// Typing reports that a user started or stopped typing in a room.
+//
+// Typing signals are ephemeral: they are neither stored nor sequenced, are not sent back to
+// the typing user's own connections and are dropped for subscribers without buffer space.
+// Repeated starts while the user is already typing are debounced: they only postpone the
+// automatic stop, which is announced once the typing timeout elapses without a new start.
+// Sending a message or leaving the room also ends the typing state. A user toggling the state
+// is rate-limited: a start less than the typing cooldown after the previous announced one is
+// recorded silently, and so is its stop.
+func (s *Service) Typing(_ context.Context, roomID, userID string, typing bool) error {
+	if roomID == "" || userID == "" {
+		return ErrEmptyFields
+	}
+
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return ErrRoomNotFound
+	}
+	defer rm.mu.Unlock()
+
+	m, ok := rm.members[userID]
+	if !ok {
+		return ErrUserNotInRoom
+	}
+
+	if !typing {
+		s.stopTypingLocked(rm, userID, true)
+		return nil
+	}
+
+	state := &typingState{}
+	if previous, active := rm.typing[userID]; active {
+		previous.timer.Stop()
+		state.announced = previous.announced
+	} else if now := s.clock.Now(); now.Sub(rm.typingAnnounced[userID]) >= typingCooldown {
+		state.announced = true
+		rm.typingAnnounced[userID] = now
+		s.signalLocked(rm, s.typingEvent(m.profile, true))
+	}
+	state.timer = time.AfterFunc(s.typingTimeout, func() {
+		s.expireTyping(roomID, userID, state)
+	})
+	rm.typing[userID] = state
+	return nil
+}
+
+// expireTyping announces that a user stopped typing once the typing timeout elapsed.
+func (s *Service) expireTyping(roomID, userID string, state *typingState) {
+	rm, ok := s.lockRoom(roomID, false)
+	if !ok {
+		return
+	}
+	defer rm.mu.Unlock()
+
+	if rm.typing[userID] != state {
+		return
+	}
+	s.stopTypingLocked(rm, userID, true)
+}
+
+// stopTypingLocked clears the user's typing state, announcing the stop when requested.
+func (s *Service) stopTypingLocked(rm *room, userID string, announce bool) {
+	state, ok := rm.typing[userID]
+	if !ok {
+		return
+	}
+	state.timer.Stop()
+	delete(rm.typing, userID)
+
+	if m, present := rm.members[userID]; announce && present && state.announced {
+		s.signalLocked(rm, s.typingEvent(m.profile, false))
+	}
+}
+
+// signalLocked delivers an ephemeral event to every attached connection of the room except
+// those of the user it describes. It never blocks and never counts as a missed event, and it
+// skips subscribers whose buffer would no longer fit the next room event behind a pending
+// EventMissed notice.
+func (s *Service) signalLocked(rm *room, event domain.Event) {
+	for connID, sub := range rm.subscribers {
+		if rm.sessions[connID].UserID == event.UserID {
+			continue
+		}
+		if _, detached := rm.detached[connID]; detached {
+			continue
+		}
+		if spareRoom(sub) {
+			sub.ch <- event
+		}
+	}
+}
+
+func (s *Service) typingEvent(profile domain.Session, typing bool) domain.Event {
+	return domain.Event{
+		Type:        domain.EventTyping,
+		UserID:      profile.UserID,
+		DisplayName: profile.DisplayName,
+		RoomID:      profile.RoomID,
+		Typing:      typing,
+		Timestamp:   s.clock.Now(),
+	}
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/chat/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypingIsDebouncedAndExpires(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithTypingTimeout(30 * time.Millisecond))
+
+	_, chAlice, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1", DisplayName: "Alice"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+
+	for range 3 {
+		require.NoError(t, svc.Typing(ctx, "room-1", "alice", true))
+	}
+
+	started := expectEvent(t, chBob, domain.EventTyping)
+	require.True(t, started.Typing)
+	require.Equal(t, "Alice", started.DisplayName)
+	require.Zero(t, started.Sequence)
+	require.Empty(t, started.ID)
+
+	stopped := expectEvent(t, chBob, domain.EventTyping)
+	require.False(t, stopped.Typing)
+	require.Equal(t, "alice", stopped.UserID)
+	require.Empty(t, chBob)
+	require.Empty(t, chAlice, "typing signals are not echoed to the typing user")
+
+	// Ephemeral signals do not consume room sequences.
+	broadcast(t, svc, domain.Message{UserID: "bob", RoomID: "room-1", Content: "hi"})
+	msg := expectEvent(t, chBob, domain.EventMessage)
+	require.Equal(t, uint64(3), msg.Sequence)
+}
+
+func TestTypingLeavesRoomForRoomEvents(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(WithBufferSize(2))
+	chAlice, _ := joinPair(t, svc)
+
+	// With the join notice buffered, bob's typing signal would take the slot msg-1 needs.
+	require.NoError(t, svc.Typing(ctx, "room-1", "bob", true))
+	broadcastN(t, svc, "alice", 1)
+	expectEvent(t, chAlice, domain.EventUserJoined)
+	require.Equal(t, "msg-1", expectEvent(t, chAlice, domain.EventMessage).Content)
+
+	// A pending missed notice needs a slot of its own next to the next event.
+	broadcastN(t, svc, "alice", 3)
+	expectEvent(t, chAlice, domain.EventMessage)
+	expectEvent(t, chAlice, domain.EventMessage)
+	require.NoError(t, svc.Typing(ctx, "room-1", "bob", false))
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "msg-4"})
+	require.Equal(t, uint64(1), expectEvent(t, chAlice, domain.EventMissed).Missed)
+	require.Equal(t, "msg-4", expectEvent(t, chAlice, domain.EventMessage).Content)
+	require.Empty(t, chAlice)
+}
+
+func TestTypingStopsOnMessageOrRequest(t *testing.T) {
+	ctx := context.Background()
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Typing(ctx, "room-1", "alice", true))
+	broadcast(t, svc, domain.Message{UserID: "alice", RoomID: "room-1", Content: "done"})
+	require.True(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	require.False(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	expectEvent(t, chBob, domain.EventMessage)
+
+	clk.t = clk.t.Add(typingCooldown)
+	require.NoError(t, svc.Typing(ctx, "room-1", "alice", true))
+	require.NoError(t, svc.Typing(ctx, "room-1", "alice", false))
+	require.NoError(t, svc.Typing(ctx, "room-1", "alice", false))
+	require.True(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	require.False(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	require.Empty(t, chBob)
+
+	require.ErrorIs(t, svc.Typing(ctx, "room-1", "carol", true), ErrUserNotInRoom)
+	require.ErrorIs(t, svc.Typing(ctx, "room-2", "alice", true), ErrRoomNotFound)
+}
+
+func TestTypingToggleIsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	clk := &fakeClock{t: time.Date(2024, 7, 10, 15, 0, 0, 0, time.UTC)}
+	svc := NewService(WithClock(clk))
+
+	_, _, err := svc.Join(ctx, domain.JoinRequest{UserID: "alice", RoomID: "room-1"})
+	require.NoError(t, err)
+	_, chBob, err := svc.Join(ctx, domain.JoinRequest{UserID: "bob", RoomID: "room-1"})
+	require.NoError(t, err)
+
+	for range 5 {
+		require.NoError(t, svc.Typing(ctx, "room-1", "alice", true))
+		require.NoError(t, svc.Typing(ctx, "room-1", "alice", false))
+	}
+	require.True(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	require.False(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+	require.Empty(t, chBob, "toggling within the cooldown is not announced")
+
+	clk.t = clk.t.Add(typingCooldown)
+	require.NoError(t, svc.Typing(ctx, "room-1", "alice", true))
+	require.True(t, expectEvent(t, chBob, domain.EventTyping).Typing)
+}
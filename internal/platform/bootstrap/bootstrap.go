@@ -3,31 +3,151 @@ package bootstrap
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/lechitz/chat-grpc/internal/adapter/secondary/auth/apikey"
+	"github.com/lechitz/chat-grpc/internal/adapter/secondary/auth/jwt"
+	"github.com/lechitz/chat-grpc/internal/chat/adapter/secondary/filestore"
+	"github.com/lechitz/chat-grpc/internal/chat/adapter/secondary/memstore"
 	"github.com/lechitz/chat-grpc/internal/chat/core/ports/input"
+	"github.com/lechitz/chat-grpc/internal/chat/core/ports/output"
 	"github.com/lechitz/chat-grpc/internal/chat/core/usecase"
 	"github.com/lechitz/chat-grpc/internal/platform/config"
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
 	"github.com/lechitz/chat-grpc/internal/platform/ports/logger"
 )
 
+const (
+	logMsgStoreReady        = "message store ready"
+	logMsgStoreCloseFailure = "failed to close message store"
+	logMsgStoreTruncated    = "cut a partially written record from the message store"
+	logMsgAuthReady         = "authentication ready"
+	logFieldMode            = "mode"
+	logFieldBackend         = "backend"
+	logFieldPath            = "path"
+	logFieldBytes           = "bytes"
+	logFieldError           = "error"
+
+	errFmtOpenFileStore = "open file store: %w"
+	errFmtLoadAPIKeys   = "load api keys: %w"
+	errFmtBuildJWTAuth  = "build jwt authenticator: %w"
+)
+
 // AppDependencies collects the primary ports exposed to adapters.
 type AppDependencies struct {
-	ChatService input.StreamService
+	ChatService input.ChatService
 	Logger      logger.ContextLogger
+	// Authenticator verifies client credentials; nil when authentication is disabled.
+	Authenticator auth.Authenticator
 }
 
 // Initialize builds the dependencies required by transports.
 func Initialize(ctx context.Context, cfg *config.Config, log logger.ContextLogger) (*AppDependencies, func(context.Context), error) {
-	_ = cfg
 	_ = ctx
 
-	chatService := usecase.NewService()
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Infow(logMsgAuthReady, logFieldMode, cfg.Auth.Mode)
+
+	store, closeStore, err := newMessageStore(cfg.Store, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Infow(logMsgStoreReady, logFieldBackend, cfg.Store.Backend, logFieldPath, cfg.Store.Path)
 
-	// Placeholder for future resource cleanup.
-	cleanup := func(context.Context) {}
+	opts := []usecase.Option{
+		usecase.WithMessageStore(store),
+		usecase.WithLogger(log),
+		usecase.WithMaxHistoryReplay(cfg.ServerGRPC.MaxHistoryReplay),
+		usecase.WithResumeGrace(cfg.ServerGRPC.ResumeGrace),
+		usecase.WithSlowConsumerPolicy(slowConsumerPolicy(cfg.ServerGRPC.SlowConsumerPolicy)),
+		usecase.WithBlockTimeout(cfg.ServerGRPC.SlowConsumerTimeout),
+		usecase.WithTypingTimeout(cfg.ServerGRPC.TypingTimeout),
+		usecase.WithIdleTimeout(cfg.ServerGRPC.IdleTimeout),
+		usecase.WithDedupWindow(cfg.ServerGRPC.DedupWindow),
+		usecase.WithModerators(cfg.ServerGRPC.Moderators...),
+		usecase.WithAdHocRooms(!cfg.ServerGRPC.RequireRoomCreation),
+		usecase.WithRoomParticipantLimit(cfg.ServerGRPC.RoomParticipantLimit),
+		usecase.WithJoinQueue(cfg.ServerGRPC.JoinQueueSize),
+	}
+	if cfg.Store.DirectQueue {
+		opts = append(opts,
+			usecase.WithDirectMessageStore(store),
+			usecase.WithDirectQueueLimits(cfg.Store.DirectQueueLimit, cfg.Store.DirectQueueTTL),
+		)
+	}
+	chatService := usecase.NewService(opts...)
+
+	cleanup := func(context.Context) {
+		if err := closeStore(); err != nil {
+			log.Warnw(logMsgStoreCloseFailure, logFieldError, err)
+		}
+	}
 
 	return &AppDependencies{
-		ChatService: chatService,
-		Logger:      log,
+		ChatService:   chatService,
+		Logger:        log,
+		Authenticator: authenticator,
 	}, cleanup, nil
 }
+
+// messageStore is implemented by every store backend: it keeps room history and, when the
+// direct queue is enabled, the direct messages of offline users.
+type messageStore interface {
+	output.MessageStore
+	output.DirectMessageStore
+}
+
+// newMessageStore builds the configured store along with the function that releases it.
+func newMessageStore(cfg config.StoreConfig, log logger.ContextLogger) (messageStore, func() error, error) {
+	switch cfg.Backend {
+	case config.StoreBackendFile:
+		store, err := filestore.Open(cfg.Path, memstore.WithRoomRetention(cfg.RoomRetention))
+		if err != nil {
+			return nil, nil, fmt.Errorf(errFmtOpenFileStore, err)
+		}
+		if cut := store.Truncated(); cut > 0 {
+			log.Warnw(logMsgStoreTruncated, logFieldPath, cfg.Path, logFieldBytes, cut)
+		}
+		return store, store.Close, nil
+	default:
+		return memstore.New(memstore.WithRoomRetention(cfg.RoomRetention)), func() error { return nil }, nil
+	}
+}
+
+// newAuthenticator builds the authenticator of the configured mode, or nil when
+// authentication is disabled.
+func newAuthenticator(cfg config.AuthConfig) (auth.Authenticator, error) {
+	switch cfg.Mode {
+	case config.AuthModeAPIKey:
+		authenticator, err := apikey.Load(cfg.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf(errFmtLoadAPIKeys, err)
+		}
+		return authenticator, nil
+	case config.AuthModeJWT:
+		authenticator, err := jwt.New([]byte(cfg.JWTSecret), jwt.WithIssuer(cfg.JWTIssuer), jwt.WithAudience(cfg.JWTAudience))
+		if err != nil {
+			return nil, fmt.Errorf(errFmtBuildJWTAuth, err)
+		}
+		return authenticator, nil
+	default:
+		return nil, nil
+	}
+}
+
+// slowConsumerPolicy maps the configured policy name onto the use case policy.
+func slowConsumerPolicy(name string) usecase.SlowConsumerPolicy {
+	switch name {
+	case config.SlowConsumerDropOldest:
+		return usecase.DropOldest
+	case config.SlowConsumerBlock:
+		return usecase.Block
+	case config.SlowConsumerDisconnect:
+		return usecase.Disconnect
+	default:
+		return usecase.DropNewest
+	}
+}
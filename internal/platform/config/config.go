@@ -19,6 +19,8 @@ type Config struct {
 	App           AppConfig
 	ServerGRPC    ServerConfig
 	Observability ObservabilityConfig
+	Store         StoreConfig
+	Auth          AuthConfig
 }
 
 // AppConfig holds metadata about the running application.
@@ -56,11 +58,34 @@ func Load() (*Config, error) {
 			Environment: getEnv(envEnvironmentKey, defaultEnvironment),
 		},
 		ServerGRPC: ServerConfig{
-			Host:           getEnv(envHostKey, defaultHost),
-			Port:           getEnv(envPortKey, defaultPort),
-			ShutdownGrace:  getEnvDuration(envShutdownGraceKey, defaultShutdownGrace),
-			MaxRecvMsgSize: getEnvInt(envMaxRecvSizeKey, defaultMaxRecvMsgSize),
-			MaxSendMsgSize: getEnvInt(envMaxSendSizeKey, defaultMaxSendMsgSize),
+			Host:             getEnv(envHostKey, defaultHost),
+			Port:             getEnv(envPortKey, defaultPort),
+			ShutdownGrace:    getEnvDuration(envShutdownGraceKey, defaultShutdownGrace),
+			MaxRecvMsgSize:   getEnvInt(envMaxRecvSizeKey, defaultMaxRecvMsgSize),
+			MaxSendMsgSize:   getEnvInt(envMaxSendSizeKey, defaultMaxSendMsgSize),
+			MaxHistoryReplay: getEnvInt(envMaxHistoryReplayKey, defaultMaxHistoryReplay),
+			ResumeGrace:      getEnvDuration(envResumeGraceKey, defaultResumeGrace),
+
+			SlowConsumerPolicy:   getEnv(envSlowConsumerPolicyKey, defaultSlowConsumerPolicy),
+			SlowConsumerTimeout:  getEnvDuration(envSlowConsumerTimeoutKey, defaultSlowConsumerTimeout),
+			TypingTimeout:        getEnvDuration(envTypingTimeoutKey, defaultTypingTimeout),
+			IdleTimeout:          getEnvDuration(envIdleTimeoutKey, defaultIdleTimeout),
+			DedupWindow:          getEnvDuration(envDedupWindowKey, defaultDedupWindow),
+			Moderators:           getEnvList(envModeratorsKey),
+			RequireRoomCreation:  getEnvBool(envRequireRoomCreationKey, false),
+			RoomParticipantLimit: getEnvInt(envRoomParticipantLimitKey, 0),
+			JoinQueueSize:        getEnvInt(envJoinQueueSizeKey, 0),
+
+			KeepaliveTime:                getEnvDuration(envKeepaliveTimeKey, defaultKeepaliveTime),
+			KeepaliveTimeout:             getEnvDuration(envKeepaliveTimeoutKey, defaultKeepaliveTimeout),
+			KeepaliveMaxIdle:             getEnvDuration(envKeepaliveMaxIdleKey, 0),
+			KeepaliveMinTime:             getEnvDuration(envKeepaliveMinTimeKey, defaultKeepaliveMinTime),
+			KeepalivePermitWithoutStream: getEnvBool(envKeepalivePermitKey, false),
+
+			TLSCertFile:       getEnv(envTLSCertFileKey, ""),
+			TLSKeyFile:        getEnv(envTLSKeyFileKey, ""),
+			TLSClientCAFile:   getEnv(envTLSClientCAFileKey, ""),
+			TLSReloadInterval: getEnvDuration(envTLSReloadIntervalKey, defaultTLSReloadInterval),
 		},
 		Observability: ObservabilityConfig{
 			Enabled:                  getEnvBool(envOtelEnabledKey, defaultOtelEnabled),
@@ -72,6 +97,22 @@ func Load() (*Config, error) {
 			ServiceName:              getEnv(envOtelServiceNameKey, ""),
 			ServiceVersion:           getEnv(envOtelServiceVersionKey, defaultOtelServiceVersion),
 		},
+		Store: StoreConfig{
+			Backend: getEnv(envStoreBackendKey, defaultStoreBackend),
+			Path:    getEnv(envStorePathKey, defaultStorePath),
+
+			RoomRetention:    getEnvInt(envStoreRoomRetentionKey, defaultStoreRoomRetention),
+			DirectQueue:      getEnvBool(envDirectQueueKey, false),
+			DirectQueueLimit: getEnvInt(envDirectQueueLimitKey, defaultDirectQueueLimit),
+			DirectQueueTTL:   getEnvDuration(envDirectQueueTTLKey, defaultDirectQueueTTL),
+		},
+		Auth: AuthConfig{
+			Mode:        getEnv(envAuthModeKey, defaultAuthMode),
+			APIKeysFile: getEnv(envAuthAPIKeysFileKey, ""),
+			JWTSecret:   getEnv(envAuthJWTSecretKey, ""),
+			JWTIssuer:   getEnv(envAuthJWTIssuerKey, ""),
+			JWTAudience: getEnv(envAuthJWTAudienceKey, ""),
+		},
 	}
 
 	if cfg.Observability.ServiceName == "" {
@@ -155,6 +196,17 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+// getEnvList splits a comma-separated value, dropping blank entries.
+func getEnvList(key string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func getEnvBool(key string, fallback bool) bool {
 	if val := os.Getenv(key); val != "" {
 		switch strings.ToLower(strings.TrimSpace(val)) {
@@ -169,15 +221,35 @@ func getEnvBool(key string, fallback bool) bool {
 
 // Validation errors returned by Config.Validate.
 var (
-	ErrAppNameRequired         = errors.New("config: app name is required")
-	ErrEnvironmentRequired     = errors.New("config: environment is required")
-	ErrServerHostRequired      = errors.New("config: server host is required")
-	ErrServerPortInvalid       = errors.New("config: server port must be an integer between 1 and 65535")
-	ErrShutdownGraceNegative   = errors.New("config: shutdown grace must be zero or positive")
-	ErrMaxRecvSizeInvalid      = errors.New("config: max receive message size must be greater than zero")
-	ErrMaxSendSizeInvalid      = errors.New("config: max send message size must be greater than zero")
-	ErrOtelEndpointRequired    = errors.New("config: OTEL exporter endpoint is required when observability is enabled")
-	ErrOtelServiceNameRequired = errors.New("config: OTEL service name is required when observability is enabled")
+	ErrAppNameRequired              = errors.New("config: app name is required")
+	ErrEnvironmentRequired          = errors.New("config: environment is required")
+	ErrServerHostRequired           = errors.New("config: server host is required")
+	ErrServerPortInvalid            = errors.New("config: server port must be an integer between 1 and 65535")
+	ErrShutdownGraceNegative        = errors.New("config: shutdown grace must be zero or positive")
+	ErrMaxRecvSizeInvalid           = errors.New("config: max receive message size must be greater than zero")
+	ErrMaxSendSizeInvalid           = errors.New("config: max send message size must be greater than zero")
+	ErrMaxHistoryNegative           = errors.New("config: max history replay must be zero or positive")
+	ErrResumeGraceNegative          = errors.New("config: resume grace must be zero or positive")
+	ErrSlowConsumerPolicy           = errors.New("config: slow consumer policy must be one of drop-newest, drop-oldest, block or disconnect")
+	ErrSlowConsumerTimeout          = errors.New("config: slow consumer timeout must be greater than zero")
+	ErrTypingTimeoutInvalid         = errors.New("config: typing timeout must be greater than zero")
+	ErrIdleTimeoutNegative          = errors.New("config: idle timeout must be zero or positive")
+	ErrDedupWindowNegative          = errors.New("config: dedup window must be zero or positive")
+	ErrRoomParticipantLimitNegative = errors.New("config: room participant limit must be zero or positive")
+	ErrJoinQueueSizeNegative        = errors.New("config: join queue size must be zero or positive")
+	ErrKeepaliveNegative            = errors.New("config: keepalive durations must be zero or positive")
+	ErrTLSKeyPairIncomplete         = errors.New("config: TLS certificate and key files must be set together")
+	ErrTLSClientCAWithoutCert       = errors.New("config: TLS client CA requires the server certificate and key")
+	ErrTLSReloadNegative            = errors.New("config: TLS reload interval must be zero or positive")
+	ErrOtelEndpointRequired         = errors.New("config: OTEL exporter endpoint is required when observability is enabled")
+	ErrOtelServiceNameRequired      = errors.New("config: OTEL service name is required when observability is enabled")
+	ErrStoreBackendInvalid          = errors.New("config: store backend must be one of memory or file")
+	ErrStorePathRequired            = errors.New("config: store path is required when the file backend is selected")
+	ErrRoomRetentionNegative        = errors.New("config: store room retention must be zero or positive")
+	ErrDirectQueueNegative          = errors.New("config: direct queue limit and TTL must be zero or positive")
+	ErrAuthModeInvalid              = errors.New("config: auth mode must be one of none, apikey or jwt")
+	ErrAuthAPIKeysFileRequired      = errors.New("config: api keys file is required when the apikey auth mode is selected")
+	ErrAuthJWTSecretRequired        = errors.New("config: jwt secret is required when the jwt auth mode is selected")
 )
 
 // Validate ensures the Config has sane values before it is used by the application.
@@ -207,6 +279,49 @@ func (c *Config) Validate() error {
 	if c.ServerGRPC.MaxSendMsgSize <= 0 {
 		return ErrMaxSendSizeInvalid
 	}
+	if c.ServerGRPC.MaxHistoryReplay < 0 {
+		return ErrMaxHistoryNegative
+	}
+	if c.ServerGRPC.ResumeGrace < 0 {
+		return ErrResumeGraceNegative
+	}
+	switch c.ServerGRPC.SlowConsumerPolicy {
+	case SlowConsumerDropNewest, SlowConsumerDropOldest, SlowConsumerDisconnect:
+	case SlowConsumerBlock:
+		if c.ServerGRPC.SlowConsumerTimeout <= 0 {
+			return ErrSlowConsumerTimeout
+		}
+	default:
+		return ErrSlowConsumerPolicy
+	}
+	if c.ServerGRPC.TypingTimeout <= 0 {
+		return ErrTypingTimeoutInvalid
+	}
+	if c.ServerGRPC.IdleTimeout < 0 {
+		return ErrIdleTimeoutNegative
+	}
+	if c.ServerGRPC.DedupWindow < 0 {
+		return ErrDedupWindowNegative
+	}
+	if c.ServerGRPC.RoomParticipantLimit < 0 {
+		return ErrRoomParticipantLimitNegative
+	}
+	if c.ServerGRPC.JoinQueueSize < 0 {
+		return ErrJoinQueueSizeNegative
+	}
+	if c.ServerGRPC.KeepaliveTime < 0 || c.ServerGRPC.KeepaliveTimeout < 0 ||
+		c.ServerGRPC.KeepaliveMaxIdle < 0 || c.ServerGRPC.KeepaliveMinTime < 0 {
+		return ErrKeepaliveNegative
+	}
+	if (c.ServerGRPC.TLSCertFile == "") != (c.ServerGRPC.TLSKeyFile == "") {
+		return ErrTLSKeyPairIncomplete
+	}
+	if c.ServerGRPC.TLSClientCAFile != "" && c.ServerGRPC.TLSCertFile == "" {
+		return ErrTLSClientCAWithoutCert
+	}
+	if c.ServerGRPC.TLSReloadInterval < 0 {
+		return ErrTLSReloadNegative
+	}
 
 	if c.Observability.Enabled {
 		if strings.TrimSpace(c.Observability.OtelExporterOTLPEndpoint) == "" {
@@ -217,5 +332,35 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.Store.Backend {
+	case StoreBackendMemory:
+	case StoreBackendFile:
+		if strings.TrimSpace(c.Store.Path) == "" {
+			return ErrStorePathRequired
+		}
+	default:
+		return ErrStoreBackendInvalid
+	}
+	if c.Store.RoomRetention < 0 {
+		return ErrRoomRetentionNegative
+	}
+	if c.Store.DirectQueueLimit < 0 || c.Store.DirectQueueTTL < 0 {
+		return ErrDirectQueueNegative
+	}
+
+	switch c.Auth.Mode {
+	case AuthModeNone:
+	case AuthModeAPIKey:
+		if strings.TrimSpace(c.Auth.APIKeysFile) == "" {
+			return ErrAuthAPIKeysFileRequired
+		}
+	case AuthModeJWT:
+		if c.Auth.JWTSecret == "" {
+			return ErrAuthJWTSecretRequired
+		}
+	default:
+		return ErrAuthModeInvalid
+	}
+
 	return nil
 }
@@ -19,11 +19,20 @@ func TestConfigValidateSuccess(t *testing.T) {
 			ShutdownGrace:  time.Second,
 			MaxRecvMsgSize: 1024,
 			MaxSendMsgSize: 1024,
+
+			SlowConsumerPolicy: SlowConsumerDropNewest,
+			TypingTimeout:      5 * time.Second,
 		},
 		Observability: ObservabilityConfig{
 			Enabled:     false,
 			ServiceName: "chat-grpc",
 		},
+		Store: StoreConfig{
+			Backend: StoreBackendMemory,
+		},
+		Auth: AuthConfig{
+			Mode: AuthModeNone,
+		},
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -93,6 +102,84 @@ func TestConfigValidateFailures(t *testing.T) {
 			},
 			wantErr: ErrMaxSendSizeInvalid,
 		},
+		{
+			name: "negative max history replay",
+			mutate: func(c *Config) {
+				c.ServerGRPC.MaxHistoryReplay = -1
+			},
+			wantErr: ErrMaxHistoryNegative,
+		},
+		{
+			name: "negative resume grace",
+			mutate: func(c *Config) {
+				c.ServerGRPC.ResumeGrace = -time.Second
+			},
+			wantErr: ErrResumeGraceNegative,
+		},
+		{
+			name: "unknown slow consumer policy",
+			mutate: func(c *Config) {
+				c.ServerGRPC.SlowConsumerPolicy = "ignore"
+			},
+			wantErr: ErrSlowConsumerPolicy,
+		},
+		{
+			name: "block policy without timeout",
+			mutate: func(c *Config) {
+				c.ServerGRPC.SlowConsumerPolicy = SlowConsumerBlock
+				c.ServerGRPC.SlowConsumerTimeout = 0
+			},
+			wantErr: ErrSlowConsumerTimeout,
+		},
+		{
+			name: "typing timeout not positive",
+			mutate: func(c *Config) {
+				c.ServerGRPC.TypingTimeout = 0
+			},
+			wantErr: ErrTypingTimeoutInvalid,
+		},
+		{
+			name: "negative idle timeout",
+			mutate: func(c *Config) {
+				c.ServerGRPC.IdleTimeout = -time.Second
+			},
+			wantErr: ErrIdleTimeoutNegative,
+		},
+		{
+			name: "negative dedup window",
+			mutate: func(c *Config) {
+				c.ServerGRPC.DedupWindow = -time.Second
+			},
+			wantErr: ErrDedupWindowNegative,
+		},
+		{
+			name: "negative room participant limit",
+			mutate: func(c *Config) {
+				c.ServerGRPC.RoomParticipantLimit = -1
+			},
+			wantErr: ErrRoomParticipantLimitNegative,
+		},
+		{
+			name: "negative join queue size",
+			mutate: func(c *Config) {
+				c.ServerGRPC.JoinQueueSize = -1
+			},
+			wantErr: ErrJoinQueueSizeNegative,
+		},
+		{
+			name: "negative keepalive time",
+			mutate: func(c *Config) {
+				c.ServerGRPC.KeepaliveTime = -time.Second
+			},
+			wantErr: ErrKeepaliveNegative,
+		},
+		{
+			name: "negative keepalive min time",
+			mutate: func(c *Config) {
+				c.ServerGRPC.KeepaliveMinTime = -time.Second
+			},
+			wantErr: ErrKeepaliveNegative,
+		},
 		{
 			name: "otel enabled without endpoint",
 			mutate: func(c *Config) {
@@ -111,6 +198,84 @@ func TestConfigValidateFailures(t *testing.T) {
 			},
 			wantErr: ErrOtelServiceNameRequired,
 		},
+		{
+			name: "tls certificate without key",
+			mutate: func(c *Config) {
+				c.ServerGRPC.TLSCertFile = "server.pem"
+			},
+			wantErr: ErrTLSKeyPairIncomplete,
+		},
+		{
+			name: "tls client CA without server certificate",
+			mutate: func(c *Config) {
+				c.ServerGRPC.TLSClientCAFile = "ca.pem"
+			},
+			wantErr: ErrTLSClientCAWithoutCert,
+		},
+		{
+			name: "negative tls reload interval",
+			mutate: func(c *Config) {
+				c.ServerGRPC.TLSReloadInterval = -time.Second
+			},
+			wantErr: ErrTLSReloadNegative,
+		},
+		{
+			name: "unknown store backend",
+			mutate: func(c *Config) {
+				c.Store.Backend = "redis"
+			},
+			wantErr: ErrStoreBackendInvalid,
+		},
+		{
+			name: "file store without path",
+			mutate: func(c *Config) {
+				c.Store.Backend = StoreBackendFile
+				c.Store.Path = " "
+			},
+			wantErr: ErrStorePathRequired,
+		},
+		{
+			name: "negative room retention",
+			mutate: func(c *Config) {
+				c.Store.RoomRetention = -1
+			},
+			wantErr: ErrRoomRetentionNegative,
+		},
+		{
+			name: "negative direct queue limit",
+			mutate: func(c *Config) {
+				c.Store.DirectQueueLimit = -1
+			},
+			wantErr: ErrDirectQueueNegative,
+		},
+		{
+			name: "negative direct queue ttl",
+			mutate: func(c *Config) {
+				c.Store.DirectQueueTTL = -time.Hour
+			},
+			wantErr: ErrDirectQueueNegative,
+		},
+		{
+			name: "unknown auth mode",
+			mutate: func(c *Config) {
+				c.Auth.Mode = "basic"
+			},
+			wantErr: ErrAuthModeInvalid,
+		},
+		{
+			name: "api key auth without keys file",
+			mutate: func(c *Config) {
+				c.Auth.Mode = AuthModeAPIKey
+			},
+			wantErr: ErrAuthAPIKeysFileRequired,
+		},
+		{
+			name: "jwt auth without secret",
+			mutate: func(c *Config) {
+				c.Auth.Mode = AuthModeJWT
+			},
+			wantErr: ErrAuthJWTSecretRequired,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -161,6 +326,32 @@ func TestLoadValidatesObservabilityEndpointWhenEnabled(t *testing.T) {
 	}
 }
 
+func TestLoadParsesModerators(t *testing.T) {
+	t.Setenv(envModeratorsKey, " alice, ,bob ")
+	resetEnvCache()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ServerGRPC.Moderators; len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Fatalf("expected moderators [alice bob], got %q", got)
+	}
+}
+
+func TestLoadParsesRequireRoomCreation(t *testing.T) {
+	t.Setenv(envRequireRoomCreationKey, "true")
+	resetEnvCache()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ServerGRPC.RequireRoomCreation {
+		t.Fatal("expected room creation to be required")
+	}
+}
+
 func defaultConfig() *Config {
 	return &Config{
 		App: AppConfig{
@@ -173,12 +364,22 @@ func defaultConfig() *Config {
 			ShutdownGrace:  time.Second,
 			MaxRecvMsgSize: 1024,
 			MaxSendMsgSize: 1024,
+
+			SlowConsumerPolicy: SlowConsumerDropNewest,
+			TypingTimeout:      5 * time.Second,
 		},
 		Observability: ObservabilityConfig{
 			Enabled:                  false,
 			ServiceName:              "chat-grpc",
 			OtelExporterOTLPEndpoint: "",
 		},
+		Store: StoreConfig{
+			Backend: StoreBackendMemory,
+			Path:    "data/messages.jsonl",
+		},
+		Auth: AuthConfig{
+			Mode: AuthModeNone,
+		},
 	}
 }
 
@@ -186,3 +387,31 @@ func resetEnvCache() {
 	loadEnvOnce = sync.Once{}
 	loadEnvErr = nil
 }
+
+func TestLoadParsesParticipantLimits(t *testing.T) {
+	t.Setenv(envRoomParticipantLimitKey, "50")
+	t.Setenv(envJoinQueueSizeKey, "10")
+	resetEnvCache()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ServerGRPC.RoomParticipantLimit != 50 || cfg.ServerGRPC.JoinQueueSize != 10 {
+		t.Fatalf("expected limit 50 and queue 10, got %d and %d", cfg.ServerGRPC.RoomParticipantLimit, cfg.ServerGRPC.JoinQueueSize)
+	}
+}
+
+func TestLoadParsesDirectQueue(t *testing.T) {
+	t.Setenv(envDirectQueueKey, "true")
+	t.Setenv(envDirectQueueLimitKey, "20")
+	resetEnvCache()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Store.DirectQueue || cfg.Store.DirectQueueLimit != 20 || cfg.Store.DirectQueueTTL != defaultDirectQueueTTL {
+		t.Fatalf("expected an enabled queue of 20 kept for %v, got %+v", defaultDirectQueueTTL, cfg.Store)
+	}
+}
@@ -5,35 +5,113 @@ import "time"
 const (
 	envFileName = ".env"
 
-	envAppNameKey            = "CHAT_GRPC_APP_NAME"
-	envEnvironmentKey        = "CHAT_GRPC_ENV"
-	envAppVersionKey         = "CHAT_GRPC_APP_VERSION"
-	envHostKey               = "CHAT_GRPC_HOST"
-	envPortKey               = "CHAT_GRPC_PORT"
-	envShutdownGraceKey      = "CHAT_GRPC_SHUTDOWN_GRACE"
-	envMaxRecvSizeKey        = "CHAT_GRPC_MAX_RECV_MSG_SIZE"
-	envMaxSendSizeKey        = "CHAT_GRPC_MAX_SEND_MSG_SIZE"
-	envOtelEnabledKey        = "CHAT_GRPC_OTEL_ENABLED"
-	envOtelEndpointKey       = "CHAT_GRPC_OTEL_EXPORTER_ENDPOINT"
-	envOtelInsecureKey       = "CHAT_GRPC_OTEL_EXPORTER_INSECURE"
-	envOtelTimeoutKey        = "CHAT_GRPC_OTEL_EXPORTER_TIMEOUT"
-	envOtelCompressionKey    = "CHAT_GRPC_OTEL_EXPORTER_COMPRESSION"
-	envOtelHeadersKey        = "CHAT_GRPC_OTEL_EXPORTER_HEADERS"
-	envOtelServiceNameKey    = "CHAT_GRPC_OTEL_SERVICE_NAME"
-	envOtelServiceVersionKey = "CHAT_GRPC_OTEL_SERVICE_VERSION"
-
-	defaultAppName            = "chat-grpc"
-	defaultEnvironment        = "development"
-	defaultHost               = "127.0.0.1"
-	defaultPort               = "50051"
-	defaultShutdownGrace      = 5 * time.Second
-	defaultMaxRecvMsgSize     = 4 << 20 // 4 MiB
-	defaultMaxSendMsgSize     = 4 << 20 // 4 MiB
-	defaultOtelEnabled        = false
-	defaultOtelInsecure       = true
-	defaultOtelTimeout        = "5s"
-	defaultOtelCompression    = "none"
-	defaultOtelServiceVersion = "0.1.0"
+	envAppNameKey              = "CHAT_GRPC_APP_NAME"
+	envEnvironmentKey          = "CHAT_GRPC_ENV"
+	envAppVersionKey           = "CHAT_GRPC_APP_VERSION"
+	envHostKey                 = "CHAT_GRPC_HOST"
+	envPortKey                 = "CHAT_GRPC_PORT"
+	envShutdownGraceKey        = "CHAT_GRPC_SHUTDOWN_GRACE"
+	envMaxRecvSizeKey          = "CHAT_GRPC_MAX_RECV_MSG_SIZE"
+	envMaxSendSizeKey          = "CHAT_GRPC_MAX_SEND_MSG_SIZE"
+	envMaxHistoryReplayKey     = "CHAT_GRPC_MAX_HISTORY_REPLAY"
+	envResumeGraceKey          = "CHAT_GRPC_RESUME_GRACE"
+	envSlowConsumerPolicyKey   = "CHAT_GRPC_SLOW_CONSUMER_POLICY"
+	envSlowConsumerTimeoutKey  = "CHAT_GRPC_SLOW_CONSUMER_TIMEOUT"
+	envTypingTimeoutKey        = "CHAT_GRPC_TYPING_TIMEOUT"
+	envIdleTimeoutKey          = "CHAT_GRPC_IDLE_TIMEOUT"
+	envDedupWindowKey          = "CHAT_GRPC_DEDUP_WINDOW"
+	envModeratorsKey           = "CHAT_GRPC_MODERATORS"
+	envRequireRoomCreationKey  = "CHAT_GRPC_REQUIRE_ROOM_CREATION"
+	envRoomParticipantLimitKey = "CHAT_GRPC_ROOM_PARTICIPANT_LIMIT"
+	envJoinQueueSizeKey        = "CHAT_GRPC_JOIN_QUEUE_SIZE"
+	envKeepaliveTimeKey        = "CHAT_GRPC_KEEPALIVE_TIME"
+	envKeepaliveTimeoutKey     = "CHAT_GRPC_KEEPALIVE_TIMEOUT"
+	envKeepaliveMaxIdleKey     = "CHAT_GRPC_KEEPALIVE_MAX_IDLE"
+	envKeepaliveMinTimeKey     = "CHAT_GRPC_KEEPALIVE_MIN_TIME"
+	envKeepalivePermitKey      = "CHAT_GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM"
+	envOtelEnabledKey          = "CHAT_GRPC_OTEL_ENABLED"
+	envOtelEndpointKey         = "CHAT_GRPC_OTEL_EXPORTER_ENDPOINT"
+	envOtelInsecureKey         = "CHAT_GRPC_OTEL_EXPORTER_INSECURE"
+	envOtelTimeoutKey          = "CHAT_GRPC_OTEL_EXPORTER_TIMEOUT"
+	envOtelCompressionKey      = "CHAT_GRPC_OTEL_EXPORTER_COMPRESSION"
+	envOtelHeadersKey          = "CHAT_GRPC_OTEL_EXPORTER_HEADERS"
+	envOtelServiceNameKey      = "CHAT_GRPC_OTEL_SERVICE_NAME"
+	envOtelServiceVersionKey   = "CHAT_GRPC_OTEL_SERVICE_VERSION"
+	envTLSCertFileKey          = "CHAT_GRPC_TLS_CERT_FILE"
+	envTLSKeyFileKey           = "CHAT_GRPC_TLS_KEY_FILE"
+	envTLSClientCAFileKey      = "CHAT_GRPC_TLS_CLIENT_CA_FILE"
+	envTLSReloadIntervalKey    = "CHAT_GRPC_TLS_RELOAD_INTERVAL"
+	envStoreBackendKey         = "CHAT_GRPC_STORE_BACKEND"
+	envStorePathKey            = "CHAT_GRPC_STORE_PATH"
+	envStoreRoomRetentionKey   = "CHAT_GRPC_STORE_ROOM_RETENTION"
+	envDirectQueueKey          = "CHAT_GRPC_DIRECT_QUEUE"
+	envDirectQueueLimitKey     = "CHAT_GRPC_DIRECT_QUEUE_LIMIT"
+	envDirectQueueTTLKey       = "CHAT_GRPC_DIRECT_QUEUE_TTL"
+	envAuthModeKey             = "CHAT_GRPC_AUTH_MODE"
+	envAuthAPIKeysFileKey      = "CHAT_GRPC_AUTH_API_KEYS_FILE"
+	envAuthJWTSecretKey        = "CHAT_GRPC_AUTH_JWT_SECRET"
+	envAuthJWTIssuerKey        = "CHAT_GRPC_AUTH_JWT_ISSUER"
+	envAuthJWTAudienceKey      = "CHAT_GRPC_AUTH_JWT_AUDIENCE"
+
+	defaultAppName             = "chat-grpc"
+	defaultEnvironment         = "development"
+	defaultHost                = "127.0.0.1"
+	defaultPort                = "50051"
+	defaultShutdownGrace       = 5 * time.Second
+	defaultMaxRecvMsgSize      = 4 << 20 // 4 MiB
+	defaultMaxSendMsgSize      = 4 << 20 // 4 MiB
+	defaultMaxHistoryReplay    = 100
+	defaultResumeGrace         = 0
+	defaultSlowConsumerPolicy  = SlowConsumerDropNewest
+	defaultSlowConsumerTimeout = 100 * time.Millisecond
+	defaultTypingTimeout       = 5 * time.Second
+	defaultIdleTimeout         = 5 * time.Minute
+	defaultDedupWindow         = time.Minute
+	defaultKeepaliveTime       = 30 * time.Second
+	defaultKeepaliveTimeout    = 10 * time.Second
+	defaultKeepaliveMinTime    = 10 * time.Second
+	defaultTLSReloadInterval   = time.Minute
+	defaultOtelEnabled         = false
+	defaultOtelInsecure        = true
+	defaultOtelTimeout         = "5s"
+	defaultOtelCompression     = "none"
+	defaultOtelServiceVersion  = "0.1.0"
+	defaultStoreBackend        = StoreBackendMemory
+	defaultStorePath           = "data/messages.jsonl"
+	defaultStoreRoomRetention  = 10000
+	defaultDirectQueueLimit    = 100
+	defaultDirectQueueTTL      = 7 * 24 * time.Hour
+	defaultAuthMode            = AuthModeNone
+)
+
+// Supported slow-consumer policies, applied when a subscriber's buffer is full.
+const (
+	// SlowConsumerDropNewest drops incoming events and later reports how many were missed.
+	SlowConsumerDropNewest = "drop-newest"
+	// SlowConsumerDropOldest evicts buffered events to make room and reports how many were missed.
+	SlowConsumerDropOldest = "drop-oldest"
+	// SlowConsumerBlock waits up to the slow-consumer timeout, then drops like drop-newest.
+	SlowConsumerBlock = "block"
+	// SlowConsumerDisconnect removes the subscriber from the room.
+	SlowConsumerDisconnect = "disconnect"
+)
+
+// Supported message store backends.
+const (
+	// StoreBackendMemory keeps history in process memory; it is lost on restart.
+	StoreBackendMemory = "memory"
+	// StoreBackendFile appends history to an embedded JSON Lines file.
+	StoreBackendFile = "file"
+)
+
+// Supported authentication modes.
+const (
+	// AuthModeNone accepts every client; identities are whatever clients claim.
+	AuthModeNone = "none"
+	// AuthModeAPIKey requires an x-api-key metadata entry listed in the API keys file.
+	AuthModeAPIKey = "apikey"
+	// AuthModeJWT requires an HMAC-signed bearer token in the authorization metadata.
+	AuthModeJWT = "jwt"
 )
 
 // ErrFailedToProcessEnvVars is returned when environment variables cannot be processed.
@@ -30,4 +30,77 @@ type ServerConfig struct {
 	ShutdownGrace  time.Duration
 	MaxRecvMsgSize int
 	MaxSendMsgSize int
+	// MaxHistoryReplay caps the backlog a client may request on join; zero disables replay.
+	MaxHistoryReplay int
+	// ResumeGrace is how long a dropped session may be resumed; zero disables resumption.
+	ResumeGrace time.Duration
+	// SlowConsumerPolicy is one of the SlowConsumer* values.
+	SlowConsumerPolicy string
+	// SlowConsumerTimeout bounds how long the block policy waits for a subscriber.
+	SlowConsumerTimeout time.Duration
+	// TypingTimeout is how long after its last typing signal a user is announced as no longer typing.
+	TypingTimeout time.Duration
+	// IdleTimeout evicts sessions without activity, heartbeats included, for that long; zero disables eviction.
+	IdleTimeout time.Duration
+	// DedupWindow is how long a client request ID is remembered to drop retried sends; zero disables it.
+	DedupWindow time.Duration
+	// Moderators lists the user IDs that act as moderators of every room.
+	Moderators []string
+	// RequireRoomCreation rejects joins to rooms that were not created with CreateRoom
+	// instead of creating them on the fly.
+	RequireRoomCreation bool
+	// RoomParticipantLimit caps the users of each room separately, not across rooms; zero
+	// leaves rooms to their own limit.
+	RoomParticipantLimit int
+	// JoinQueueSize is how many joins may wait for a seat in a full room; zero rejects them.
+	JoinQueueSize int
+	// KeepaliveTime is how long a connection may stay silent before the server pings it, and
+	// KeepaliveTimeout how long the server then waits for the ping ack before closing it.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// KeepaliveMaxIdle closes connections without active streams for that long; zero never does.
+	KeepaliveMaxIdle time.Duration
+	// KeepaliveMinTime is the shortest interval between client pings the server tolerates;
+	// clients pinging faster are disconnected.
+	KeepaliveMinTime time.Duration
+	// KeepalivePermitWithoutStream allows client pings on connections without active streams.
+	KeepalivePermitWithoutStream bool
+	// TLSCertFile and TLSKeyFile enable TLS when set; the listener is plaintext otherwise.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables mutual TLS: clients must present a certificate signed by this
+	// CA, and the certificate's subject common name becomes the stream's user identity.
+	TLSClientCAFile string
+	// TLSReloadInterval is how often the TLS files are checked for changes; zero disables reloading.
+	TLSReloadInterval time.Duration
+}
+
+// AuthConfig selects how clients authenticate.
+type AuthConfig struct {
+	// Mode is one of the AuthMode* values.
+	Mode string
+	// APIKeysFile lists "<user_id> <api_key>" pairs, one per line, for the apikey mode.
+	APIKeysFile string
+	// JWTSecret is the HMAC secret tokens are signed with in the jwt mode.
+	JWTSecret string
+	// JWTIssuer, when set, is the "iss" claim tokens must carry.
+	JWTIssuer string
+	// JWTAudience, when set, must be listed in the "aud" claim of tokens.
+	JWTAudience string
+}
+
+// StoreConfig selects the message store backend used to persist room history.
+type StoreConfig struct {
+	Backend string
+	Path    string
+	// RoomRetention caps the messages kept in memory per room, dropping the oldest; zero keeps
+	// them all. The file backend still keeps every message on disk.
+	RoomRetention int
+	// DirectQueue keeps the direct messages of offline users in the store until they connect
+	// instead of rejecting them.
+	DirectQueue bool
+	// DirectQueueLimit caps the messages queued per recipient, and DirectQueueTTL discards
+	// those queued for longer; zero leaves either unbounded.
+	DirectQueueLimit int
+	DirectQueueTTL   time.Duration
 }
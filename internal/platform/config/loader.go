@@ -57,6 +57,103 @@ func (l *Loader) Load(logger logger.ContextLogger) (*Config, error) {
 		l.cfg.ServerGRPC.MaxSendMsgSize = defaultMaxSendMsgSize
 	}
 
+	if l.cfg.ServerGRPC.MaxHistoryReplay == 0 {
+		l.cfg.ServerGRPC.MaxHistoryReplay = getEnvInt(envMaxHistoryReplayKey, defaultMaxHistoryReplay)
+	}
+	if l.cfg.ServerGRPC.ResumeGrace == 0 {
+		l.cfg.ServerGRPC.ResumeGrace = getEnvDuration(envResumeGraceKey, defaultResumeGrace)
+	}
+	if l.cfg.ServerGRPC.SlowConsumerPolicy == "" {
+		l.cfg.ServerGRPC.SlowConsumerPolicy = getEnv(envSlowConsumerPolicyKey, defaultSlowConsumerPolicy)
+	}
+	if l.cfg.ServerGRPC.SlowConsumerTimeout == 0 {
+		l.cfg.ServerGRPC.SlowConsumerTimeout = getEnvDuration(envSlowConsumerTimeoutKey, defaultSlowConsumerTimeout)
+	}
+	if l.cfg.ServerGRPC.TypingTimeout == 0 {
+		l.cfg.ServerGRPC.TypingTimeout = getEnvDuration(envTypingTimeoutKey, defaultTypingTimeout)
+	}
+	if l.cfg.ServerGRPC.IdleTimeout == 0 {
+		l.cfg.ServerGRPC.IdleTimeout = getEnvDuration(envIdleTimeoutKey, defaultIdleTimeout)
+	}
+	if l.cfg.ServerGRPC.DedupWindow == 0 {
+		l.cfg.ServerGRPC.DedupWindow = getEnvDuration(envDedupWindowKey, defaultDedupWindow)
+	}
+	if len(l.cfg.ServerGRPC.Moderators) == 0 {
+		l.cfg.ServerGRPC.Moderators = getEnvList(envModeratorsKey)
+	}
+	if !l.cfg.ServerGRPC.RequireRoomCreation {
+		l.cfg.ServerGRPC.RequireRoomCreation = getEnvBool(envRequireRoomCreationKey, false)
+	}
+	if l.cfg.ServerGRPC.RoomParticipantLimit == 0 {
+		l.cfg.ServerGRPC.RoomParticipantLimit = getEnvInt(envRoomParticipantLimitKey, 0)
+	}
+	if l.cfg.ServerGRPC.JoinQueueSize == 0 {
+		l.cfg.ServerGRPC.JoinQueueSize = getEnvInt(envJoinQueueSizeKey, 0)
+	}
+	if l.cfg.ServerGRPC.KeepaliveTime == 0 {
+		l.cfg.ServerGRPC.KeepaliveTime = getEnvDuration(envKeepaliveTimeKey, defaultKeepaliveTime)
+	}
+	if l.cfg.ServerGRPC.KeepaliveTimeout == 0 {
+		l.cfg.ServerGRPC.KeepaliveTimeout = getEnvDuration(envKeepaliveTimeoutKey, defaultKeepaliveTimeout)
+	}
+	if l.cfg.ServerGRPC.KeepaliveMaxIdle == 0 {
+		l.cfg.ServerGRPC.KeepaliveMaxIdle = getEnvDuration(envKeepaliveMaxIdleKey, 0)
+	}
+	if l.cfg.ServerGRPC.KeepaliveMinTime == 0 {
+		l.cfg.ServerGRPC.KeepaliveMinTime = getEnvDuration(envKeepaliveMinTimeKey, defaultKeepaliveMinTime)
+	}
+	if !l.cfg.ServerGRPC.KeepalivePermitWithoutStream {
+		l.cfg.ServerGRPC.KeepalivePermitWithoutStream = getEnvBool(envKeepalivePermitKey, false)
+	}
+
+	if l.cfg.ServerGRPC.TLSCertFile == "" {
+		l.cfg.ServerGRPC.TLSCertFile = getEnv(envTLSCertFileKey, "")
+	}
+	if l.cfg.ServerGRPC.TLSKeyFile == "" {
+		l.cfg.ServerGRPC.TLSKeyFile = getEnv(envTLSKeyFileKey, "")
+	}
+	if l.cfg.ServerGRPC.TLSClientCAFile == "" {
+		l.cfg.ServerGRPC.TLSClientCAFile = getEnv(envTLSClientCAFileKey, "")
+	}
+	if l.cfg.ServerGRPC.TLSReloadInterval == 0 {
+		l.cfg.ServerGRPC.TLSReloadInterval = getEnvDuration(envTLSReloadIntervalKey, defaultTLSReloadInterval)
+	}
+
+	if l.cfg.Store.Backend == "" {
+		l.cfg.Store.Backend = getEnv(envStoreBackendKey, defaultStoreBackend)
+	}
+	if l.cfg.Store.Path == "" {
+		l.cfg.Store.Path = getEnv(envStorePathKey, defaultStorePath)
+	}
+	if l.cfg.Store.RoomRetention == 0 {
+		l.cfg.Store.RoomRetention = getEnvInt(envStoreRoomRetentionKey, defaultStoreRoomRetention)
+	}
+	if !l.cfg.Store.DirectQueue {
+		l.cfg.Store.DirectQueue = getEnvBool(envDirectQueueKey, false)
+	}
+	if l.cfg.Store.DirectQueueLimit == 0 {
+		l.cfg.Store.DirectQueueLimit = getEnvInt(envDirectQueueLimitKey, defaultDirectQueueLimit)
+	}
+	if l.cfg.Store.DirectQueueTTL == 0 {
+		l.cfg.Store.DirectQueueTTL = getEnvDuration(envDirectQueueTTLKey, defaultDirectQueueTTL)
+	}
+
+	if l.cfg.Auth.Mode == "" {
+		l.cfg.Auth.Mode = getEnv(envAuthModeKey, defaultAuthMode)
+	}
+	if l.cfg.Auth.APIKeysFile == "" {
+		l.cfg.Auth.APIKeysFile = getEnv(envAuthAPIKeysFileKey, "")
+	}
+	if l.cfg.Auth.JWTSecret == "" {
+		l.cfg.Auth.JWTSecret = getEnv(envAuthJWTSecretKey, "")
+	}
+	if l.cfg.Auth.JWTIssuer == "" {
+		l.cfg.Auth.JWTIssuer = getEnv(envAuthJWTIssuerKey, "")
+	}
+	if l.cfg.Auth.JWTAudience == "" {
+		l.cfg.Auth.JWTAudience = getEnv(envAuthJWTAudienceKey, "")
+	}
+
 	if l.cfg.Observability.ServiceName == "" {
 		l.cfg.Observability.ServiceName = l.cfg.App.Name
 	}
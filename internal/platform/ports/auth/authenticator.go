@@ -0,0 +1,31 @@
+// Package auth defines the port used by transports to verify client credentials.
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnauthenticated is returned when credentials are missing, malformed or not accepted.
+var ErrUnauthenticated = errors.New("auth: invalid or missing credentials")
+
+// Credentials carries what a client presented with its request. Fields the client did not
+// send are empty.
+type Credentials struct {
+	// APIKey is the value of the x-api-key metadata entry.
+	APIKey string
+	// BearerToken is the token of an "authorization: Bearer <token>" metadata entry.
+	BearerToken string
+}
+
+// Principal is the identity established by an Authenticator.
+type Principal struct {
+	UserID string
+}
+
+// Authenticator verifies credentials and resolves the principal they belong to.
+type Authenticator interface {
+	// Authenticate returns the principal of the credentials, or an error wrapping
+	// ErrUnauthenticated when they are not accepted.
+	Authenticate(ctx context.Context, creds Credentials) (Principal, error)
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+	"github.com/lechitz/chat-grpc/internal/platform/ports/logger"
+	"github.com/lechitz/chat-grpc/internal/shared/ctxkeys"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticatedStream overrides the context of a server stream with the authenticated one.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// AuthStreamInterceptor rejects streams whose metadata does not carry credentials accepted
+// by authenticator. Accepted streams see the principal's user ID under ctxkeys.UserID. When
+// an earlier interceptor already identified the stream, the principal must match it.
+func AuthStreamInterceptor(authenticator auth.Authenticator, log logger.ContextLogger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, log, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// AuthUnaryInterceptor is the unary counterpart of AuthStreamInterceptor.
+func AuthUnaryInterceptor(authenticator auth.Authenticator, log logger.ContextLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, log, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authenticate verifies the credentials of a call and returns its context carrying the
+// principal's user ID.
+func authenticate(ctx context.Context, authenticator auth.Authenticator, log logger.ContextLogger, method string) (context.Context, error) {
+	principal, err := authenticator.Authenticate(ctx, credentialsFromMetadata(ctx))
+	if err != nil {
+		log.WarnwCtx(ctx, logMsgAuthRejected, logFieldMethod, method, logFieldError, err)
+		return nil, status.Error(codes.Unauthenticated, errMsgUnauthenticated)
+	}
+
+	if known, ok := ctx.Value(ctxkeys.UserID).(string); ok && known != principal.UserID {
+		return nil, status.Error(codes.PermissionDenied, errMsgPrincipalClash)
+	}
+	return context.WithValue(ctx, ctxkeys.UserID, principal.UserID), nil
+}
+
+// credentialsFromMetadata extracts the credentials a client sent in the request metadata.
+func credentialsFromMetadata(ctx context.Context) auth.Credentials {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var creds auth.Credentials
+	if keys := md.Get(metadataAPIKey); len(keys) > 0 {
+		creds.APIKey = keys[0]
+	}
+	if values := md.Get(metadataAuthorization); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, authSchemeBearer) {
+			creds.BearerToken = strings.TrimSpace(token)
+		}
+	}
+	return creds
+}
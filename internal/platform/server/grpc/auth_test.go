@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/lechitz/chat-grpc/api/proto/chatv1"
+	grpcadapter "github.com/lechitz/chat-grpc/internal/chat/adapter/primary/grpc"
+	"github.com/lechitz/chat-grpc/internal/chat/core/usecase"
+	"github.com/lechitz/chat-grpc/internal/platform/logger"
+	"github.com/lechitz/chat-grpc/internal/platform/ports/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// staticKeys accepts API keys from a fixed map.
+type staticKeys map[string]string
+
+func (k staticKeys) Authenticate(_ context.Context, creds auth.Credentials) (auth.Principal, error) {
+	userID, ok := k[creds.APIKey]
+	if !ok {
+		return auth.Principal{}, auth.ErrUnauthenticated
+	}
+	return auth.Principal{UserID: userID}, nil
+}
+
+func newAuthClient(t *testing.T, ctx context.Context) chatv1.ChatServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	keys := staticKeys{"key-a": "alice"}
+	srv := grpc.NewServer(
+		grpc.ChainStreamInterceptor(AuthStreamInterceptor(keys, logger.NoopLogger{})),
+		grpc.ChainUnaryInterceptor(AuthUnaryInterceptor(keys, logger.NoopLogger{})),
+	)
+	t.Cleanup(srv.Stop)
+	chatv1.RegisterChatServiceServer(srv, grpcadapter.NewServer(usecase.NewService(), logger.NoopLogger{}))
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return chatv1.NewChatServiceClient(conn)
+}
+
+func join(t *testing.T, ctx context.Context, client chatv1.ChatServiceClient, userID string) (*chatv1.ServerEvent, error) {
+	t.Helper()
+	stream, err := client.Channel(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&chatv1.ClientEnvelope{
+		Message: &chatv1.ClientEnvelope_Join{Join: &chatv1.JoinRequest{UserId: userID, Room: "general"}},
+	}))
+	return stream.Recv()
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newAuthClient(t, ctx)
+
+	t.Run("rejects missing credentials", func(t *testing.T) {
+		_, err := join(t, ctx, client, "alice")
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("rejects unknown credentials", func(t *testing.T) {
+		_, err := join(t, metadata.AppendToOutgoingContext(ctx, metadataAPIKey, "key-x"), client, "alice")
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("joins as the principal", func(t *testing.T) {
+		ev, err := join(t, metadata.AppendToOutgoingContext(ctx, metadataAPIKey, "key-a"), client, "")
+		require.NoError(t, err)
+		require.Equal(t, "alice", ev.GetJoined().GetUserId())
+	})
+
+	t.Run("guards unary calls", func(t *testing.T) {
+		_, err := client.ListRooms(ctx, &chatv1.ListRoomsRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.ListRooms(metadata.AppendToOutgoingContext(ctx, metadataAPIKey, "key-a"), &chatv1.ListRoomsRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects joining as someone else", func(t *testing.T) {
+		_, err := join(t, metadata.AppendToOutgoingContext(ctx, metadataAPIKey, "key-a"), client, "bob")
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestCredentialsFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		metadataAPIKey, "key-a",
+		metadataAuthorization, "Bearer token-1",
+	))
+	require.Equal(t, auth.Credentials{APIKey: "key-a", BearerToken: "token-1"}, credentialsFromMetadata(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataAuthorization, "Basic abc"))
+	require.Equal(t, auth.Credentials{}, credentialsFromMetadata(ctx))
+}
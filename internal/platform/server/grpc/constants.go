@@ -6,4 +6,20 @@ const (
 	logMsgServerStopping = "grpc server stopping"
 	logFieldAddr         = "addr"
 	errFmtListenTCP      = "listen tcp %s: %w"
+	errFmtLoadTLS        = "load tls files: %w"
+	logFieldTLS          = "tls"
+	logFieldMutualTLS    = "mtls"
+
+	logMsgAuthRejected    = "rejected unauthenticated stream"
+	logFieldMethod        = "method"
+	logFieldError         = "error"
+	errMsgUnauthenticated = "invalid or missing credentials"
+	errMsgPrincipalClash  = "credentials do not match the client certificate identity"
+
+	logMsgTLSReloadFailure   = "failed to reload TLS files; keeping previous ones"
+	errMsgClientCertRequired = "verified client certificate with a subject common name required"
+
+	metadataAPIKey        = "x-api-key"
+	metadataAuthorization = "authorization"
+	authSchemeBearer      = "bearer"
 )
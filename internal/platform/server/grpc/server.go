@@ -22,13 +22,48 @@ func Compose(cfg *config.Config, deps *bootstrap.AppDependencies, log logger.Con
 	opts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(cfg.ServerGRPC.MaxRecvMsgSize),
 		grpc.MaxSendMsgSize(cfg.ServerGRPC.MaxSendMsgSize),
-		grpc.KeepaliveParams(keepalive.ServerParameters{}),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: cfg.ServerGRPC.KeepaliveMaxIdle,
+			Time:              cfg.ServerGRPC.KeepaliveTime,
+			Timeout:           cfg.ServerGRPC.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.ServerGRPC.KeepaliveMinTime,
+			PermitWithoutStream: cfg.ServerGRPC.KeepalivePermitWithoutStream,
+		}),
 	}
 
 	if cfg.Observability.Enabled {
 		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
 	}
 
+	creds, err := transportCredentials(cfg.ServerGRPC, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf(errFmtLoadTLS, err)
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	// The client certificate identifies the stream first, so credentials checked by the
+	// authenticator must belong to the same user.
+	var (
+		streamInterceptors []grpc.StreamServerInterceptor
+		unaryInterceptors  []grpc.UnaryServerInterceptor
+	)
+	if cfg.ServerGRPC.TLSClientCAFile != "" {
+		streamInterceptors = append(streamInterceptors, ClientCertStreamInterceptor())
+		unaryInterceptors = append(unaryInterceptors, ClientCertUnaryInterceptor())
+	}
+	if deps.Authenticator != nil {
+		streamInterceptors = append(streamInterceptors, AuthStreamInterceptor(deps.Authenticator, log))
+		unaryInterceptors = append(unaryInterceptors, AuthUnaryInterceptor(deps.Authenticator, log))
+	}
+	opts = append(opts,
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+	)
+
 	server := grpc.NewServer(opts...)
 
 	chatv1.RegisterChatServiceServer(server, grpcadapter.NewServer(deps.ChatService, deps.Logger))
@@ -38,7 +73,7 @@ func Compose(cfg *config.Config, deps *bootstrap.AppDependencies, log logger.Con
 		return nil, nil, fmt.Errorf(errFmtListenTCP, cfg.ServerGRPC.Addr(), err)
 	}
 
-	log.Infow(logMsgServerReady, logFieldAddr, cfg.ServerGRPC.Addr())
+	log.Infow(logMsgServerReady, logFieldAddr, cfg.ServerGRPC.Addr(), logFieldTLS, creds != nil, logFieldMutualTLS, cfg.ServerGRPC.TLSClientCAFile != "")
 	return server, listener, nil
 }
 
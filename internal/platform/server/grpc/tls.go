@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/lechitz/chat-grpc/internal/platform/config"
+	"github.com/lechitz/chat-grpc/internal/platform/ports/logger"
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig"
+	"github.com/lechitz/chat-grpc/internal/shared/ctxkeys"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// transportCredentials returns the TLS credentials configured for the server, or nil when
+// it listens in plaintext.
+func transportCredentials(cfg config.ServerConfig, log logger.ContextLogger) (credentials.TransportCredentials, error) {
+	if cfg.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	reloader, err := tlsconfig.NewReloader(tlsconfig.ServerFiles{
+		CertFile:     cfg.TLSCertFile,
+		KeyFile:      cfg.TLSKeyFile,
+		ClientCAFile: cfg.TLSClientCAFile,
+	}, cfg.TLSReloadInterval, func(err error) {
+		log.Warnw(logMsgTLSReloadFailure, logFieldError, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(reloader.ServerConfig()), nil
+}
+
+// ClientCertStreamInterceptor identifies streams by the subject common name of their
+// verified client certificate, placing it under ctxkeys.UserID.
+func ClientCertStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := identifyClientCert(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// ClientCertUnaryInterceptor is the unary counterpart of ClientCertStreamInterceptor.
+func ClientCertUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := identifyClientCert(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// identifyClientCert returns the context of a call carrying its client certificate identity.
+func identifyClientCert(ctx context.Context) (context.Context, error) {
+	userID, ok := clientCertIdentity(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, errMsgClientCertRequired)
+	}
+	return context.WithValue(ctx, ctxkeys.UserID, userID), nil
+}
+
+// clientCertIdentity returns the subject common name of the peer's verified certificate.
+func clientCertIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	cn := info.State.VerifiedChains[0][0].Subject.CommonName
+	return cn, cn != ""
+}
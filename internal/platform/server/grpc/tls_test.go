@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/lechitz/chat-grpc/api/proto/chatv1"
+	grpcadapter "github.com/lechitz/chat-grpc/internal/chat/adapter/primary/grpc"
+	"github.com/lechitz/chat-grpc/internal/chat/core/usecase"
+	"github.com/lechitz/chat-grpc/internal/platform/config"
+	"github.com/lechitz/chat-grpc/internal/platform/logger"
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig"
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig/tlstest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMutualTLSMapsCertificateSubjectToUser(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ca := tlstest.NewCA(t)
+	serverCert, serverKey := ca.IssueServer("server")
+	creds, err := transportCredentials(config.ServerConfig{
+		TLSCertFile:     serverCert,
+		TLSKeyFile:      serverKey,
+		TLSClientCAFile: ca.CertFile,
+	}, logger.NoopLogger{})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(grpc.Creds(creds), grpc.ChainStreamInterceptor(ClientCertStreamInterceptor()))
+	t.Cleanup(srv.Stop)
+	chatv1.RegisterChatServiceServer(srv, grpcadapter.NewServer(usecase.NewService(), logger.NoopLogger{}))
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	dial := func(files tlsconfig.ClientFiles) chatv1.ChatServiceClient {
+		files.CAFile = ca.CertFile
+		files.ServerName = "localhost"
+		cfg, err := tlsconfig.ClientConfig(files)
+		require.NoError(t, err)
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithTransportCredentials(credentials.NewTLS(cfg)),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return chatv1.NewChatServiceClient(conn)
+	}
+
+	aliceCert, aliceKey := ca.IssueClient("alice", "alice")
+	ev, err := join(t, ctx, dial(tlsconfig.ClientFiles{CertFile: aliceCert, KeyFile: aliceKey}), "")
+	require.NoError(t, err)
+	require.Equal(t, "alice", ev.GetJoined().GetUserId())
+
+	// Without a client certificate the handshake itself fails.
+	stream, err := dial(tlsconfig.ClientFiles{}).Channel(ctx)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,211 @@
+// Package tlsconfig builds the TLS configurations used by the gRPC server and the bundled
+// clients, including certificate hot-reload for the server.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	alpnHTTP2 = "h2"
+
+	errFmtLoadKeyPair = "load key pair %s, %s: %w"
+	errFmtReadCA      = "read CA file %s: %w"
+	errFmtParseCA     = "CA file %s: %w"
+	errFmtStat        = "stat %s: %w"
+)
+
+// errNoCertificates is returned when a CA file holds no PEM certificate.
+var errNoCertificates = errors.New("no PEM certificates found")
+
+// ServerFiles locates the server's key pair and, optionally, the CA that signs client
+// certificates. When ClientCAFile is set, clients must present a certificate it verifies.
+type ServerFiles struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Reloader serves the server certificate and client CA pool from disk, reloading them when
+// the files change. Files are checked at most once per interval, during a handshake; a
+// reload that fails keeps serving the last good material.
+type Reloader struct {
+	files    ServerFiles
+	interval time.Duration
+	onError  func(error)
+
+	mu      sync.Mutex
+	checked time.Time
+	state   material
+}
+
+// material is a consistent snapshot of the files loaded from disk.
+type material struct {
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes []time.Time
+}
+
+// NewReloader loads the files once and returns a reloader checking them every interval.
+// A zero interval disables reloading. onError, when non-nil, receives reload failures.
+func NewReloader(files ServerFiles, interval time.Duration, onError func(error)) (*Reloader, error) {
+	r := &Reloader{
+		files:    files,
+		interval: interval,
+		onError:  onError,
+	}
+	state, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.state = state
+	r.checked = time.Now()
+	return r, nil
+}
+
+// ServerConfig returns a TLS configuration whose handshakes always use the current files.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		NextProtos:         []string{alpnHTTP2},
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+func (r *Reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	state := r.current()
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{alpnHTTP2},
+		Certificates: []tls.Certificate{*state.cert},
+	}
+	if state.clientCA != nil {
+		cfg.ClientCAs = state.clientCA
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// current returns the loaded material, reloading it first when a file changed.
+func (r *Reloader) current() material {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interval == 0 || time.Since(r.checked) < r.interval {
+		return r.state
+	}
+	r.checked = time.Now()
+
+	modTimes, err := r.modTimes()
+	if err == nil && sameTimes(modTimes, r.state.modTimes) {
+		return r.state
+	}
+	state, err := r.load()
+	if err != nil {
+		if r.onError != nil {
+			r.onError(err)
+		}
+		return r.state
+	}
+	r.state = state
+	return r.state
+}
+
+func (r *Reloader) load() (material, error) {
+	modTimes, err := r.modTimes()
+	if err != nil {
+		return material{}, err
+	}
+	cert, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+	if err != nil {
+		return material{}, fmt.Errorf(errFmtLoadKeyPair, r.files.CertFile, r.files.KeyFile, err)
+	}
+	state := material{cert: &cert, modTimes: modTimes}
+	if r.files.ClientCAFile != "" {
+		if state.clientCA, err = LoadCertPool(r.files.ClientCAFile); err != nil {
+			return material{}, err
+		}
+	}
+	return state, nil
+}
+
+func (r *Reloader) modTimes() ([]time.Time, error) {
+	paths := []string{r.files.CertFile, r.files.KeyFile}
+	if r.files.ClientCAFile != "" {
+		paths = append(paths, r.files.ClientCAFile)
+	}
+	times := make([]time.Time, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf(errFmtStat, path, err)
+		}
+		times = append(times, info.ModTime())
+	}
+	return times, nil
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientFiles configures a client connection. CAFile verifies the server, falling back to
+// the system roots when empty; CertFile and KeyFile are presented to servers requiring
+// client certificates. ServerName overrides the name checked against the server
+// certificate.
+type ClientFiles struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+// ClientConfig builds the TLS configuration of a client connection.
+func ClientConfig(files ClientFiles) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: files.ServerName,
+	}
+	if files.CAFile != "" {
+		pool, err := LoadCertPool(files.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if files.CertFile != "" || files.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf(errFmtLoadKeyPair, files.CertFile, files.KeyFile, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// LoadCertPool reads the PEM certificates of a CA file.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errFmtReadCA, path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf(errFmtParseCA, path, errNoCertificates)
+	}
+	return pool, nil
+}
@@ -0,0 +1,85 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lechitz/chat-grpc/internal/platform/tlsconfig/tlstest"
+	"github.com/stretchr/testify/require"
+)
+
+func servedSerial(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cfg, err := r.ServerConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.String()
+}
+
+func touch(t *testing.T, paths ...string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	for _, path := range paths {
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+}
+
+func TestReloaderPicksUpRenewedCertificate(t *testing.T) {
+	ca := tlstest.NewCA(t)
+	certFile, keyFile := ca.IssueServer("server")
+
+	var reloadErrs []error
+	r, err := NewReloader(ServerFiles{CertFile: certFile, KeyFile: keyFile}, time.Nanosecond, func(err error) {
+		reloadErrs = append(reloadErrs, err)
+	})
+	require.NoError(t, err)
+	before := servedSerial(t, r)
+
+	ca.IssueServer("server")
+	touch(t, certFile, keyFile)
+	renewed := servedSerial(t, r)
+	require.NotEqual(t, before, renewed)
+
+	// A broken renewal keeps the last good certificate.
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	touch(t, certFile)
+	require.Equal(t, renewed, servedSerial(t, r))
+	require.NotEmpty(t, reloadErrs)
+}
+
+func TestReloaderRequiresClientCertificatesWithCA(t *testing.T) {
+	ca := tlstest.NewCA(t)
+	certFile, keyFile := ca.IssueServer("server")
+
+	r, err := NewReloader(ServerFiles{CertFile: certFile, KeyFile: keyFile, ClientCAFile: ca.CertFile}, 0, nil)
+	require.NoError(t, err)
+
+	cfg, err := r.ServerConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+	require.NotNil(t, cfg.ClientCAs)
+	require.Equal(t, []string{"h2"}, cfg.NextProtos)
+}
+
+func TestNewReloaderRejectsMissingFiles(t *testing.T) {
+	_, err := NewReloader(ServerFiles{CertFile: "missing.pem", KeyFile: "missing-key.pem"}, 0, nil)
+	require.Error(t, err)
+}
+
+func TestClientConfig(t *testing.T) {
+	ca := tlstest.NewCA(t)
+	certFile, keyFile := ca.IssueClient("alice", "alice")
+
+	cfg, err := ClientConfig(ClientFiles{CAFile: ca.CertFile, CertFile: certFile, KeyFile: keyFile, ServerName: "localhost"})
+	require.NoError(t, err)
+	require.NotNil(t, cfg.RootCAs)
+	require.Len(t, cfg.Certificates, 1)
+	require.Equal(t, "localhost", cfg.ServerName)
+
+	_, err = ClientConfig(ClientFiles{CAFile: certFile + ".missing"})
+	require.Error(t, err)
+}
@@ -119,7 +119,7 @@ func TestChatFlow(t *testing.T) {
 
 			// wait for others then send a message
 			time.Sleep(delay)
-			if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{UserId: u, Room: "general", Content: "hello from " + u, TimestampUtc: time.Now().UTC().UnixMilli()}}}); err != nil {
+			if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Chat{Chat: &chatv1.ChatPayload{Content: "hello from " + u, TimestampUtc: time.Now().UTC().UnixMilli()}}}); err != nil {
 				results <- fmt.Sprintf("chat send: %v", err)
 				return
 			}
@@ -130,7 +130,7 @@ func TestChatFlow(t *testing.T) {
 					break
 				}
 			}
-			if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{UserId: u, Room: "general"}}}); err != nil {
+			if err := stream.Send(&chatv1.ClientEnvelope{Message: &chatv1.ClientEnvelope_Leave{Leave: &chatv1.LeaveRequest{}}}); err != nil {
 				results <- fmt.Sprintf("leave send: %v", err)
 				return
 			}